@@ -0,0 +1,75 @@
+package s3_manager
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Способ адресации бакета в запросах и ссылках на файлы
+type AddressingStyle string
+
+const (
+	// Прежнее поведение библиотеки: запросы отправляются так, как решит SDK, а ссылки на файлы формируются в path-style
+	AddressingStyleDefault AddressingStyle = ""
+	// Path-style: бакет указывается в пути ("https://storage.example.com/bucket/key"). Обычно используется для MinIO и других self-hosted хранилищ.
+	AddressingStylePath AddressingStyle = "path"
+	// Virtual-hosted-style: бакет указывается в домене ("https://bucket.storage.example.com/key"). Используется в AWS S3 и большинстве облачных хранилищ.
+	AddressingStyleVirtualHosted AddressingStyle = "virtual-hosted"
+)
+
+// Настраивает адресацию бакета в запросах клиента S3 в соответствии с конфигом
+func (c *Config) applyAddressingStyle(o *s3.Options) {
+	switch c.AddressingStyle {
+	case AddressingStylePath:
+		o.UsePathStyle = true
+	case AddressingStyleVirtualHosted:
+		o.UsePathStyle = false
+	}
+}
+
+// Возвращает адрес хранилища из конфига или адрес AWS S3 в регионе бакета, если адрес хранилища не задан
+func (r *s3Manager) endpoint() string {
+	if r.cfg.Endpoint == "" {
+		return fmt.Sprintf("https://s3.%s.amazonaws.com", r.cfg.Region)
+	}
+
+	return r.cfg.Endpoint
+}
+
+// Формирует ссылку на объект по адресу хранилища (без учёта CDN) в соответствии со способом адресации бакета
+func (r *s3Manager) endpointURL(key string) string {
+	endpoint := strings.TrimSuffix(r.endpoint(), "/")
+
+	if r.cfg.AddressingStyle == AddressingStyleVirtualHosted {
+		endpointURL, err := url.Parse(endpoint)
+		if err == nil && endpointURL.Host != "" {
+			endpointURL.Host = r.cfg.Name + "." + endpointURL.Host
+			return fmt.Sprintf("%s/%s", strings.TrimSuffix(endpointURL.String(), "/"), key)
+		}
+	}
+
+	return fmt.Sprintf("%s/%s/%s", endpoint, r.cfg.Name, key)
+}
+
+// Формирует ссылку на объект: через CDN, если он задан в конфиге, иначе по адресу хранилища. Каждый каталог и имя файла в ключе экранируются.
+func (r *s3Manager) objectURL(key string) string {
+	escapedKey := escapeKey(key)
+	if r.cfg.CDN != "" {
+		return strings.TrimSuffix(r.cfg.CDN, "/") + "/" + escapedKey
+	}
+
+	return r.endpointURL(escapedKey)
+}
+
+// Экранирует ключ объекта для использования в пути ссылки, сохраняя "/" между каталогами
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
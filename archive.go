@@ -0,0 +1,129 @@
+package s3_manager
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Метод для скачивания всех файлов каталога storagePath (включая подкаталоги) в виде zip-архива, который записывается в w по мере скачивания файлов,
+// без временных файлов и буферизации архива в памяти (например, для функции «скачать все вложения» в HTTP-обработчике).
+// Пути файлов в архиве задаются относительно каталога. Так как архив пишется потоком, при ошибке в середине w содержит неполный архив.
+func (r *s3Manager) DownloadCatalogAsZip(ctx context.Context, storagePath StoragePath, w io.Writer, opts ...Option) error {
+	r = r.forCall(opts)
+
+	archive := zip.NewWriter(w)
+
+	err := r.walkCatalogFiles(ctx, storagePath, r.applyOptions(opts), func(name string, object ObjectInfo, body io.Reader) error {
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: object.LastModified,
+		})
+		if err != nil {
+			return fmt.Errorf("CreateHeader: %w", err)
+		}
+		_, err = io.Copy(entry, body)
+		if err != nil {
+			return fmt.Errorf("Copy: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("DownloadCatalogAsZip/walkCatalogFiles: %w", err)
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return fmt.Errorf("DownloadCatalogAsZip/Close: %w", err)
+	}
+
+	return nil
+}
+
+// Метод для скачивания всех файлов каталога storagePath в виде архива tar.gz, который записывается в w по мере скачивания файлов (см. DownloadCatalogAsZip)
+func (r *s3Manager) DownloadCatalogAsTarGz(ctx context.Context, storagePath StoragePath, w io.Writer, opts ...Option) error {
+	r = r.forCall(opts)
+
+	compressor := gzip.NewWriter(w)
+	archive := tar.NewWriter(compressor)
+
+	err := r.walkCatalogFiles(ctx, storagePath, r.applyOptions(opts), func(name string, object ObjectInfo, body io.Reader) error {
+		err := archive.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     name,
+			Size:     object.Size,
+			Mode:     0o644,
+			ModTime:  object.LastModified,
+		})
+		if err != nil {
+			return fmt.Errorf("WriteHeader: %w", err)
+		}
+		_, err = io.Copy(archive, body)
+		if err != nil {
+			return fmt.Errorf("Copy: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("DownloadCatalogAsTarGz/walkCatalogFiles: %w", err)
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return fmt.Errorf("DownloadCatalogAsTarGz/Close: %w", err)
+	}
+	err = compressor.Close()
+	if err != nil {
+		return fmt.Errorf("DownloadCatalogAsTarGz/Close: %w", err)
+	}
+
+	return nil
+}
+
+// Последовательно скачивает файлы каталога в порядке имён и передаёт их содержимое в add
+func (r *s3Manager) walkCatalogFiles(ctx context.Context, storagePath StoragePath, o operationOptions, add func(name string, object ObjectInfo, body io.Reader) error) error {
+	prefix, err := r.objectKey(storagePath, "")
+	if err != nil {
+		return fmt.Errorf("walkCatalogFiles/objectKey: %w", err)
+	}
+	objects, err := r.listCatalog(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("walkCatalogFiles/listCatalog: %w", err)
+	}
+
+	names := make([]string, 0, len(objects))
+	for name := range objects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		err = r.addCatalogFile(ctx, name, objects[name], o, add)
+		if err != nil {
+			return fmt.Errorf("walkCatalogFiles: %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// Скачивает один файл каталога и передаёт его содержимое в add
+func (r *s3Manager) addCatalogFile(ctx context.Context, name string, object ObjectInfo, o operationOptions, add func(name string, object ObjectInfo, body io.Reader) error) error {
+	if object.Size == 0 {
+		return add(name, object, strings.NewReader("")) // Диапазонный запрос к пустому объекту завершается ошибкой InvalidRange
+	}
+
+	body, _, err := r.openRange(ctx, name, object.Key, 0, -1, object.ETag, o)
+	if err != nil {
+		return fmt.Errorf("addCatalogFile/openRange: %w", err)
+	}
+	defer body.Close()
+
+	return add(name, object, body)
+}
@@ -0,0 +1,182 @@
+package s3_manager
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Формат архива для PutArchive
+type ArchiveFormat string
+
+const (
+	ArchiveZip   ArchiveFormat = "zip"
+	ArchiveTar   ArchiveFormat = "tar"
+	ArchiveTarGz ArchiveFormat = "tar.gz"
+)
+
+// Метод для распаковки архива в каталог storagePath (например, для массового импорта файлов). Файлы загружаются по одному с сохранением
+// относительных путей из архива; каталоги, символические ссылки и файлы с путями вне каталога (например, "../file") пропускаются.
+// Архивы tar и tar.gz читаются потоком. Для zip нужен произвольный доступ к архиву: если archive не реализует io.ReaderAt и io.Seeker
+// (как *os.File), архив сначала сохраняется во временный файл. Размер каждого файла ограничивается так же, как в PutFile.
+// Возвращает имена загруженных файлов; если часть файлов загрузить не удалось, возвращается также ошибка *BatchError.
+func (r *s3Manager) PutArchive(ctx context.Context, storagePath StoragePath, archive io.Reader, format ArchiveFormat, opts ...Option) ([]string, error) {
+	r = r.forCall(opts)
+
+	if archive == nil {
+		return nil, fmt.Errorf("PutArchive: %w: archive is nil", ErrInvalidInput)
+	}
+
+	u := &archiveUploader{
+		manager:     r,
+		ctx:         ctx,
+		storagePath: storagePath,
+		opts:        append([]Option{withRawName()}, opts...), // Пути файлов из архива сохраняются как есть
+		failed:      make(map[string]error),
+	}
+
+	var err error
+	switch format {
+	case ArchiveZip:
+		err = u.putZip(archive)
+	case ArchiveTar:
+		err = u.putTar(archive)
+	case ArchiveTarGz:
+		var decompressor *gzip.Reader
+		decompressor, err = gzip.NewReader(archive)
+		if err != nil {
+			return nil, fmt.Errorf("PutArchive/NewReader: %w: %w", ErrInvalidInput, err)
+		}
+		defer decompressor.Close()
+		err = u.putTar(decompressor)
+	default:
+		return nil, fmt.Errorf("PutArchive: %w: unknown archive format %q", ErrInvalidInput, format)
+	}
+	if err != nil {
+		return u.uploaded, fmt.Errorf("PutArchive: %w", err)
+	}
+
+	if len(u.failed) > 0 {
+		return u.uploaded, fmt.Errorf("PutArchive: %w", &BatchError{Errors: u.failed})
+	}
+
+	return u.uploaded, nil
+}
+
+// Загрузка файлов из архива
+type archiveUploader struct {
+	manager     *s3Manager
+	ctx         context.Context
+	storagePath StoragePath
+	opts        []Option
+	uploaded    []string         // Имена загруженных файлов
+	failed      map[string]error // Ошибки загрузки по именам файлов
+}
+
+// Загружает файлы из zip-архива
+func (u *archiveUploader) putZip(archive io.Reader) error {
+	readerAt, size, cleanup, err := zipSource(archive)
+	if err != nil {
+		return fmt.Errorf("putZip/zipSource: %w", err)
+	}
+	defer cleanup()
+
+	reader, err := zip.NewReader(readerAt, size)
+	if err != nil {
+		return fmt.Errorf("putZip/NewReader: %w: %w", ErrInvalidInput, err)
+	}
+
+	for _, file := range reader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		body, err := file.Open()
+		if err != nil {
+			u.failed[file.Name] = fmt.Errorf("putZip/Open: %w", err)
+			continue
+		}
+		u.put(file.Name, body)
+		body.Close()
+	}
+
+	return nil
+}
+
+// Загружает файлы из tar-архива
+func (u *archiveUploader) putTar(archive io.Reader) error {
+	reader := tar.NewReader(archive)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("putTar/Next: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		u.put(header.Name, reader)
+	}
+}
+
+// Загружает файл из архива, если его путь не выходит за пределы каталога
+func (u *archiveUploader) put(name string, body io.Reader) {
+	// Начальный "/" отбрасывается, как это делает tar при распаковке
+	name = strings.TrimLeft(path.Clean(strings.ReplaceAll(name, "\\", "/")), "/")
+	if !fs.ValidPath(name) || name == "." {
+		return
+	}
+
+	_, err := u.manager.PutFile(u.ctx, u.storagePath, &BucketFile{File: body, Name: name}, u.opts...)
+	if err != nil {
+		u.failed[name] = err
+		return
+	}
+	u.uploaded = append(u.uploaded, name)
+}
+
+// Возвращает источник для чтения zip-архива с произвольной позиции: сам поток, если он это поддерживает, иначе временный файл с его копией
+func zipSource(archive io.Reader) (io.ReaderAt, int64, func(), error) {
+	if readerAt, ok := archive.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		size, err := readerSize(archive)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("zipSource/readerSize: %w", err)
+		}
+		current, err := readerAt.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("zipSource/Seek: %w", err)
+		}
+		return io.NewSectionReader(readerAt, current, size), size, func() {}, nil
+	}
+
+	file, err := os.CreateTemp("", "s3-manager-*.zip")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("zipSource/CreateTemp: %w", err)
+	}
+	cleanup := func() {
+		file.Close()
+		os.Remove(file.Name())
+	}
+
+	size, err := io.Copy(file, archive)
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, fmt.Errorf("zipSource/Copy: %w", err)
+	}
+
+	return file, size, cleanup, nil
+}
@@ -0,0 +1,188 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Получатель журнала аудита: вызывается после каждого изменяющего запроса к хранилищу (загрузка, копирование, удаление, изменение
+// настроек бакета и блокировок объектов), в том числе при неудаче. Вызывается синхронно в горутине запроса, поэтому медленную запись
+// (например, в базу или внешний сервис) стоит выполнять асинхронно. Подключается через Config.AuditSink.
+type AuditSink interface {
+	Record(ctx context.Context, record AuditRecord)
+}
+
+// Функция, которая реализует AuditSink (например, запись в slog или отправка в очередь)
+type AuditSinkFunc func(ctx context.Context, record AuditRecord)
+
+func (f AuditSinkFunc) Record(ctx context.Context, record AuditRecord) {
+	f(ctx, record)
+}
+
+// Запись журнала аудита об одном изменяющем запросе к хранилищу
+type AuditRecord struct {
+	Time      time.Time     // Время начала запроса
+	Actor     string        // Кто выполнил операцию (см. WithAuditActor); пустая строка, если не задано
+	Operation string        // Запрос к хранилищу (например, "PutObject", "DeleteObject", "CopyObject", "PutObjectRetention")
+	Bucket    string        // Бакет
+	Key       string        // Ключ объекта; пустой для операций с настройками бакета
+	Source    string        // Исходный объект при копировании в формате "bucket/key"
+	Bytes     int64         // Размер загруженных данных в байтах; 0, если неизвестен (например, при копировании, multipart upload и загрузке потока без Seek)
+	Duration  time.Duration // Длительность запроса
+	Err       error         // Ошибка запроса; nil, если операция выполнена
+}
+
+type auditActorKey struct{}
+
+// Возвращает контекст с именем того, кто выполняет операции (например, ID пользователя или сервиса из токена запроса).
+// Имя попадает в AuditRecord.Actor всех изменяющих запросов с этим контекстом.
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// Возвращает имя того, кто выполняет операции, из контекста (см. WithAuditActor)
+func AuditActor(ctx context.Context) string {
+	actor, _ := ctx.Value(auditActorKey{}).(string)
+	return actor
+}
+
+// Хранилище, которое передаёт в журнал аудита каждый изменяющий запрос (запросы на чтение передаются без изменений). Подключается автоматически, если в конфиге указан AuditSink.
+type auditBackend struct {
+	next StorageBackend
+	sink AuditSink
+}
+
+var _ StorageBackend = (*auditBackend)(nil)
+
+// Возвращает хранилище, вокруг которого построена обёртка
+func (b *auditBackend) Unwrap() StorageBackend {
+	return b.next
+}
+
+func (b *auditBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	size := bodySize(params)
+	start := time.Now()
+	output, err := b.next.PutObject(ctx, params, optFns...)
+	recordAudit(ctx, b.sink, AuditRecord{
+		Operation: "PutObject",
+		Bucket:    aws.ToString(params.Bucket),
+		Key:       aws.ToString(params.Key),
+		Bytes:     size,
+	}, start, err)
+	return output, err
+}
+
+func (b *auditBackend) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	start := time.Now()
+	output, err := b.next.DeleteObject(ctx, params, optFns...)
+	recordAudit(ctx, b.sink, AuditRecord{Operation: "DeleteObject", Bucket: aws.ToString(params.Bucket), Key: aws.ToString(params.Key)}, start, err)
+	return output, err
+}
+
+// Записывает удаление каждого объекта отдельно, чтобы в журнале было видно, какие файлы удалены, а какие нет
+func (b *auditBackend) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	start := time.Now()
+	output, err := b.next.DeleteObjects(ctx, params, optFns...)
+	if params.Delete == nil {
+		return output, err
+	}
+
+	failed := make(map[string]error)
+	if output != nil {
+		for _, deleteErr := range output.Errors {
+			failed[aws.ToString(deleteErr.Key)] = fmt.Errorf("%s: %s", aws.ToString(deleteErr.Code), aws.ToString(deleteErr.Message))
+		}
+	}
+	for _, object := range params.Delete.Objects {
+		key := aws.ToString(object.Key)
+		objectErr := err
+		if objectErr == nil {
+			objectErr = failed[key]
+		}
+		recordAudit(ctx, b.sink, AuditRecord{Operation: "DeleteObjects", Bucket: aws.ToString(params.Bucket), Key: key}, start, objectErr)
+	}
+	return output, err
+}
+
+func (b *auditBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	start := time.Now()
+	output, err := b.next.CopyObject(ctx, params, optFns...)
+	recordAudit(ctx, b.sink, AuditRecord{
+		Operation: "CopyObject",
+		Bucket:    aws.ToString(params.Bucket),
+		Key:       aws.ToString(params.Key),
+		Source:    aws.ToString(params.CopySource),
+	}, start, err)
+	return output, err
+}
+
+func (b *auditBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	start := time.Now()
+	output, err := b.next.CompleteMultipartUpload(ctx, params, optFns...)
+	recordAudit(ctx, b.sink, AuditRecord{Operation: "CompleteMultipartUpload", Bucket: aws.ToString(params.Bucket), Key: aws.ToString(params.Key)}, start, err)
+	return output, err
+}
+
+func (b *auditBackend) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return b.next.GetObject(ctx, params, optFns...)
+}
+
+func (b *auditBackend) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return b.next.HeadObject(ctx, params, optFns...)
+}
+
+func (b *auditBackend) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return b.next.ListObjectsV2(ctx, params, optFns...)
+}
+
+func (b *auditBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return b.next.CreateMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *auditBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return b.next.UploadPart(ctx, params, optFns...)
+}
+
+func (b *auditBackend) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	return b.next.UploadPartCopy(ctx, params, optFns...)
+}
+
+func (b *auditBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return b.next.AbortMultipartUpload(ctx, params, optFns...)
+}
+
+// Возвращает размер загружаемых данных: ContentLength или оставшуюся длину тела с поддержкой Seek; 0, если размер неизвестен
+func bodySize(params *s3.PutObjectInput) int64 {
+	if params.ContentLength != nil {
+		return *params.ContentLength
+	}
+	size, err := readerSize(params.Body)
+	if err != nil || size < 0 {
+		return 0
+	}
+
+	return size
+}
+
+// Дополняет запись временем, длительностью, исполнителем и результатом и передаёт её в журнал
+func recordAudit(ctx context.Context, sink AuditSink, record AuditRecord, start time.Time, err error) {
+	record.Time = start
+	record.Duration = time.Since(start)
+	record.Actor = AuditActor(ctx)
+	record.Err = err
+	sink.Record(ctx, record)
+}
+
+// Передаёт в журнал аудита изменяющий запрос, который выполняется напрямую клиентом S3, минуя StorageBackend
+// (настройки бакета, сроки хранения и юридические блокировки объектов)
+func (r *s3Manager) audit(ctx context.Context, operation, key string, start time.Time, err error) {
+	if r.cfg.AuditSink == nil {
+		return
+	}
+
+	recordAudit(ctx, r.cfg.AuditSink, AuditRecord{Operation: operation, Bucket: r.cfg.Name, Key: key}, start, err)
+}
@@ -0,0 +1,92 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Ошибка для операций, которые не поддерживаются используемым хранилищем (например, подписанные ссылки для локального или in-memory хранилища)
+var ErrNotSupported = errors.New("operation is not supported by the storage backend")
+
+// Хранилище объектов, с которым работает S3Manager. Повторяет подмножество методов *s3.Client, поэтому клиент S3 подходит под интерфейс без обёрток.
+// Помимо S3 (и S3-совместимых хранилищ вроде MinIO) есть встроенные реализации для локальной разработки и тестов: NewMemoryBackend и NewLocalBackend.
+type StorageBackend interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error)
+	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+var _ StorageBackend = (*s3.Client)(nil)
+
+// Создаёт S3Manager поверх произвольного хранилища объектов (например, NewMemoryBackend() для тестов или NewLocalBackend(dir) для локального запуска).
+// Операции, доступные только в S3 (например, подписанные ссылки), для других хранилищ возвращают ErrNotSupported.
+func NewS3ManagerWithBackend(backend StorageBackend, cfg *Config, isTestServer bool) (S3Manager, error) {
+	if backend == nil {
+		return nil, fmt.Errorf("NewS3ManagerWithBackend: backend is nil")
+	}
+
+	s3Manager, err := newS3Manager(backend, cfg, isTestServer)
+	if err != nil {
+		return nil, fmt.Errorf("NewS3ManagerWithBackend/newS3Manager: %w", err)
+	}
+
+	return s3Manager, nil
+}
+
+// Возвращает клиент S3 для операций, которые есть только в S3 (например, подписанные ссылки)
+func (r *s3Manager) s3Client() (*s3.Client, error) {
+	backend := r.client
+	for {
+		switch client := backend.(type) {
+		case *s3.Client:
+			return client, nil
+		case interface{ Unwrap() StorageBackend }: // Обёртки над хранилищем (например, логирование)
+			backend = client.Unwrap()
+		default:
+			return nil, ErrNotSupported
+		}
+	}
+}
+
+// Метод для получения клиента S3, с которым работает менеджер, для вызова API, для которых в S3Manager нет методов
+// (например, тегирование бакета или настройки репликации), без создания второго клиента с теми же учётными данными.
+// Запросы через клиент не проходят через логирование (Config.Logger) и ограничение частоты запросов (Config.RateLimit).
+// Для хранилищ, отличных от S3 (см. NewS3ManagerWithBackend), возвращает nil. В режиме только для чтения (Config.ReadOnly) тоже
+// возвращает nil: запросы через клиент не проверяются на изменение бакета.
+func (r *s3Manager) Client() *s3.Client {
+	if r.cfg.ReadOnly {
+		return nil
+	}
+	client, err := r.s3Client()
+	if err != nil {
+		return nil
+	}
+
+	return client
+}
+
+// Метод для получения клиента подписи ссылок поверх клиента S3 менеджера (см. Client), например, для подписи запросов,
+// для которых в S3Manager нет методов. Для хранилищ, отличных от S3, и в режиме только для чтения возвращает nil.
+func (r *s3Manager) PresignClient() *s3.PresignClient {
+	if r.cfg.ReadOnly {
+		return nil
+	}
+	client, err := r.s3Client()
+	if err != nil {
+		return nil
+	}
+
+	return s3.NewPresignClient(client)
+}
@@ -0,0 +1,733 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const defaultListMaxKeys = 1000 // Количество ключей на странице ListObjectsV2 по умолчанию (как в S3)
+
+// Ошибка хранилища: объект не найден
+var errObjectNotStored = errors.New("object not found")
+
+// Объект, сохранённый во встроенном хранилище: содержимое и метаданные в том виде, в котором их возвращает S3
+type storedObject struct {
+	Data               []byte            `json:"-"`
+	Size               int64             `json:"size"`
+	ETag               string            `json:"etag"`
+	LastModified       time.Time         `json:"last_modified"`
+	ContentType        string            `json:"content_type,omitempty"`
+	CacheControl       string            `json:"cache_control,omitempty"`
+	ContentDisposition string            `json:"content_disposition,omitempty"`
+	ContentEncoding    string            `json:"content_encoding,omitempty"`
+	ContentLanguage    string            `json:"content_language,omitempty"`
+	StorageClass       string            `json:"storage_class,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	Redirect           string            `json:"redirect,omitempty"` // x-amz-website-redirect-location
+}
+
+// Простое хранилище объектов, поверх которого emulatedBackend эмулирует API S3
+type objectStore interface {
+	load(bucket, key string, withData bool) (*storedObject, error) // Возвращает errObjectNotStored, если объекта нет
+	save(bucket, key string, obj *storedObject) error
+	remove(bucket, key string) error              // Отсутствие объекта ошибкой не считается
+	keys(bucket, prefix string) ([]string, error) // Ключи с указанным префиксом в лексикографическом порядке
+}
+
+// Бэкенд, эмулирующий API S3 поверх простого хранилища объектов (в памяти или на локальном диске).
+// Поддерживает подмножество возможностей S3, которое использует S3Manager: префиксы и разделители, постраничный вывод, копирование и multipart upload.
+// Бакеты создаются автоматически при первой записи.
+type emulatedBackend struct {
+	mu      sync.Mutex
+	store   objectStore
+	uploads map[string]*emulatedUpload
+}
+
+// Незавершённая multipart-загрузка
+type emulatedUpload struct {
+	bucket string
+	key    string
+	object storedObject // Параметры будущего объекта (без содержимого)
+	parts  map[int32][]byte
+}
+
+var _ StorageBackend = (*emulatedBackend)(nil)
+
+func newEmulatedBackend(store objectStore) *emulatedBackend {
+	return &emulatedBackend{
+		store:   store,
+		uploads: make(map[string]*emulatedUpload),
+	}
+}
+
+func (b *emulatedBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := readBody(params.Body)
+	if err != nil {
+		return nil, fmt.Errorf("PutObject: %w", err)
+	}
+
+	output := &s3.PutObjectOutput{}
+	for _, algorithm := range types.ChecksumAlgorithm("").Values() {
+		expected, _ := checksumField(algorithm, &params.ChecksumCRC32, &params.ChecksumCRC32C, &params.ChecksumCRC64NVME, &params.ChecksumSHA1, &params.ChecksumSHA256)
+		actual, _ := checksumField(algorithm, &output.ChecksumCRC32, &output.ChecksumCRC32C, &output.ChecksumCRC64NVME, &output.ChecksumSHA1, &output.ChecksumSHA256)
+		if err := checkBodyChecksum(algorithm, *expected, actual, data); err != nil {
+			return nil, err
+		}
+	}
+
+	obj := &storedObject{
+		Data:               data,
+		ContentType:        aws.ToString(params.ContentType),
+		CacheControl:       aws.ToString(params.CacheControl),
+		ContentDisposition: aws.ToString(params.ContentDisposition),
+		ContentEncoding:    aws.ToString(params.ContentEncoding),
+		ContentLanguage:    aws.ToString(params.ContentLanguage),
+		StorageClass:       string(params.StorageClass),
+		Metadata:           params.Metadata,
+		Redirect:           aws.ToString(params.WebsiteRedirectLocation),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.checkWritePreconditions(aws.ToString(params.Bucket), aws.ToString(params.Key), params.IfMatch, params.IfNoneMatch); err != nil {
+		return nil, err
+	}
+	err = b.saveObject(aws.ToString(params.Bucket), aws.ToString(params.Key), obj, etagOf(data))
+	if err != nil {
+		return nil, err
+	}
+	output.ETag = aws.String(obj.ETag)
+
+	return output, nil
+}
+
+func (b *emulatedBackend) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if err := checkVersion(params.VersionId); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	obj, err := b.store.load(aws.ToString(params.Bucket), aws.ToString(params.Key), true)
+	if err != nil {
+		return nil, objectError(err, &types.NoSuchKey{Message: aws.String("The specified key does not exist.")})
+	}
+	if params.IfMatch != nil && *params.IfMatch != obj.ETag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+	}
+	// Как в S3: If-None-Match проверяется вместо If-Modified-Since, если указаны оба условия
+	notModified := params.IfNoneMatch != nil && *params.IfNoneMatch == obj.ETag
+	if params.IfNoneMatch == nil && params.IfModifiedSince != nil {
+		notModified = !obj.LastModified.Truncate(time.Second).After(*params.IfModifiedSince)
+	}
+	if notModified {
+		return nil, &smithy.GenericAPIError{Code: "NotModified", Message: "Not Modified"}
+	}
+
+	data := obj.Data
+	var contentRange *string
+	if params.Range != nil {
+		start, end, err := parseByteRange(*params.Range, obj.Size)
+		if err != nil {
+			return nil, &smithy.GenericAPIError{Code: "InvalidRange", Message: "The requested range is not satisfiable"}
+		}
+		data = data[start : end+1]
+		contentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, obj.Size))
+	}
+
+	return &s3.GetObjectOutput{
+		Body:               io.NopCloser(bytes.NewReader(data)),
+		ContentLength:      aws.Int64(int64(len(data))),
+		ContentRange:       contentRange,
+		ETag:               aws.String(obj.ETag),
+		LastModified:       aws.Time(obj.LastModified),
+		ContentType:        nonEmpty(obj.ContentType),
+		CacheControl:       nonEmpty(obj.CacheControl),
+		ContentDisposition: nonEmpty(obj.ContentDisposition),
+		ContentEncoding:    nonEmpty(obj.ContentEncoding),
+		ContentLanguage:    nonEmpty(obj.ContentLanguage),
+		StorageClass:       types.StorageClass(obj.StorageClass),
+		Metadata:           obj.Metadata,
+	}, nil
+}
+
+func (b *emulatedBackend) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if err := checkVersion(params.VersionId); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	obj, err := b.store.load(aws.ToString(params.Bucket), aws.ToString(params.Key), false)
+	if err != nil {
+		return nil, objectError(err, &types.NotFound{Message: aws.String("Not Found")})
+	}
+
+	return &s3.HeadObjectOutput{
+		ContentLength:           aws.Int64(obj.Size),
+		ETag:                    aws.String(obj.ETag),
+		LastModified:            aws.Time(obj.LastModified),
+		ContentType:             nonEmpty(obj.ContentType),
+		CacheControl:            nonEmpty(obj.CacheControl),
+		ContentDisposition:      nonEmpty(obj.ContentDisposition),
+		ContentEncoding:         nonEmpty(obj.ContentEncoding),
+		ContentLanguage:         nonEmpty(obj.ContentLanguage),
+		StorageClass:            types.StorageClass(obj.StorageClass),
+		Metadata:                obj.Metadata,
+		WebsiteRedirectLocation: nonEmpty(obj.Redirect),
+	}, nil
+}
+
+func (b *emulatedBackend) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	bucket := aws.ToString(params.Bucket)
+	prefix := aws.ToString(params.Prefix)
+	delimiter := aws.ToString(params.Delimiter)
+
+	maxKeys := int32(defaultListMaxKeys)
+	if params.MaxKeys != nil {
+		maxKeys = *params.MaxKeys
+	}
+
+	// Продолжаем после последнего ключа предыдущей страницы (токен продолжения — это и есть последний выданный ключ)
+	startAfter := aws.ToString(params.StartAfter)
+	if params.ContinuationToken != nil {
+		startAfter = *params.ContinuationToken
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	keys, err := b.store.keys(bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("ListObjectsV2: %w", err)
+	}
+
+	output := &s3.ListObjectsV2Output{
+		Name:              params.Bucket,
+		Prefix:            params.Prefix,
+		Delimiter:         params.Delimiter,
+		MaxKeys:           aws.Int32(maxKeys),
+		ContinuationToken: params.ContinuationToken,
+		StartAfter:        params.StartAfter,
+		IsTruncated:       aws.Bool(false),
+	}
+
+	var count int32
+	var lastKey string
+	seenPrefixes := make(map[string]bool)
+	for _, key := range keys {
+		if key <= startAfter {
+			continue
+		}
+
+		if delimiter != "" {
+			if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+				commonPrefix := key[:len(prefix)+i+len(delimiter)]
+				if seenPrefixes[commonPrefix] || strings.HasPrefix(startAfter, commonPrefix) {
+					continue
+				}
+				if count == maxKeys {
+					output.IsTruncated = aws.Bool(true)
+					break
+				}
+
+				seenPrefixes[commonPrefix] = true
+				output.CommonPrefixes = append(output.CommonPrefixes, types.CommonPrefix{Prefix: aws.String(commonPrefix)})
+				count++
+				lastKey = commonPrefix
+				continue
+			}
+		}
+
+		if count == maxKeys {
+			output.IsTruncated = aws.Bool(true)
+			break
+		}
+
+		obj, err := b.store.load(bucket, key, false)
+		if err != nil {
+			if errors.Is(err, errObjectNotStored) {
+				continue
+			}
+			return nil, fmt.Errorf("ListObjectsV2: %w", err)
+		}
+
+		output.Contents = append(output.Contents, types.Object{
+			Key:          aws.String(key),
+			Size:         aws.Int64(obj.Size),
+			ETag:         aws.String(obj.ETag),
+			LastModified: aws.Time(obj.LastModified),
+			StorageClass: types.ObjectStorageClass(storageClassOrDefault(obj.StorageClass)),
+		})
+		count++
+		lastKey = key
+	}
+
+	output.KeyCount = aws.Int32(count)
+	if aws.ToBool(output.IsTruncated) {
+		output.NextContinuationToken = aws.String(lastKey)
+	}
+
+	return output, nil
+}
+
+func (b *emulatedBackend) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	if err := checkVersion(params.VersionId); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	err := b.store.remove(aws.ToString(params.Bucket), aws.ToString(params.Key))
+	if err != nil {
+		return nil, fmt.Errorf("DeleteObject: %w", err)
+	}
+
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (b *emulatedBackend) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	if params.Delete == nil {
+		return nil, fmt.Errorf("DeleteObjects: delete request is empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	output := &s3.DeleteObjectsOutput{}
+	for _, object := range params.Delete.Objects {
+		err := b.store.remove(aws.ToString(params.Bucket), aws.ToString(object.Key))
+		if err != nil {
+			output.Errors = append(output.Errors, types.Error{
+				Key:     object.Key,
+				Code:    aws.String("InternalError"),
+				Message: aws.String(err.Error()),
+			})
+			continue
+		}
+		if !aws.ToBool(params.Delete.Quiet) {
+			output.Deleted = append(output.Deleted, types.DeletedObject{Key: object.Key})
+		}
+	}
+
+	return output, nil
+}
+
+func (b *emulatedBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	srcBucket, srcKey, versionID, err := parseCopySource(aws.ToString(params.CopySource))
+	if err != nil {
+		return nil, fmt.Errorf("CopyObject: %w", err)
+	}
+	if err := checkVersion(versionID); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	src, err := b.store.load(srcBucket, srcKey, true)
+	if err != nil {
+		return nil, objectError(err, &types.NoSuchKey{Message: aws.String("The specified key does not exist.")})
+	}
+
+	obj := *src
+	if params.MetadataDirective == types.MetadataDirectiveReplace {
+		obj.ContentType = aws.ToString(params.ContentType)
+		obj.CacheControl = aws.ToString(params.CacheControl)
+		obj.ContentDisposition = aws.ToString(params.ContentDisposition)
+		obj.ContentEncoding = aws.ToString(params.ContentEncoding)
+		obj.ContentLanguage = aws.ToString(params.ContentLanguage)
+		obj.Metadata = params.Metadata
+	}
+	if params.StorageClass != "" {
+		obj.StorageClass = string(params.StorageClass)
+	}
+
+	err = b.saveObject(aws.ToString(params.Bucket), aws.ToString(params.Key), &obj, src.ETag)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s3.CopyObjectOutput{
+		CopyObjectResult: &types.CopyObjectResult{
+			ETag:         aws.String(obj.ETag),
+			LastModified: aws.Time(obj.LastModified),
+		},
+	}, nil
+}
+
+func (b *emulatedBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	uploadID, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("CreateMultipartUpload: %w", err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.uploads[uploadID] = &emulatedUpload{
+		bucket: aws.ToString(params.Bucket),
+		key:    aws.ToString(params.Key),
+		object: storedObject{
+			ContentType:        aws.ToString(params.ContentType),
+			CacheControl:       aws.ToString(params.CacheControl),
+			ContentDisposition: aws.ToString(params.ContentDisposition),
+			ContentEncoding:    aws.ToString(params.ContentEncoding),
+			ContentLanguage:    aws.ToString(params.ContentLanguage),
+			StorageClass:       string(params.StorageClass),
+			Metadata:           params.Metadata,
+		},
+		parts: make(map[int32][]byte),
+	}
+
+	return &s3.CreateMultipartUploadOutput{
+		Bucket:   params.Bucket,
+		Key:      params.Key,
+		UploadId: aws.String(uploadID),
+	}, nil
+}
+
+func (b *emulatedBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	data, err := readBody(params.Body)
+	if err != nil {
+		return nil, fmt.Errorf("UploadPart: %w", err)
+	}
+
+	output := &s3.UploadPartOutput{ETag: aws.String(etagOf(data))}
+	for _, algorithm := range types.ChecksumAlgorithm("").Values() {
+		expected, _ := checksumField(algorithm, &params.ChecksumCRC32, &params.ChecksumCRC32C, &params.ChecksumCRC64NVME, &params.ChecksumSHA1, &params.ChecksumSHA256)
+		actual, _ := checksumField(algorithm, &output.ChecksumCRC32, &output.ChecksumCRC32C, &output.ChecksumCRC64NVME, &output.ChecksumSHA1, &output.ChecksumSHA256)
+		if err := checkBodyChecksum(algorithm, *expected, actual, data); err != nil {
+			return nil, err
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	upload, err := b.upload(params.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	upload.parts[aws.ToInt32(params.PartNumber)] = data
+
+	return output, nil
+}
+
+func (b *emulatedBackend) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	srcBucket, srcKey, versionID, err := parseCopySource(aws.ToString(params.CopySource))
+	if err != nil {
+		return nil, fmt.Errorf("UploadPartCopy: %w", err)
+	}
+	if err := checkVersion(versionID); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	upload, err := b.upload(params.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := b.store.load(srcBucket, srcKey, true)
+	if err != nil {
+		return nil, objectError(err, &types.NoSuchKey{Message: aws.String("The specified key does not exist.")})
+	}
+
+	data := src.Data
+	if params.CopySourceRange != nil {
+		start, end, err := parseByteRange(*params.CopySourceRange, int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("UploadPartCopy: %w", err)
+		}
+		data = data[start : end+1]
+	}
+	upload.parts[aws.ToInt32(params.PartNumber)] = bytes.Clone(data)
+
+	return &s3.UploadPartCopyOutput{
+		CopyPartResult: &types.CopyPartResult{
+			ETag:         aws.String(etagOf(data)),
+			LastModified: aws.Time(time.Now().UTC()),
+		},
+	}, nil
+}
+
+func (b *emulatedBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	if params.MultipartUpload == nil || len(params.MultipartUpload.Parts) == 0 {
+		return nil, fmt.Errorf("CompleteMultipartUpload: parts list is empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	upload, err := b.upload(params.UploadId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Собираем объект из частей в указанном порядке. ETag multipart-объекта, как и в S3, — это MD5 от MD5 частей с количеством частей через дефис.
+	var data bytes.Buffer
+	partsHash := md5.New()
+	for _, part := range params.MultipartUpload.Parts {
+		partData, ok := upload.parts[aws.ToInt32(part.PartNumber)]
+		if !ok {
+			return nil, &types.NoSuchUpload{Message: aws.String(fmt.Sprintf("part %d was not uploaded", aws.ToInt32(part.PartNumber)))}
+		}
+		data.Write(partData)
+		partSum := md5.Sum(partData)
+		partsHash.Write(partSum[:])
+	}
+	etag := fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(partsHash.Sum(nil)), len(params.MultipartUpload.Parts))
+
+	if err := b.checkWritePreconditions(upload.bucket, upload.key, params.IfMatch, params.IfNoneMatch); err != nil {
+		return nil, err
+	}
+	obj := upload.object
+	obj.Data = data.Bytes()
+	err = b.saveObject(upload.bucket, upload.key, &obj, etag)
+	if err != nil {
+		return nil, err
+	}
+	delete(b.uploads, aws.ToString(params.UploadId))
+
+	return &s3.CompleteMultipartUploadOutput{
+		Bucket: aws.String(upload.bucket),
+		Key:    aws.String(upload.key),
+		ETag:   aws.String(etag),
+	}, nil
+}
+
+func (b *emulatedBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	_, err := b.upload(params.UploadId)
+	if err != nil {
+		return nil, err
+	}
+	delete(b.uploads, aws.ToString(params.UploadId))
+
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+// Сохраняет объект с заполнением служебных полей (размер, ETag, время изменения). Вызывается под блокировкой.
+func (b *emulatedBackend) saveObject(bucket, key string, obj *storedObject, etag string) error {
+	if key == "" {
+		return fmt.Errorf("object key is empty")
+	}
+
+	obj.Size = int64(len(obj.Data))
+	obj.ETag = etag
+	obj.LastModified = time.Now().UTC().Truncate(time.Millisecond)
+
+	err := b.store.save(bucket, key, obj)
+	if err != nil {
+		return fmt.Errorf("save object %q: %w", key, err)
+	}
+
+	return nil
+}
+
+// Проверяет условия записи, как в S3: If-None-Match "*" запрещает перезаписывать существующий объект,
+// If-Match разрешает перезаписать только объект с указанным ETag. Вызывается под блокировкой.
+func (b *emulatedBackend) checkWritePreconditions(bucket, key string, ifMatch, ifNoneMatch *string) error {
+	if ifMatch == nil && aws.ToString(ifNoneMatch) != "*" {
+		return nil
+	}
+
+	obj, err := b.store.load(bucket, key, false)
+	if err != nil && !errors.Is(err, errObjectNotStored) {
+		return fmt.Errorf("check preconditions: %w", err)
+	}
+	if ifMatch != nil && obj == nil {
+		return &types.NoSuchKey{Message: aws.String("The specified key does not exist.")}
+	}
+	if (ifMatch != nil && *ifMatch != obj.ETag) || (aws.ToString(ifNoneMatch) == "*" && obj != nil) {
+		return &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"}
+	}
+
+	return nil
+}
+
+// Возвращает незавершённую multipart-загрузку по идентификатору. Вызывается под блокировкой.
+func (b *emulatedBackend) upload(uploadID *string) (*emulatedUpload, error) {
+	upload, ok := b.uploads[aws.ToString(uploadID)]
+	if !ok {
+		return nil, &types.NoSuchUpload{Message: aws.String("The specified upload does not exist.")}
+	}
+
+	return upload, nil
+}
+
+// Преобразует ошибку хранилища в ошибку S3: отсутствие объекта — в notFound, остальные возвращаются как есть
+func objectError(err error, notFound error) error {
+	if errors.Is(err, errObjectNotStored) {
+		return notFound
+	}
+	return err
+}
+
+// Читает тело запроса целиком
+func readBody(body io.Reader) ([]byte, error) {
+	if body == nil {
+		return []byte{}, nil
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("read body: %w", err)
+	}
+
+	return data, nil
+}
+
+// ETag объекта, загруженного одним запросом: MD5 содержимого в кавычках
+func etagOf(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+// Разбирает заголовок x-amz-copy-source ("бакет/ключ?versionId=версия" в URL-кодировке) на бакет, ключ и версию
+func parseCopySource(copySource string) (string, string, *string, error) {
+	copySource, rawQuery, _ := strings.Cut(strings.TrimPrefix(copySource, "/"), "?")
+	bucket, escapedKey, ok := strings.Cut(copySource, "/")
+	if !ok || bucket == "" || escapedKey == "" {
+		return "", "", nil, fmt.Errorf("invalid copy source %q", copySource)
+	}
+
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("invalid copy source %q: %w", copySource, err)
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("invalid copy source %q: %w", copySource, err)
+	}
+
+	return bucket, key, nonEmpty(query.Get("versionId")), nil
+}
+
+// Проверяет контрольную сумму тела запроса, переданную клиентом, и записывает её в поле ответа, как это делает S3
+func checkBodyChecksum(algorithm types.ChecksumAlgorithm, expected *string, actual **string, data []byte) error {
+	if expected == nil {
+		return nil
+	}
+
+	checksum, err := computeChecksum(algorithm, data)
+	if err != nil {
+		return err
+	}
+	if checksum.value != *expected {
+		return &smithy.GenericAPIError{Code: "BadDigest", Message: fmt.Sprintf("The %s you specified did not match the calculated checksum.", algorithm)}
+	}
+	*actual = aws.String(checksum.value)
+
+	return nil
+}
+
+// Хранилище не поддерживает версионирование: все объекты имеют единственную версию "null", как в бакете без версионирования
+func checkVersion(versionID *string) error {
+	if versionID == nil || *versionID == "null" {
+		return nil
+	}
+	return &smithy.GenericAPIError{Code: "NoSuchVersion", Message: "The specified version does not exist."}
+}
+
+// Разбирает диапазон байт вида "bytes=начало-конец" (включительно) для объекта указанного размера
+func parseByteRange(byteRange string, size int64) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(byteRange, "bytes=")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range %q", byteRange)
+	}
+	startStr, endStr, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid range %q", byteRange)
+	}
+
+	var start, end int64
+	var err error
+	switch {
+	case startStr == "": // Суффиксный диапазон: последние N байт
+		var suffix int64
+		suffix, err = strconv.ParseInt(endStr, 10, 64)
+		start, end = max(size-suffix, 0), size-1
+	case endStr == "": // Открытый диапазон: с позиции до конца объекта
+		start, err = strconv.ParseInt(startStr, 10, 64)
+		end = size - 1
+	default:
+		start, err = strconv.ParseInt(startStr, 10, 64)
+		if err == nil {
+			end, err = strconv.ParseInt(endStr, 10, 64)
+		}
+		end = min(end, size-1)
+	}
+	if err != nil || start < 0 || start > end {
+		return 0, 0, fmt.Errorf("invalid range %q for object of size %d", byteRange, size)
+	}
+
+	return start, end, nil
+}
+
+// Генерирует случайную строку из n байт в шестнадцатеричном виде
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("randomHex: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Класс хранения объекта; для объектов без явно указанного класса — STANDARD
+func storageClassOrDefault(storageClass string) string {
+	if storageClass == "" {
+		return string(types.StorageClassStandard)
+	}
+	return storageClass
+}
+
+// Возвращает указатель на строку или nil для пустой строки (в ответах S3 отсутствующие заголовки равны nil)
+func nonEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// Сортирует ключи и оставляет только ключи с указанным префиксом
+func filterKeys(keys []string, prefix string) []string {
+	filtered := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			filtered = append(filtered, key)
+		}
+	}
+	sort.Strings(filtered)
+
+	return filtered
+}
@@ -0,0 +1,431 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Встроенные хранилища: тесты эмулятора S3 выполняются для каждого из них
+var emulatedBackends = []struct {
+	name string
+	new  func(t *testing.T) StorageBackend
+}{
+	{"memory", func(t *testing.T) StorageBackend { return NewMemoryBackend() }},
+	{"local", func(t *testing.T) StorageBackend {
+		backend, err := NewLocalBackend(t.TempDir())
+		if err != nil {
+			t.Fatalf("NewLocalBackend: %v", err)
+		}
+		return backend
+	}},
+}
+
+func forEachEmulatedBackend(t *testing.T, test func(t *testing.T, backend StorageBackend)) {
+	for _, tt := range emulatedBackends {
+		t.Run(tt.name, func(t *testing.T) {
+			test(t, tt.new(t))
+		})
+	}
+}
+
+func mustPut(t *testing.T, backend StorageBackend, key, content string) *s3.PutObjectOutput {
+	t.Helper()
+
+	output, err := backend.PutObject(context.Background(), putInput("b", key, content))
+	if err != nil {
+		t.Fatalf("PutObject(%q): %v", key, err)
+	}
+
+	return output
+}
+
+func readObject(t *testing.T, backend StorageBackend, key string) string {
+	t.Helper()
+
+	output, err := backend.GetObject(context.Background(), getInput("b", key))
+	if err != nil {
+		t.Fatalf("GetObject(%q): %v", key, err)
+	}
+	defer output.Body.Close()
+
+	content, err := io.ReadAll(output.Body)
+	if err != nil {
+		t.Fatalf("ReadAll(%q): %v", key, err)
+	}
+
+	return string(content)
+}
+
+func TestEmulatedBackendPutGet(t *testing.T) {
+	forEachEmulatedBackend(t, func(t *testing.T, backend StorageBackend) {
+		ctx := context.Background()
+		put := mustPut(t, backend, "dir/file.txt", "hello")
+
+		if content := readObject(t, backend, "dir/file.txt"); content != "hello" {
+			t.Errorf("content = %q, want %q", content, "hello")
+		}
+
+		head, err := backend.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("dir/file.txt")})
+		if err != nil {
+			t.Fatalf("HeadObject: %v", err)
+		}
+		if aws.ToInt64(head.ContentLength) != 5 || aws.ToString(head.ETag) != aws.ToString(put.ETag) {
+			t.Errorf("HeadObject = size %d, ETag %s; want size 5, ETag %s", aws.ToInt64(head.ContentLength), aws.ToString(head.ETag), aws.ToString(put.ETag))
+		}
+
+		output, err := backend.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String("b"), Key: aws.String("dir/file.txt"), Range: aws.String("bytes=1-3")})
+		if err != nil {
+			t.Fatalf("GetObject with range: %v", err)
+		}
+		content, _ := io.ReadAll(output.Body)
+		output.Body.Close()
+		if string(content) != "ell" {
+			t.Errorf("range content = %q, want %q", content, "ell")
+		}
+
+		_, err = backend.GetObject(ctx, getInput("b", "dir/missing.txt"))
+		if !errors.Is(classifyError(err), ErrObjectNotFound) {
+			t.Errorf("GetObject of missing object: err = %v, want ErrObjectNotFound", err)
+		}
+	})
+}
+
+func TestEmulatedBackendListPagination(t *testing.T) {
+	keys := []string{"a/1", "a/2", "a/3", "a/sub/1", "a/sub/2", "b/1"}
+
+	tests := []struct {
+		name      string
+		prefix    string
+		delimiter string
+		maxKeys   int32
+		wantPages [][]string // Ключи и общие префиксы на каждой странице
+	}{
+		{"all keys", "", "", 0, [][]string{keys}},
+		{"pages of two", "a/", "", 2, [][]string{{"a/1", "a/2"}, {"a/3", "a/sub/1"}, {"a/sub/2"}}},
+		{"delimiter", "a/", "/", 0, [][]string{{"a/1", "a/2", "a/3", "a/sub/"}}},
+		{"delimiter with pages", "a/", "/", 3, [][]string{{"a/1", "a/2", "a/3"}, {"a/sub/"}}},
+		{"common prefix not repeated", "", "/", 1, [][]string{{"a/"}, {"b/"}}},
+		{"no matches", "c/", "", 0, [][]string{nil}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachEmulatedBackend(t, func(t *testing.T, backend StorageBackend) {
+				for _, key := range keys {
+					mustPut(t, backend, key, key)
+				}
+
+				input := &s3.ListObjectsV2Input{Bucket: aws.String("b"), Prefix: aws.String(tt.prefix), Delimiter: aws.String(tt.delimiter)}
+				if tt.maxKeys > 0 {
+					input.MaxKeys = aws.Int32(tt.maxKeys)
+				}
+
+				var pages [][]string
+				paginator := s3.NewListObjectsV2Paginator(backend, input)
+				for paginator.HasMorePages() {
+					page, err := paginator.NextPage(context.Background())
+					if err != nil {
+						t.Fatalf("NextPage: %v", err)
+					}
+
+					var entries []string
+					for _, object := range page.Contents {
+						entries = append(entries, aws.ToString(object.Key))
+					}
+					for _, commonPrefix := range page.CommonPrefixes {
+						entries = append(entries, aws.ToString(commonPrefix.Prefix))
+					}
+					slices.Sort(entries)
+					if int(aws.ToInt32(page.KeyCount)) != len(entries) {
+						t.Errorf("KeyCount = %d, want %d", aws.ToInt32(page.KeyCount), len(entries))
+					}
+					pages = append(pages, entries)
+
+					if len(pages) > len(keys)+1 {
+						t.Fatalf("paginator does not stop: %v", pages)
+					}
+				}
+
+				if fmt.Sprint(pages) != fmt.Sprint(tt.wantPages) {
+					t.Errorf("pages = %v, want %v", pages, tt.wantPages)
+				}
+			})
+		})
+	}
+}
+
+func TestEmulatedBackendDeleteObjects(t *testing.T) {
+	tests := []struct {
+		name        string
+		delete      []string
+		quiet       bool
+		wantDeleted []string
+		wantLeft    []string
+	}{
+		{"existing objects", []string{"a", "c"}, false, []string{"a", "c"}, []string{"b"}},
+		{"missing object is not an error", []string{"a", "missing"}, false, []string{"a", "missing"}, []string{"b", "c"}},
+		{"quiet mode", []string{"a", "b", "c"}, true, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachEmulatedBackend(t, func(t *testing.T, backend StorageBackend) {
+				ctx := context.Background()
+				for _, key := range []string{"a", "b", "c"} {
+					mustPut(t, backend, key, key)
+				}
+
+				objects := make([]types.ObjectIdentifier, 0, len(tt.delete))
+				for _, key := range tt.delete {
+					objects = append(objects, types.ObjectIdentifier{Key: aws.String(key)})
+				}
+				output, err := backend.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+					Bucket: aws.String("b"),
+					Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(tt.quiet)},
+				})
+				if err != nil {
+					t.Fatalf("DeleteObjects: %v", err)
+				}
+				if len(output.Errors) > 0 {
+					t.Errorf("DeleteObjects errors = %v", output.Errors)
+				}
+
+				var deleted []string
+				for _, object := range output.Deleted {
+					deleted = append(deleted, aws.ToString(object.Key))
+				}
+				if !slices.Equal(deleted, tt.wantDeleted) {
+					t.Errorf("Deleted = %v, want %v", deleted, tt.wantDeleted)
+				}
+
+				list, err := backend.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: aws.String("b")})
+				if err != nil {
+					t.Fatalf("ListObjectsV2: %v", err)
+				}
+				var left []string
+				for _, object := range list.Contents {
+					left = append(left, aws.ToString(object.Key))
+				}
+				if !slices.Equal(left, tt.wantLeft) {
+					t.Errorf("objects left = %v, want %v", left, tt.wantLeft)
+				}
+			})
+		})
+	}
+}
+
+func TestEmulatedBackendMultipart(t *testing.T) {
+	tests := []struct {
+		name        string
+		parts       []string // Содержимое частей с номерами 1, 2, ...
+		complete    []int32  // Номера частей в запросе на завершение; nil — загрузка отменяется
+		wantContent string
+		wantETag    string // Суффикс ETag с количеством частей
+		wantErr     bool
+	}{
+		{"complete", []string{"hello ", "world"}, []int32{1, 2}, "hello world", "-2\"", false},
+		{"complete with subset of parts", []string{"hello ", "world", "!"}, []int32{1, 3}, "hello !", "-2\"", false},
+		{"part was not uploaded", []string{"hello "}, []int32{1, 2}, "", "", true},
+		{"abort", []string{"hello ", "world"}, nil, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachEmulatedBackend(t, func(t *testing.T, backend StorageBackend) {
+				ctx := context.Background()
+				create, err := backend.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+					Bucket:      aws.String("b"),
+					Key:         aws.String("big.txt"),
+					ContentType: aws.String("text/plain"),
+				})
+				if err != nil {
+					t.Fatalf("CreateMultipartUpload: %v", err)
+				}
+
+				for i, part := range tt.parts {
+					_, err := backend.UploadPart(ctx, &s3.UploadPartInput{
+						Bucket:     aws.String("b"),
+						Key:        aws.String("big.txt"),
+						UploadId:   create.UploadId,
+						PartNumber: aws.Int32(int32(i + 1)),
+						Body:       strings.NewReader(part),
+					})
+					if err != nil {
+						t.Fatalf("UploadPart(%d): %v", i+1, err)
+					}
+				}
+
+				if tt.complete == nil {
+					_, err = backend.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{Bucket: aws.String("b"), Key: aws.String("big.txt"), UploadId: create.UploadId})
+					if err != nil {
+						t.Fatalf("AbortMultipartUpload: %v", err)
+					}
+					_, err = backend.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("big.txt")})
+					if !errors.Is(classifyError(err), ErrObjectNotFound) {
+						t.Errorf("HeadObject after abort: err = %v, want ErrObjectNotFound", err)
+					}
+					_, err = backend.UploadPart(ctx, &s3.UploadPartInput{Bucket: aws.String("b"), Key: aws.String("big.txt"), UploadId: create.UploadId, PartNumber: aws.Int32(1), Body: strings.NewReader("x")})
+					if err == nil {
+						t.Errorf("UploadPart after abort succeeded, want error")
+					}
+					return
+				}
+
+				completed := make([]types.CompletedPart, 0, len(tt.complete))
+				for _, partNumber := range tt.complete {
+					completed = append(completed, types.CompletedPart{PartNumber: aws.Int32(partNumber)})
+				}
+				output, err := backend.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+					Bucket:          aws.String("b"),
+					Key:             aws.String("big.txt"),
+					UploadId:        create.UploadId,
+					MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+				})
+				if tt.wantErr {
+					if err == nil {
+						t.Errorf("CompleteMultipartUpload succeeded, want error")
+					}
+					_, err = backend.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("big.txt")})
+					if !errors.Is(classifyError(err), ErrObjectNotFound) {
+						t.Errorf("HeadObject after failed complete: err = %v, want ErrObjectNotFound", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("CompleteMultipartUpload: %v", err)
+				}
+				if etag := aws.ToString(output.ETag); len(etag) < len(tt.wantETag) || etag[len(etag)-len(tt.wantETag):] != tt.wantETag {
+					t.Errorf("ETag = %s, want suffix %s", etag, tt.wantETag)
+				}
+
+				if content := readObject(t, backend, "big.txt"); content != tt.wantContent {
+					t.Errorf("content = %q, want %q", content, tt.wantContent)
+				}
+				head, err := backend.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("big.txt")})
+				if err != nil {
+					t.Fatalf("HeadObject: %v", err)
+				}
+				if aws.ToString(head.ContentType) != "text/plain" {
+					t.Errorf("ContentType = %q, want %q", aws.ToString(head.ContentType), "text/plain")
+				}
+			})
+		})
+	}
+}
+
+func TestEmulatedBackendPreconditions(t *testing.T) {
+	tests := []struct {
+		name        string
+		exists      bool
+		call        func(ctx context.Context, backend StorageBackend, etag string) error
+		wantErr     error
+		wantContent string // Содержимое объекта после вызова; пустое — объекта быть не должно
+	}{
+		{"put If-None-Match * on new object", false, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := putInput("b", "obj", "new")
+			input.IfNoneMatch = aws.String("*")
+			_, err := backend.PutObject(ctx, input)
+			return err
+		}, nil, "new"},
+		{"put If-None-Match * on existing object", true, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := putInput("b", "obj", "new")
+			input.IfNoneMatch = aws.String("*")
+			_, err := backend.PutObject(ctx, input)
+			return err
+		}, ErrPreconditionFailed, "old"},
+		{"put If-Match with current ETag", true, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := putInput("b", "obj", "new")
+			input.IfMatch = aws.String(etag)
+			_, err := backend.PutObject(ctx, input)
+			return err
+		}, nil, "new"},
+		{"put If-Match with stale ETag", true, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := putInput("b", "obj", "new")
+			input.IfMatch = aws.String(`"stale"`)
+			_, err := backend.PutObject(ctx, input)
+			return err
+		}, ErrPreconditionFailed, "old"},
+		{"put If-Match on missing object", false, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := putInput("b", "obj", "new")
+			input.IfMatch = aws.String(`"any"`)
+			_, err := backend.PutObject(ctx, input)
+			return err
+		}, ErrObjectNotFound, ""},
+		{"get If-Match with current ETag", true, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := getInput("b", "obj")
+			input.IfMatch = aws.String(etag)
+			output, err := backend.GetObject(ctx, input)
+			if err == nil {
+				output.Body.Close()
+			}
+			return err
+		}, nil, "old"},
+		{"get If-Match with stale ETag", true, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := getInput("b", "obj")
+			input.IfMatch = aws.String(`"stale"`)
+			_, err := backend.GetObject(ctx, input)
+			return err
+		}, ErrPreconditionFailed, "old"},
+		{"get If-None-Match with current ETag", true, func(ctx context.Context, backend StorageBackend, etag string) error {
+			input := getInput("b", "obj")
+			input.IfNoneMatch = aws.String(etag)
+			_, err := backend.GetObject(ctx, input)
+			return err
+		}, ErrNotModified, "old"},
+		{"complete multipart If-None-Match * on existing object", true, func(ctx context.Context, backend StorageBackend, etag string) error {
+			create, err := backend.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{Bucket: aws.String("b"), Key: aws.String("obj")})
+			if err != nil {
+				return err
+			}
+			_, err = backend.UploadPart(ctx, &s3.UploadPartInput{Bucket: aws.String("b"), Key: aws.String("obj"), UploadId: create.UploadId, PartNumber: aws.Int32(1), Body: strings.NewReader("new")})
+			if err != nil {
+				return err
+			}
+			_, err = backend.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+				Bucket:          aws.String("b"),
+				Key:             aws.String("obj"),
+				UploadId:        create.UploadId,
+				MultipartUpload: &types.CompletedMultipartUpload{Parts: []types.CompletedPart{{PartNumber: aws.Int32(1)}}},
+				IfNoneMatch:     aws.String("*"),
+			})
+			return err
+		}, ErrPreconditionFailed, "old"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachEmulatedBackend(t, func(t *testing.T, backend StorageBackend) {
+				var etag string
+				if tt.exists {
+					etag = aws.ToString(mustPut(t, backend, "obj", "old").ETag)
+				}
+
+				err := tt.call(context.Background(), backend, etag)
+				if tt.wantErr == nil && err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				if tt.wantErr != nil && !errors.Is(classifyError(err), tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+
+				if tt.wantContent == "" {
+					_, err = backend.HeadObject(context.Background(), &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("obj")})
+					if !errors.Is(classifyError(err), ErrObjectNotFound) {
+						t.Errorf("object was created: HeadObject err = %v", err)
+					}
+				} else if content := readObject(t, backend, "obj"); content != tt.wantContent {
+					t.Errorf("content = %q, want %q", content, tt.wantContent)
+				}
+			})
+		})
+	}
+}
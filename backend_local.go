@@ -0,0 +1,250 @@
+package s3_manager
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const (
+	localMetaDir = ".meta" // Каталог с метаданными объектов (имена бакетов не могут начинаться с точки, поэтому конфликтов нет)
+	localTempDir = ".tmp"  // Каталог для временных файлов при атомарной записи
+)
+
+// Хранилище объектов на локальном диске. Содержимое объекта хранится в файле <dir>/<бакет>/<ключ>,
+// метаданные (MIME-тип, ETag, пользовательские метаданные) — в <dir>/.meta/<бакет>/<ключ>.json.
+// Доступ синхронизируется в emulatedBackend.
+type localStore struct {
+	dir string
+}
+
+// Создаёт хранилище объектов в локальном каталоге dir. Подходит для локального запуска сервисов без S3-совместимого сервера:
+// файлы лежат на диске по своим ключам и их можно просматривать обычными средствами.
+// Ограничения файловой системы сохраняются: нельзя одновременно хранить объекты "a" и "a/b", а также ключи, оканчивающиеся на "/".
+func NewLocalBackend(dir string) (StorageBackend, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("NewLocalBackend: directory is empty")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("NewLocalBackend/Abs: %w", err)
+	}
+	err = os.MkdirAll(absDir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("NewLocalBackend/MkdirAll: %w", err)
+	}
+
+	return newEmulatedBackend(&localStore{dir: absDir}), nil
+}
+
+func (s *localStore) load(bucket, key string, withData bool) (*storedObject, error) {
+	dataPath, metaPath, err := s.paths(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(dataPath)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) || (err == nil && stat.IsDir()) {
+		return nil, errObjectNotStored
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stat %q: %w", key, err)
+	}
+
+	obj := &storedObject{}
+	metaData, err := os.ReadFile(metaPath)
+	switch {
+	case err == nil:
+		err = json.Unmarshal(metaData, obj)
+		if err != nil {
+			return nil, fmt.Errorf("decode metadata of %q: %w", key, err)
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		// Файл положили в каталог в обход хранилища: восстанавливаем метаданные по самому файлу
+		obj.LastModified = stat.ModTime().UTC()
+		obj.ContentType = contentTypeByExtension(key)
+		obj.ETag, err = fileETag(dataPath)
+		if err != nil {
+			return nil, fmt.Errorf("compute etag of %q: %w", key, err)
+		}
+	default:
+		return nil, fmt.Errorf("read metadata of %q: %w", key, err)
+	}
+	obj.Size = stat.Size()
+
+	if withData {
+		obj.Data, err = os.ReadFile(dataPath)
+		if err != nil {
+			return nil, fmt.Errorf("read %q: %w", key, err)
+		}
+		obj.Size = int64(len(obj.Data))
+	}
+
+	return obj, nil
+}
+
+func (s *localStore) save(bucket, key string, obj *storedObject) error {
+	dataPath, metaPath, err := s.paths(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	metaData, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("encode metadata: %w", err)
+	}
+
+	err = s.writeFile(dataPath, obj.Data)
+	if err != nil {
+		return err
+	}
+
+	return s.writeFile(metaPath, metaData)
+}
+
+func (s *localStore) remove(bucket, key string) error {
+	dataPath, metaPath, err := s.paths(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{dataPath, metaPath} {
+		err = os.Remove(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove %q: %w", key, err)
+		}
+	}
+
+	// Удаляем опустевшие каталоги, чтобы «папки» исчезали вместе с последним объектом, как в S3
+	s.removeEmptyDirs(filepath.Dir(dataPath), filepath.Join(s.dir, bucket))
+	s.removeEmptyDirs(filepath.Dir(metaPath), filepath.Join(s.dir, localMetaDir, bucket))
+
+	return nil
+}
+
+func (s *localStore) keys(bucket, prefix string) ([]string, error) {
+	if err := validateLocalBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	bucketDir := filepath.Join(s.dir, bucket)
+
+	var keys []string
+	err := filepath.WalkDir(bucketDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(bucketDir, path)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, filepath.ToSlash(relPath))
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk bucket %q: %w", bucket, err)
+	}
+
+	return filterKeys(keys, prefix), nil
+}
+
+// Возвращает пути к файлу содержимого и файлу метаданных объекта. Ключи, выходящие за пределы каталога бакета, отклоняются.
+func (s *localStore) paths(bucket, key string) (string, string, error) {
+	if err := validateLocalBucket(bucket); err != nil {
+		return "", "", err
+	}
+
+	localKey := filepath.FromSlash(key)
+	if !filepath.IsLocal(localKey) || strings.HasSuffix(key, "/") {
+		return "", "", fmt.Errorf("key %q can not be stored in local filesystem", key)
+	}
+
+	dataPath := filepath.Join(s.dir, bucket, localKey)
+	metaPath := filepath.Join(s.dir, localMetaDir, bucket, localKey) + ".json"
+
+	return dataPath, metaPath, nil
+}
+
+// Атомарно записывает файл: сначала во временный файл, затем переименование
+func (s *localStore) writeFile(path string, data []byte) error {
+	tempDir := filepath.Join(s.dir, localTempDir)
+	for _, dir := range []string{tempDir, filepath.Dir(path)} {
+		err := os.MkdirAll(dir, 0o755)
+		if err != nil {
+			return fmt.Errorf("create directory: %w", err)
+		}
+	}
+
+	tempFile, err := os.CreateTemp(tempDir, "object-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(data)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	err = os.Rename(tempFile.Name(), path)
+	if err != nil {
+		return fmt.Errorf("rename temp file: %w", err)
+	}
+
+	return nil
+}
+
+// Удаляет пустые каталоги, поднимаясь от dir до stopDir (не включая его)
+func (s *localStore) removeEmptyDirs(dir, stopDir string) {
+	for dir != stopDir && strings.HasPrefix(dir, stopDir) {
+		if os.Remove(dir) != nil {
+			return // Каталог не пустой или уже удалён
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// Проверяет, что имя бакета можно использовать как имя каталога
+func validateLocalBucket(bucket string) error {
+	if bucket == "" || strings.HasPrefix(bucket, ".") || strings.ContainsAny(bucket, `/\`) {
+		return fmt.Errorf("invalid bucket name %q", bucket)
+	}
+	return nil
+}
+
+// Вычисляет ETag файла (MD5 содержимого в кавычках)
+func fileETag(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`, nil
+}
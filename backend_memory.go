@@ -0,0 +1,62 @@
+package s3_manager
+
+import (
+	"bytes"
+	"maps"
+)
+
+// Хранилище объектов в памяти процесса. Доступ синхронизируется в emulatedBackend.
+type memoryStore struct {
+	buckets map[string]map[string]*storedObject
+}
+
+// Создаёт хранилище объектов в памяти процесса. Подходит для тестов и локального запуска без S3-совместимого сервера;
+// содержимое теряется при завершении процесса.
+func NewMemoryBackend() StorageBackend {
+	return newEmulatedBackend(&memoryStore{
+		buckets: make(map[string]map[string]*storedObject),
+	})
+}
+
+func (s *memoryStore) load(bucket, key string, withData bool) (*storedObject, error) {
+	obj, ok := s.buckets[bucket][key]
+	if !ok {
+		return nil, errObjectNotStored
+	}
+
+	loaded := *obj
+	loaded.Metadata = maps.Clone(obj.Metadata)
+	loaded.Data = nil
+	if withData {
+		loaded.Data = bytes.Clone(obj.Data)
+	}
+
+	return &loaded, nil
+}
+
+func (s *memoryStore) save(bucket, key string, obj *storedObject) error {
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]*storedObject)
+	}
+
+	saved := *obj
+	saved.Metadata = maps.Clone(obj.Metadata)
+	saved.Data = bytes.Clone(obj.Data)
+	s.buckets[bucket][key] = &saved
+
+	return nil
+}
+
+func (s *memoryStore) remove(bucket, key string) error {
+	delete(s.buckets[bucket], key)
+	return nil
+}
+
+func (s *memoryStore) keys(bucket, prefix string) ([]string, error) {
+	keys := make([]string, 0, len(s.buckets[bucket]))
+	for key := range s.buckets[bucket] {
+		keys = append(keys, key)
+	}
+
+	return filterKeys(keys, prefix), nil
+}
@@ -0,0 +1,125 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const defaultBatchConcurrency = 4 // Количество одновременных операций в пакетных методах по умолчанию
+
+// Ошибка пакетной операции. Содержит ошибки по каждому файлу, который не удалось обработать; остальные файлы обработаны успешно.
+type BatchError struct {
+	Errors map[string]error // Ошибки по файлам. Ключ зависит от метода: имя файла, ключ объекта или индекс файла (см. описание метода).
+}
+
+func (e *BatchError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+
+	return fmt.Sprintf("%d file(s) failed: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+// Позволяет проверять ошибки отдельных файлов через errors.Is/errors.As
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Метод для загрузки нескольких файлов в бакет по одному пути. Файлы загружаются параллельно (см. WithConcurrency).
+// Возвращает ссылки на файлы в том же порядке, что и data.Files. Если часть файлов загрузить не удалось, для них возвращается пустая ссылка
+// и ошибка *BatchError с ошибками по индексам файлов в data.Files (имена файлов в пакете могут совпадать).
+func (r *s3Manager) PutFiles(ctx context.Context, data BucketFilesData, opts ...Option) ([]string, error) {
+	r = r.forCall(opts)
+
+	if len(data.Files) == 0 {
+		return nil, nil
+	}
+
+	o := r.applyOptions(opts)
+
+	var (
+		mu       sync.Mutex
+		fileURLs = make([]string, len(data.Files))
+		failed   = make(map[string]error)
+	)
+
+	tasks := r.newBatch(o.concurrency)
+	for i := range data.Files {
+		tasks.Go(func() {
+			fileURL, err := r.PutFile(ctx, data.Path, &data.Files[i], opts...)
+			if err != nil {
+				mu.Lock()
+				failed[strconv.Itoa(i)] = fmt.Errorf("%s: %w", data.Files[i].Name, err)
+				mu.Unlock()
+				return
+			}
+			fileURLs[i] = fileURL
+		})
+	}
+	tasks.Wait()
+
+	if len(failed) > 0 {
+		return fileURLs, fmt.Errorf("PutFiles: %w", &BatchError{Errors: failed})
+	}
+
+	return fileURLs, nil
+}
+
+const maxDeleteObjects = 1000 // Максимальное количество ключей в одном запросе DeleteObjects
+
+// Удаляет объекты по списку ключей пачками по 1000 штук. Возвращает количество удалённых объектов.
+// Если часть объектов удалить не удалось, возвращается ошибка с описанием первой из них.
+func (r *s3Manager) deleteObjects(ctx context.Context, keys []string) (int, error) {
+	deleted := 0
+	for start := 0; start < len(keys); start += maxDeleteObjects {
+		batch := keys[start:min(start+maxDeleteObjects, len(keys))]
+
+		objectIds := make([]types.ObjectIdentifier, 0, len(batch))
+		for _, key := range batch {
+			objectIds = append(objectIds, types.ObjectIdentifier{
+				Key: aws.String(key),
+			})
+		}
+
+		deleteInput := &s3.DeleteObjectsInput{
+			Bucket: &r.cfg.Name,
+			Delete: &types.Delete{
+				Objects: objectIds,
+				Quiet:   aws.Bool(true), // Подавляем вывод списка удалённых объектов, в ответе остаются только ошибки
+			},
+		}
+
+		output, err := r.client.DeleteObjects(ctx, deleteInput)
+		if err != nil {
+			return deleted, fmt.Errorf("deleteObjects/DeleteObjects: %w", classifyError(err))
+		}
+
+		deleted += len(batch) - len(output.Errors)
+		if len(output.Errors) > 0 {
+			deleteErr := output.Errors[0]
+			return deleted, fmt.Errorf("deleteObjects/DeleteObjects: failed to delete %d object(s), first: %s: %s (%s)",
+				len(output.Errors), aws.ToString(deleteErr.Key), aws.ToString(deleteErr.Message), aws.ToString(deleteErr.Code))
+		}
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,54 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestPutFilesDuplicateNames(t *testing.T) {
+	ok := func() BucketFile {
+		return BucketFile{Name: "report.csv", File: bytes.NewReader([]byte("a,b"))}
+	}
+	failing := func() BucketFile {
+		return BucketFile{Name: "report.csv", File: io.MultiReader(bytes.NewReader([]byte("a")), failingReader{})}
+	}
+
+	tests := []struct {
+		name       string
+		files      []BucketFile
+		wantFailed []string
+	}{
+		{"second fails", []BucketFile{ok(), failing()}, []string{"1"}},
+		{"both fail", []BucketFile{failing(), failing()}, []string{"0", "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+
+			fileURLs, err := manager.PutFiles(context.Background(), BucketFilesData{Files: tt.files}, withRawName())
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("PutFiles error = %v, want *BatchError", err)
+			}
+
+			var failed []string
+			for key := range batchErr.Errors {
+				failed = append(failed, key)
+			}
+			slices.Sort(failed)
+			if !slices.Equal(failed, tt.wantFailed) {
+				t.Errorf("BatchError keys = %v, want %v", failed, tt.wantFailed)
+			}
+			for i, fileURL := range fileURLs {
+				if wantEmpty := slices.Contains(tt.wantFailed, strconv.Itoa(i)); (fileURL == "") != wantEmpty {
+					t.Errorf("fileURLs[%d] = %q, want empty: %v", i, fileURL, wantEmpty)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Правило CORS бакета. Нужно, чтобы браузер мог загружать и скачивать файлы напрямую по подписанным ссылкам.
+type CORSRule struct {
+	AllowedOrigins []string      // Разрешённые источники (например, "https://examplesite.com" или "*")
+	AllowedMethods []string      // Разрешённые HTTP-методы (например, "GET", "PUT")
+	AllowedHeaders []string      // Разрешённые заголовки запроса (например, "*")
+	ExposeHeaders  []string      // Заголовки ответа, доступные браузеру (например, "ETag")
+	MaxAge         time.Duration // Время кеширования preflight-запроса браузером
+}
+
+// Метод для создания бакета, если он ещё не существует. Используется для самостоятельной настройки окружения (например, локального MinIO или нового деплоя).
+// Бакет создаётся в регионе из конфига. Опции WithVersioning и WithCORS применяются и к уже существующему бакету, WithBucketACL — только при создании.
+func (r *s3Manager) EnsureBucket(ctx context.Context, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return fmt.Errorf("EnsureBucket: %w", err)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return fmt.Errorf("EnsureBucket/s3Client: %w", err)
+	}
+	o := r.applyOptions(opts)
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	_, err = client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &r.cfg.Name})
+	err = classifyError(err)
+	switch {
+	case err == nil:
+	case errors.Is(err, ErrBucketNotFound), errors.Is(err, ErrObjectNotFound): // HeadBucket возвращает 404 без кода ошибки
+		err = r.createBucket(ctx, client, o)
+		if err != nil {
+			return fmt.Errorf("EnsureBucket/createBucket: %w", err)
+		}
+	default:
+		return fmt.Errorf("EnsureBucket/HeadBucket: %w", err)
+	}
+
+	if o.versioning {
+		err = r.putBucketVersioning(ctx, client, types.BucketVersioningStatusEnabled)
+		if err != nil {
+			return fmt.Errorf("EnsureBucket/putBucketVersioning: %w", err)
+		}
+	}
+
+	if len(o.cors) > 0 {
+		err = r.putBucketCORS(ctx, client, o.cors)
+		if err != nil {
+			return fmt.Errorf("EnsureBucket/putBucketCORS: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Создаёт бакет. Бакет, созданный параллельно другим экземпляром сервиса, ошибкой не считается.
+func (r *s3Manager) createBucket(ctx context.Context, client *s3.Client, o operationOptions) error {
+	input := &s3.CreateBucketInput{
+		Bucket: &r.cfg.Name,
+		ACL:    o.bucketACL,
+	}
+	// us-east-1 — регион по умолчанию, для него LocationConstraint передавать нельзя
+	if r.cfg.Region != "" && r.cfg.Region != "us-east-1" {
+		input.CreateBucketConfiguration = &types.CreateBucketConfiguration{
+			LocationConstraint: types.BucketLocationConstraint(r.cfg.Region),
+		}
+	}
+	if o.objectLock {
+		input.ObjectLockEnabledForBucket = aws.Bool(true)
+	}
+	// В новых бакетах AWS ACL отключены, поэтому для загрузки файлов с ACL их нужно включить
+	if o.bucketACL != "" || r.cfg.DefaultACL != NoACL {
+		input.ObjectOwnership = types.ObjectOwnershipBucketOwnerPreferred
+	}
+
+	start := time.Now()
+	_, err := client.CreateBucket(ctx, input)
+	r.audit(ctx, "CreateBucket", "", start, err)
+	if err != nil && apiErrorCode(err) != "BucketAlreadyOwnedByYou" {
+		return fmt.Errorf("createBucket/CreateBucket: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Включает или приостанавливает версионирование объектов в бакете
+func (r *s3Manager) putBucketVersioning(ctx context.Context, client *s3.Client, status types.BucketVersioningStatus) error {
+	start := time.Now()
+	_, err := client.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket:                  &r.cfg.Name,
+		VersioningConfiguration: &types.VersioningConfiguration{Status: status},
+	})
+	r.audit(ctx, "PutBucketVersioning", "", start, err)
+	if err != nil {
+		return fmt.Errorf("putBucketVersioning/PutBucketVersioning: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Заменяет правила CORS бакета
+func (r *s3Manager) putBucketCORS(ctx context.Context, client *s3.Client, rules []CORSRule) error {
+	s3Rules := make([]types.CORSRule, 0, len(rules))
+	for _, rule := range rules {
+		s3Rules = append(s3Rules, types.CORSRule{
+			AllowedOrigins: rule.AllowedOrigins,
+			AllowedMethods: rule.AllowedMethods,
+			AllowedHeaders: rule.AllowedHeaders,
+			ExposeHeaders:  rule.ExposeHeaders,
+			MaxAgeSeconds:  aws.Int32(int32(rule.MaxAge / time.Second)),
+		})
+	}
+
+	start := time.Now()
+	_, err := client.PutBucketCors(ctx, &s3.PutBucketCorsInput{
+		Bucket:            &r.cfg.Name,
+		CORSConfiguration: &types.CORSConfiguration{CORSRules: s3Rules},
+	})
+	r.audit(ctx, "PutBucketCors", "", start, err)
+	if err != nil {
+		return fmt.Errorf("putBucketCORS/PutBucketCors: %w", classifyError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,323 @@
+package s3_manager
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	defaultCacheTTL        = time.Minute // Время хранения информации о файлах и списков файлов в кеше по умолчанию
+	defaultCacheMaxEntries = 10000       // Максимальное количество записей в MemoryCache по умолчанию
+	cacheKeyPrefix         = "s3m:"      // Префикс ключей менеджера в кеше, общем с другими данными
+)
+
+// Кеш для ответов хранилища (см. Config.Cache). Значения — непрозрачные байты, поэтому кеш может быть внешним и общим для нескольких
+// экземпляров сервиса (см. rediscache.New); для одного экземпляра подходит NewMemoryCache.
+// Ошибки кеша не считаются ошибками операций с файлами: при ошибке запрос выполняется в хранилище.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error) // Возвращает значение и true, если ключ есть в кеше и не истёк
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Кеш в памяти процесса с вытеснением давно не использованных записей. Подходит для одного экземпляра сервиса.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // Записи от недавно использованных к давно не использованным
+}
+
+var _ Cache = (*MemoryCache)(nil)
+
+type memoryCacheEntry struct {
+	key     string
+	value   []byte
+	expires time.Time // Нулевое время — запись не истекает
+}
+
+// Создаёт кеш в памяти не более чем на maxEntries записей (0 — 10000 записей по умолчанию)
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultCacheMaxEntries
+	}
+
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryCacheEntry)
+	if !entry.expires.IsZero() && time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+
+	return entry.value, true, nil
+}
+
+// Сохраняет значение на время ttl (0 — без ограничения времени)
+func (c *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := &memoryCacheEntry{key: key, value: bytes.Clone(value)}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+func (c *MemoryCache) Delete(_ context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+	}
+
+	return nil
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*memoryCacheEntry).key)
+}
+
+// Хранилище, которое кеширует информацию об объектах и списки объектов (StatFile, FileExists, GetFiles, ListObjects) на время ttl.
+// Записи привязаны к поколению объекта или списков бакета: изменяющий запрос через менеджер меняет поколение, и записи, сохранённые
+// до него, больше не читаются, в том числе если ответ на запрос, начатый до изменения, сохранён после него. Изменения в обход менеджера
+// (другими сервисами или в консоли хранилища) становятся видны не позже чем через ttl. Подключается автоматически, если в конфиге указан
+// Cache или CacheTTL.
+type cachingBackend struct {
+	next   StorageBackend
+	cache  Cache
+	ttl    time.Duration
+	logger *slog.Logger
+}
+
+var _ StorageBackend = (*cachingBackend)(nil)
+
+// Возвращает хранилище, вокруг которого построена обёртка
+func (b *cachingBackend) Unwrap() StorageBackend {
+	return b.next
+}
+
+func (b *cachingBackend) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	generation, ok := b.generation(ctx, objectGenerationKey(aws.ToString(params.Bucket), aws.ToString(params.Key)))
+	if !ok {
+		return b.next.HeadObject(ctx, params, optFns...)
+	}
+	key := cacheKeyPrefix + "head:" + generation + ":" + hashCacheKey(headFlightKey(params))
+
+	var cached s3.HeadObjectOutput
+	if b.lookup(ctx, key, &cached) {
+		return &cached, nil
+	}
+	output, err := b.next.HeadObject(ctx, params, optFns...)
+	if err == nil {
+		b.store(ctx, key, output)
+	}
+	return output, err
+}
+
+func (b *cachingBackend) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	generation, ok := b.generation(ctx, listGenerationKey(aws.ToString(params.Bucket)))
+	if !ok {
+		return b.next.ListObjectsV2(ctx, params, optFns...)
+	}
+	key := cacheKeyPrefix + "list:" + generation + ":" + hashCacheKey(listFlightKey(params))
+
+	var cached s3.ListObjectsV2Output
+	if b.lookup(ctx, key, &cached) {
+		return &cached, nil
+	}
+	output, err := b.next.ListObjectsV2(ctx, params, optFns...)
+	if err == nil {
+		b.store(ctx, key, output)
+	}
+	return output, err
+}
+
+func (b *cachingBackend) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return b.next.GetObject(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	defer b.forget(ctx, aws.ToString(params.Bucket), aws.ToString(params.Key))
+	return b.next.PutObject(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	defer b.forget(ctx, aws.ToString(params.Bucket), aws.ToString(params.Key))
+	return b.next.DeleteObject(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	var keys []string
+	if params.Delete != nil {
+		for _, object := range params.Delete.Objects {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	defer b.forget(ctx, aws.ToString(params.Bucket), keys...)
+	return b.next.DeleteObjects(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	defer b.forget(ctx, aws.ToString(params.Bucket), aws.ToString(params.Key))
+	return b.next.CopyObject(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return b.next.CreateMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return b.next.UploadPart(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	return b.next.UploadPartCopy(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	defer b.forget(ctx, aws.ToString(params.Bucket), aws.ToString(params.Key))
+	return b.next.CompleteMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *cachingBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return b.next.AbortMultipartUpload(ctx, params, optFns...)
+}
+
+// Возвращает текущее поколение записей по ключу поколения (пустая строка, если объект или списки ещё не менялись).
+// Возвращает false, если кеш недоступен: тогда запрос выполняется в хранилище без кеша.
+func (b *cachingBackend) generation(ctx context.Context, generationKey string) (string, bool) {
+	generation, _, err := b.cache.Get(ctx, generationKey)
+	if err != nil {
+		b.warn(ctx, "cache get failed", generationKey, err)
+		return "", false
+	}
+
+	return string(generation), true
+}
+
+// Читает запись из кеша в value. Возвращает false, если записи нет или её не удалось прочитать.
+func (b *cachingBackend) lookup(ctx context.Context, key string, value any) bool {
+	data, ok, err := b.cache.Get(ctx, key)
+	if err != nil {
+		b.warn(ctx, "cache get failed", key, err)
+		return false
+	}
+
+	return ok && json.Unmarshal(data, value) == nil
+}
+
+// Сохраняет ответ хранилища в кеш
+func (b *cachingBackend) store(ctx context.Context, key string, value any) {
+	data, err := json.Marshal(value)
+	if err == nil {
+		err = b.cache.Set(ctx, key, data, b.ttl)
+	}
+	if err != nil {
+		b.warn(ctx, "cache set failed", key, err)
+	}
+}
+
+// Меняет поколение объектов с ключами keys и списков объектов бакета, чтобы записи, сохранённые до изменения, больше не читались.
+// Поколение хранится не меньше ttl, чтобы записи прежнего поколения успели истечь до того, как оно будет забыто.
+func (b *cachingBackend) forget(ctx context.Context, bucket string, keys ...string) {
+	var token [8]byte
+	_, _ = rand.Read(token[:])
+	generation := []byte(hex.EncodeToString(token[:]))
+
+	for _, generationKey := range append([]string{listGenerationKey(bucket)}, objectGenerationKeys(bucket, keys)...) {
+		if err := b.cache.Set(ctx, generationKey, generation, b.ttl); err != nil {
+			b.warn(ctx, "cache invalidation failed", generationKey, err)
+		}
+	}
+}
+
+func (b *cachingBackend) warn(ctx context.Context, msg, key string, err error) {
+	if b.logger != nil {
+		b.logger.WarnContext(ctx, msg, "key", key, "error", err)
+	}
+}
+
+// Ключ поколения списков объектов бакета
+func listGenerationKey(bucket string) string {
+	return cacheKeyPrefix + "gen:list:" + bucket
+}
+
+// Ключ поколения информации об объекте
+func objectGenerationKey(bucket, key string) string {
+	return cacheKeyPrefix + "gen:object:" + bucket + "/" + key
+}
+
+func objectGenerationKeys(bucket string, keys []string) []string {
+	generationKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		generationKeys = append(generationKeys, objectGenerationKey(bucket, key))
+	}
+
+	return generationKeys
+}
+
+// Хеширует параметры запроса для ключа записи: в параметрах может быть ключ шифрования SSE-C, который нельзя хранить во внешнем кеше
+func hashCacheKey(requestKey string) string {
+	sum := sha256.Sum256([]byte(requestKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// Сбрасывает кеш и объединение запросов для объектов, изменённых запросами в обход StorageBackend (например, сроки хранения и юридические блокировки)
+func (r *s3Manager) forgetObjects(ctx context.Context, keys ...string) {
+	backend := r.client
+	for {
+		switch client := backend.(type) {
+		case *cachingBackend:
+			client.forget(ctx, r.cfg.Name, keys...)
+		case *coalescingBackend:
+			client.forget()
+		}
+		wrapper, ok := backend.(interface{ Unwrap() StorageBackend })
+		if !ok {
+			return
+		}
+		backend = wrapper.Unwrap()
+	}
+}
@@ -0,0 +1,199 @@
+package s3_manager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Построитель набора каталогов с проверкой паттернов путей. В отличие от AddCatalog, ошибки в паттернах (лишний или пропущенный %d, незакрытый {параметр},
+// путь без "/" в конце, абсолютный путь, повторное объявление каталога) обнаруживаются при запуске сервиса в Build, а не превращаются
+// в некорректные ключи объектов во время работы. Все проблемы возвращаются одной ошибкой.
+//
+//	err := s3_manager.NewCatalogRegistryBuilder().
+//		Entity("users", "users/%d/").
+//		Entity("product_certificates", "products/%d/certificates/").
+//		WithRules("product_certificates", s3_manager.UploadRules{AllowedExtensions: []string{".pdf"}}).
+//		Entity("org_users", "orgs/{org_id}/users/{user_id}/").
+//		Static("banners", "banners/").
+//		Build(manager)
+type CatalogRegistryBuilder struct {
+	catalogs []builderCatalog
+	problems []string
+}
+
+// Каталог, объявленный в построителе, и его настройки
+type builderCatalog struct {
+	catalogType  CatalogType
+	pathPattern  string
+	rules        *UploadRules
+	compression  Compression
+	storageClass types.StorageClass
+	headers      *ObjectHeaders
+	image        *ImageConfig
+}
+
+// Создаёт пустой построитель набора каталогов
+func NewCatalogRegistryBuilder() *CatalogRegistryBuilder {
+	return &CatalogRegistryBuilder{}
+}
+
+// Объявляет каталог сущности: паттерн должен содержать ровно один %d для StoragePath.EntityID или EntityKey (например, "products/%d/certificates/")
+// или именованные параметры для StoragePath.Params (например, "orgs/{org_id}/users/{user_id}/")
+func (b *CatalogRegistryBuilder) Entity(catalogType CatalogType, pathPattern string) *CatalogRegistryBuilder {
+	count := strings.Count(pathPattern, "%d")
+	params, err := patternParams(pathPattern)
+	if err == nil && (count > 1 || count == 0 && len(params) == 0) {
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: pattern %q must contain exactly one %%d or named placeholders, found %d %%d", catalogType, pathPattern, count))
+	}
+
+	return b.add(catalogType, pathPattern)
+}
+
+// Объявляет общий каталог без идентификатора сущности (например, "banners/"): паттерн не должен содержать параметров
+func (b *CatalogRegistryBuilder) Static(catalogType CatalogType, pathPattern string) *CatalogRegistryBuilder {
+	if strings.ContainsAny(pathPattern, "%{") {
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: static pattern %q must not contain placeholders", catalogType, pathPattern))
+	}
+
+	return b.add(catalogType, pathPattern)
+}
+
+// Задаёт правила проверки загружаемых файлов для объявленного каталога (см. AddCatalogWithRules)
+func (b *CatalogRegistryBuilder) WithRules(catalogType CatalogType, rules UploadRules) *CatalogRegistryBuilder {
+	if catalog := b.find(catalogType, "WithRules"); catalog != nil {
+		catalog.rules = &rules
+	}
+
+	return b
+}
+
+// Задаёт сжатие загружаемых файлов по умолчанию для объявленного каталога (см. AddCatalogWithCompression)
+func (b *CatalogRegistryBuilder) WithCompression(catalogType CatalogType, compression Compression) *CatalogRegistryBuilder {
+	if catalog := b.find(catalogType, "WithCompression"); catalog != nil {
+		catalog.compression = compression
+	}
+
+	return b
+}
+
+// Задаёт класс хранения загружаемых файлов по умолчанию для объявленного каталога (см. AddCatalogWithStorageClass)
+func (b *CatalogRegistryBuilder) WithStorageClass(catalogType CatalogType, storageClass types.StorageClass) *CatalogRegistryBuilder {
+	if catalog := b.find(catalogType, "WithStorageClass"); catalog != nil {
+		catalog.storageClass = storageClass
+	}
+
+	return b
+}
+
+// Задаёт HTTP-заголовки загружаемых файлов по умолчанию для объявленного каталога (см. AddCatalogWithHeaders)
+func (b *CatalogRegistryBuilder) WithHeaders(catalogType CatalogType, headers ObjectHeaders) *CatalogRegistryBuilder {
+	if catalog := b.find(catalogType, "WithHeaders"); catalog != nil {
+		catalog.headers = &headers
+	}
+
+	return b
+}
+
+// Задаёт обработку изображений для объявленного каталога (см. AddImageCatalog)
+func (b *CatalogRegistryBuilder) WithImages(catalogType CatalogType, cfg ImageConfig) *CatalogRegistryBuilder {
+	if catalog := b.find(catalogType, "WithImages"); catalog != nil {
+		catalog.image = &cfg
+	}
+
+	return b
+}
+
+// Метод для регистрации объявленных каталогов в менеджере. Если хотя бы один паттерн некорректен или тип каталога уже зарегистрирован
+// в менеджере, ни один каталог не регистрируется и возвращается ошибка ErrInvalidInput со списком всех проблем.
+func (b *CatalogRegistryBuilder) Build(manager CatalogRegistry) error {
+	problems := append([]string(nil), b.problems...)
+	registered := manager.Catalogs()
+	for _, catalog := range b.catalogs {
+		if _, ok := registered[catalog.catalogType]; ok {
+			problems = append(problems, fmt.Sprintf("catalog %q is already registered in the manager", catalog.catalogType))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("CatalogRegistryBuilder.Build: %w: %s", ErrInvalidInput, strings.Join(problems, "; "))
+	}
+
+	for _, catalog := range b.catalogs {
+		manager.AddCatalog(catalog.catalogType, catalog.pathPattern)
+		if catalog.rules != nil {
+			manager.AddCatalogWithRules(catalog.catalogType, catalog.pathPattern, *catalog.rules)
+		}
+		if catalog.compression != "" {
+			manager.AddCatalogWithCompression(catalog.catalogType, catalog.pathPattern, catalog.compression)
+		}
+		if catalog.storageClass != "" {
+			manager.AddCatalogWithStorageClass(catalog.catalogType, catalog.pathPattern, catalog.storageClass)
+		}
+		if catalog.headers != nil {
+			manager.AddCatalogWithHeaders(catalog.catalogType, catalog.pathPattern, *catalog.headers)
+		}
+		if catalog.image != nil {
+			manager.AddImageCatalog(catalog.catalogType, catalog.pathPattern, *catalog.image)
+		}
+	}
+
+	return nil
+}
+
+// Проверяет общие требования к паттерну и добавляет каталог в построитель
+func (b *CatalogRegistryBuilder) add(catalogType CatalogType, pathPattern string) *CatalogRegistryBuilder {
+	switch {
+	case catalogType == "":
+		b.problems = append(b.problems, fmt.Sprintf("pattern %q: catalog type is empty", pathPattern))
+	case catalogType == PathCustomCatalog:
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q is reserved for StoragePath.CustomPath", catalogType))
+	case b.lookup(catalogType) != nil:
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q is declared more than once", catalogType))
+	}
+
+	switch {
+	case pathPattern == "":
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: pattern is empty", catalogType))
+	case strings.HasPrefix(pathPattern, "/") || strings.Contains(pathPattern, "://"):
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: pattern %q must be relative to the root catalog", catalogType, pathPattern))
+	case !strings.HasSuffix(pathPattern, "/"):
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: pattern %q must end with \"/\"", catalogType, pathPattern))
+	}
+	if strings.Contains(pathPattern, "//") || strings.Contains("/"+pathPattern, "/../") || strings.Contains("/"+pathPattern, "/./") {
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: pattern %q contains empty or relative path segments", catalogType, pathPattern))
+	}
+	if strings.Count(pathPattern, "%") != strings.Count(pathPattern, "%d") {
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: pattern %q may contain only %%d and {name} placeholders", catalogType, pathPattern))
+	}
+	if _, err := patternParams(pathPattern); err != nil {
+		b.problems = append(b.problems, fmt.Sprintf("catalog %q: %v", catalogType, err))
+	}
+
+	if b.lookup(catalogType) == nil {
+		b.catalogs = append(b.catalogs, builderCatalog{catalogType: catalogType, pathPattern: pathPattern})
+	}
+
+	return b
+}
+
+// Возвращает объявленный каталог для настройки методом method или записывает проблему, если каталог не объявлен
+func (b *CatalogRegistryBuilder) find(catalogType CatalogType, method string) *builderCatalog {
+	catalog := b.lookup(catalogType)
+	if catalog == nil {
+		b.problems = append(b.problems, fmt.Sprintf("%s: catalog %q is not declared", method, catalogType))
+	}
+
+	return catalog
+}
+
+// Возвращает объявленный каталог или nil
+func (b *CatalogRegistryBuilder) lookup(catalogType CatalogType) *builderCatalog {
+	for i := range b.catalogs {
+		if b.catalogs[i].catalogType == catalogType {
+			return &b.catalogs[i]
+		}
+	}
+
+	return nil
+}
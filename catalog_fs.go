@@ -0,0 +1,228 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Файловая система только для чтения поверх каталога в бакете. Реализует fs.FS и fs.ReadDirFS, поэтому её можно передать
+// в template.ParseFS, fs.WalkDir и т.п., а через http.FS(fsys) — в http.FileServer. Подкаталогами считаются общие префиксы ключей до "/".
+// Файлы читаются диапазонными запросами (см. ObjectReader), поэтому http.FileServer поддерживает Range-запросы без скачивания всего файла.
+type CatalogFS struct {
+	ctx     context.Context
+	manager *s3Manager
+	prefix  string // Полный путь каталога в бакете (пустой или оканчивающийся на "/")
+	o       operationOptions
+	err     error // Ошибка формирования пути каталога (например, ErrUnknownCatalog), возвращается при открытии файлов
+}
+
+var (
+	_ fs.FS        = (*CatalogFS)(nil)
+	_ fs.ReadDirFS = (*CatalogFS)(nil)
+)
+
+// Метод для получения файловой системы только для чтения поверх каталога storagePath (см. CatalogFS).
+// Контекст и опции (например, WithEncryption для SSE-C) действуют на все запросы файловой системы.
+func (r *s3Manager) FS(ctx context.Context, storagePath StoragePath, opts ...Option) *CatalogFS {
+	r = r.forCall(opts)
+
+	prefix, err := r.objectKey(storagePath, "")
+
+	return &CatalogFS{
+		ctx:     ctx,
+		manager: r,
+		prefix:  prefix,
+		o:       r.applyOptions(opts),
+		err:     err,
+	}
+}
+
+// Открывает файл или каталог. Файл реализует io.Seeker и io.ReaderAt, каталог — fs.ReadDirFile.
+func (c *CatalogFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if c.err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: c.err}
+	}
+	if name == "." {
+		return &catalogDir{fsys: c, name: name, prefix: c.prefix}, nil
+	}
+
+	key := c.prefix + name
+	object, err := c.manager.openObject(c.ctx, name, key, c.o)
+	if err == nil {
+		return &catalogFile{ObjectReader: object}, nil
+	}
+	if !errors.Is(err, ErrObjectNotFound) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	// Объекта с таким ключом нет: проверяем, есть ли объекты с этим префиксом (подкаталог)
+	output, err := c.manager.client.ListObjectsV2(c.ctx, &s3.ListObjectsV2Input{
+		Bucket:  &c.manager.cfg.Name,
+		Prefix:  aws.String(key + "/"),
+		MaxKeys: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: classifyError(err)}
+	}
+	if len(output.Contents) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &catalogDir{fsys: c, name: name, prefix: key + "/"}, nil
+}
+
+// Возвращает содержимое каталога, отсортированное по имени
+func (c *CatalogFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	file, err := c.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	dir, ok := file.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+
+	return dir.ReadDir(-1)
+}
+
+// Получает содержимое каталога: подкаталоги (общие префиксы) и файлы, отсортированные по имени
+func (c *CatalogFS) list(prefix string) ([]fs.DirEntry, error) {
+	ctx, cancel := withTimeout(c.ctx, c.manager.cfg.ListTimeout)
+	defer cancel()
+
+	var entries []fs.DirEntry
+	paginator := s3.NewListObjectsV2Paginator(c.manager.client, &s3.ListObjectsV2Input{
+		Bucket:    &c.manager.cfg.Name,
+		Prefix:    &prefix,
+		Delimiter: aws.String("/"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("CatalogFS.list/ListObjectsV2: %w", classifyError(err))
+		}
+
+		for _, commonPrefix := range page.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(commonPrefix.Prefix), prefix), "/")
+			if name != "" {
+				entries = append(entries, &catalogFileInfo{name: name, dir: true})
+			}
+		}
+		for _, object := range page.Contents {
+			name := strings.TrimPrefix(aws.ToString(object.Key), prefix)
+			if name == "" {
+				continue // Объект-маркер каталога (ключ, оканчивающийся на "/")
+			}
+			entries = append(entries, &catalogFileInfo{
+				name:    name,
+				size:    aws.ToInt64(object.Size),
+				modTime: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+// Файл файловой системы CatalogFS
+type catalogFile struct {
+	*ObjectReader
+}
+
+func (f *catalogFile) Stat() (fs.FileInfo, error) {
+	info := f.Info()
+	return &catalogFileInfo{
+		name:    path.Base(info.Name),
+		size:    info.Size,
+		modTime: info.LastModified,
+	}, nil
+}
+
+// Каталог файловой системы CatalogFS
+type catalogDir struct {
+	fsys    *CatalogFS
+	name    string
+	prefix  string
+	entries []fs.DirEntry // Содержимое каталога, загружается при первом вызове ReadDir
+	loaded  bool
+}
+
+func (d *catalogDir) Stat() (fs.FileInfo, error) {
+	return &catalogFileInfo{name: path.Base(d.name), dir: true}, nil
+}
+
+func (d *catalogDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *catalogDir) Close() error {
+	return nil
+}
+
+// Возвращает следующие n записей каталога (все оставшиеся при n <= 0), как требует fs.ReadDirFile
+func (d *catalogDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.loaded {
+		entries, err := d.fsys.list(d.prefix)
+		if err != nil {
+			return nil, &fs.PathError{Op: "readdir", Path: d.name, Err: err}
+		}
+		d.entries, d.loaded = entries, true
+	}
+
+	if n <= 0 {
+		entries := d.entries
+		d.entries = nil
+		return entries, nil
+	}
+	if len(d.entries) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(d.entries))
+	entries := d.entries[:n]
+	d.entries = d.entries[n:]
+
+	return entries, nil
+}
+
+// Информация о файле или каталоге CatalogFS. Реализует и fs.FileInfo, и fs.DirEntry.
+type catalogFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *catalogFileInfo) Name() string               { return i.name }
+func (i *catalogFileInfo) Size() int64                { return i.size }
+func (i *catalogFileInfo) ModTime() time.Time         { return i.modTime }
+func (i *catalogFileInfo) IsDir() bool                { return i.dir }
+func (i *catalogFileInfo) Sys() any                   { return nil }
+func (i *catalogFileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i *catalogFileInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+func (i *catalogFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
@@ -0,0 +1,201 @@
+package s3_manager
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Подставляет в паттерн каталога значения из storagePath: %d — EntityKey или EntityID, {name} — Params["name"] (например, "orgs/{org_id}/users/{user_id}/").
+// Для PathCustomCatalog возвращает CustomPath в виде "path/to/catalog/". Корневой каталог не добавляется.
+func expandCatalogPattern(pathPattern string, storagePath StoragePath) (string, error) {
+	if storagePath.CatalogType == PathCustomCatalog {
+		return normalizeCatalog(storagePath.CustomPath), nil
+	}
+
+	var builder strings.Builder
+	rest := pathPattern
+	for {
+		start := strings.IndexAny(rest, "%{")
+		if start < 0 {
+			builder.WriteString(rest)
+			break
+		}
+		builder.WriteString(rest[:start])
+		rest = rest[start:]
+
+		if rest[0] == '%' {
+			if !strings.HasPrefix(rest, "%d") {
+				return "", fmt.Errorf("%w: pattern %q may contain only %%d and {name} placeholders", ErrInvalidInput, pathPattern)
+			}
+			entityID := strconv.FormatInt(storagePath.EntityID, 10)
+			if storagePath.EntityKey != "" {
+				if err := validatePathSegment(storagePath.EntityKey); err != nil {
+					return "", fmt.Errorf("%w: entity key: %w", ErrInvalidInput, err)
+				}
+				entityID = storagePath.EntityKey
+			}
+			builder.WriteString(entityID)
+			rest = rest[len("%d"):]
+			continue
+		}
+
+		end := strings.IndexByte(rest, '}')
+		if end < 0 {
+			return "", fmt.Errorf("%w: pattern %q has an unclosed placeholder", ErrInvalidInput, pathPattern)
+		}
+		name := rest[1:end]
+		value, ok := storagePath.Params[name]
+		if !ok {
+			return "", fmt.Errorf("%w: parameter %q of pattern %q is not set in StoragePath.Params", ErrInvalidInput, name, pathPattern)
+		}
+		segment := fmt.Sprint(value)
+		if err := validatePathSegment(segment); err != nil {
+			return "", fmt.Errorf("%w: parameter %q: %w", ErrInvalidInput, name, err)
+		}
+		builder.WriteString(segment)
+		rest = rest[end+1:]
+	}
+
+	return builder.String(), nil
+}
+
+// Возвращает имена именованных параметров паттерна в порядке следования. Возвращает ошибку без ErrInvalidInput (его добавляет вызывающий код), если фигурные скобки не закрыты,
+// имя параметра пустое или содержит недопустимые символы (допустимы латинские буквы, цифры и "_"), или параметр повторяется.
+func patternParams(pathPattern string) ([]string, error) {
+	var names []string
+	rest := pathPattern
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			break
+		}
+		if strings.Contains(rest[:start], "}") {
+			return nil, fmt.Errorf("pattern %q has an unmatched \"}\"", pathPattern)
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("pattern %q has an unclosed placeholder", pathPattern)
+		}
+		name := rest[start+1 : start+end]
+		if !isParamName(name) {
+			return nil, fmt.Errorf("pattern %q has an invalid placeholder name %q", pathPattern, name)
+		}
+		for _, existing := range names {
+			if existing == name {
+				return nil, fmt.Errorf("pattern %q repeats placeholder {%s}", pathPattern, name)
+			}
+		}
+		names = append(names, name)
+		rest = rest[start+end+1:]
+	}
+	if strings.Contains(rest, "}") {
+		return nil, fmt.Errorf("pattern %q has an unmatched \"}\"", pathPattern)
+	}
+
+	return names, nil
+}
+
+// Возвращает постоянную часть паттерна до первого параметра (например, "products/" для "products/%d/certificates/")
+func patternPrefix(pathPattern string) string {
+	if index := strings.IndexAny(pathPattern, "%{"); index >= 0 {
+		return pathPattern[:index]
+	}
+
+	return pathPattern
+}
+
+// Проверяет, что значение можно подставить в путь как один каталог: оно не пустое, не "." и не "..",
+// не содержит "/", "\" и управляющих символов. Иначе строковый идентификатор из запроса мог бы вывести файл за пределы каталога сущности.
+func validatePathSegment(segment string) error {
+	if segment == "" || segment == "." || segment == ".." {
+		return fmt.Errorf("path segment %q is empty or relative", segment)
+	}
+	if strings.ContainsAny(segment, "/\\") || strings.ContainsFunc(segment, unicode.IsControl) {
+		return fmt.Errorf("path segment %q contains a path separator or control characters", segment)
+	}
+
+	return nil
+}
+
+// Проверяет, что имя параметра состоит из латинских букв, цифр и "_"
+func isParamName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Сопоставляет путь относительно корневого каталога с паттерном каталога. Возвращает StoragePath с подставленными в паттерн значениями
+// (%d — EntityID или EntityKey, если значение не число в каноническом виде; {name} — Params) и оставшуюся часть пути (имя файла).
+// Паттерны с параметрами, между которыми нет постоянной части, не сопоставляются.
+func matchCatalogPattern(catalogType CatalogType, pathPattern, relativeKey string) (StoragePath, string, bool) {
+	var expr strings.Builder
+	var names []string // Имена параметров по порядку групп; пустое имя — %d
+	expr.WriteString("^")
+	rest := pathPattern
+	for {
+		start := strings.IndexAny(rest, "%{")
+		if start < 0 {
+			expr.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+		expr.WriteString(regexp.QuoteMeta(rest[:start]))
+		rest = rest[start:]
+
+		if strings.HasPrefix(rest, "%d") {
+			names = append(names, "")
+			rest = rest[len("%d"):]
+		} else {
+			end := strings.IndexByte(rest, '}')
+			if rest[0] != '{' || end < 0 {
+				return StoragePath{}, "", false
+			}
+			names = append(names, rest[1:end])
+			rest = rest[end+1:]
+		}
+		expr.WriteString("([^/]+)")
+		// Значения соседних параметров (например, "{year}{month}") нельзя однозначно разделить
+		if strings.HasPrefix(rest, "%d") || strings.HasPrefix(rest, "{") {
+			return StoragePath{}, "", false
+		}
+	}
+	expr.WriteString("(.+)$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return StoragePath{}, "", false
+	}
+	match := re.FindStringSubmatch(relativeKey)
+	if match == nil {
+		return StoragePath{}, "", false
+	}
+
+	storagePath := StoragePath{CatalogType: catalogType}
+	for i, name := range names {
+		value := match[i+1]
+		if name != "" {
+			if storagePath.Params == nil {
+				storagePath.Params = make(map[string]any, len(names))
+			}
+			storagePath.Params[name] = value
+			continue
+		}
+		// Значения вида "007" или "+5" остаются ключом: как EntityID они превратились бы в путь к другому каталогу
+		if id, err := strconv.ParseInt(value, 10, 64); err == nil && strconv.FormatInt(id, 10) == value {
+			storagePath.EntityID = id
+		} else {
+			storagePath.EntityKey = value
+		}
+	}
+
+	return storagePath, match[len(match)-1], true
+}
@@ -0,0 +1,151 @@
+package s3_manager
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestExpandCatalogPattern(t *testing.T) {
+	tests := []struct {
+		name        string
+		pathPattern string
+		storagePath StoragePath
+		want        string
+		wantErr     bool
+	}{
+		{"entity ID", "products/%d/certificates/", StoragePath{EntityID: 5}, "products/5/certificates/", false},
+		{"named parameters", "orgs/{org_id}/users/{user_id}/", StoragePath{Params: map[string]any{"org_id": 1, "user_id": "u2"}}, "orgs/1/users/u2/", false},
+		{"entity ID and parameter", "docs/%d/{lang}/", StoragePath{EntityID: 7, Params: map[string]any{"lang": "en"}}, "docs/7/en/", false},
+		{"adjacent placeholders", "reports/{year}{month}/", StoragePath{Params: map[string]any{"year": 2024, "month": "01"}}, "reports/202401/", false},
+		{"entity ID next to parameter", "docs/%d{lang}/", StoragePath{EntityID: 7, Params: map[string]any{"lang": "en"}}, "docs/7en/", false},
+		{"no placeholders", "banners/", StoragePath{}, "banners/", false},
+		{"unsupported verb", "products/%s/", StoragePath{EntityID: 5}, "", true},
+		{"percent at the end", "products/%", StoragePath{EntityID: 5}, "", true},
+		{"unclosed placeholder", "orgs/{org_id/", StoragePath{Params: map[string]any{"org_id": 1}}, "", true},
+		{"missing parameter", "orgs/{org_id}/", StoragePath{}, "", true},
+		{"parameter with separator", "orgs/{org_id}/", StoragePath{Params: map[string]any{"org_id": "1/2"}}, "", true},
+		{"relative parameter", "orgs/{org_id}/", StoragePath{Params: map[string]any{"org_id": ".."}}, "", true},
+		{"empty parameter", "orgs/{org_id}/", StoragePath{Params: map[string]any{"org_id": ""}}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandCatalogPattern(tt.pathPattern, tt.storagePath)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("expandCatalogPattern error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandCatalogPattern: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expandCatalogPattern = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatternParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		pathPattern string
+		want        []string
+		wantErr     bool
+	}{
+		{"no parameters", "products/%d/", nil, false},
+		{"parameters in order", "orgs/{org_id}/users/{user_id}/", []string{"org_id", "user_id"}, false},
+		{"adjacent parameters", "reports/{year}{month}/", []string{"year", "month"}, false},
+		{"unclosed", "orgs/{org_id/", nil, true},
+		{"unmatched closing brace", "orgs/org_id}/", nil, true},
+		{"closing brace before parameter", "orgs/}{org_id}/", nil, true},
+		{"nested braces", "orgs/{{org_id}}/", nil, true},
+		{"empty name", "orgs/{}/", nil, true},
+		{"invalid name", "orgs/{org-id}/", nil, true},
+		{"repeated name", "orgs/{id}/users/{id}/", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patternParams(tt.pathPattern)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("patternParams error = %v, want error: %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("patternParams = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpandCatalogPatternEntityKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		entityKey string
+		want      string
+		wantErr   bool
+	}{
+		{"UUID", "3f2b8c1e-9d4a-4e6b-8f1c-2a7d5e9b0c4d", "products/3f2b8c1e-9d4a-4e6b-8f1c-2a7d5e9b0c4d/", false},
+		{"empty key uses entity ID", "", "products/5/", false},
+		{"parent directory", "..", "", true},
+		{"current directory", ".", "", true},
+		{"slash", "a/b", "", true},
+		{"backslash", `a\b`, "", true},
+		{"control character", "a\nb", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandCatalogPattern("products/%d/", StoragePath{EntityID: 5, EntityKey: tt.entityKey})
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("expandCatalogPattern error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expandCatalogPattern: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expandCatalogPattern = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchCatalogPatternEntityKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		relativeKey string
+		want        StoragePath
+	}{
+		{"numeric ID", "products/42/photo.jpg", StoragePath{CatalogType: "product", EntityID: 42}},
+		{"UUID", "products/3f2b8c1e-9d4a-4e6b-8f1c-2a7d5e9b0c4d/photo.jpg", StoragePath{CatalogType: "product", EntityKey: "3f2b8c1e-9d4a-4e6b-8f1c-2a7d5e9b0c4d"}},
+		{"ID out of int64 range", "products/99999999999999999999/photo.jpg", StoragePath{CatalogType: "product", EntityKey: "99999999999999999999"}},
+		{"negative ID", "products/-1/photo.jpg", StoragePath{CatalogType: "product", EntityID: -1}},
+		{"leading zeros", "products/007/photo.jpg", StoragePath{CatalogType: "product", EntityKey: "007"}},
+		{"plus sign", "products/+5/photo.jpg", StoragePath{CatalogType: "product", EntityKey: "+5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fileName, ok := matchCatalogPattern("product", "products/%d/", tt.relativeKey)
+			if !ok {
+				t.Fatalf("matchCatalogPattern(%q) did not match", tt.relativeKey)
+			}
+			if got.CatalogType != tt.want.CatalogType || got.EntityID != tt.want.EntityID || got.EntityKey != tt.want.EntityKey {
+				t.Errorf("matchCatalogPattern = %+v, want %+v", got, tt.want)
+			}
+			if fileName != "photo.jpg" {
+				t.Errorf("file name = %q, want %q", fileName, "photo.jpg")
+			}
+
+			// Разобранный путь должен указывать на тот же каталог
+			catalogPath, err := expandCatalogPattern("products/%d/", got)
+			if err != nil {
+				t.Fatalf("expandCatalogPattern: %v", err)
+			}
+			if catalogPath+fileName != tt.relativeKey {
+				t.Errorf("expanded key = %q, want %q", catalogPath+fileName, tt.relativeKey)
+			}
+		})
+	}
+}
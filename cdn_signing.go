@@ -0,0 +1,142 @@
+package s3_manager
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Подписывает ссылки на файлы в CDN, чтобы приватные файлы можно было раздавать через CDN, а не напрямую из S3
+type URLSigner interface {
+	SignURL(rawURL string, expiresAt time.Time) (string, error)
+}
+
+// Подпись ссылок CloudFront (canned policy) ключом из доверенной группы ключей (key group) или ключевой пары аккаунта
+type CloudFrontSigner struct {
+	KeyPairID  string          // Идентификатор публичного ключа в CloudFront
+	PrivateKey *rsa.PrivateKey // Приватный ключ, соответствующий публичному ключу
+}
+
+// Создаёт подпись ссылок CloudFront
+func NewCloudFrontSigner(keyPairID string, privateKey *rsa.PrivateKey) *CloudFrontSigner {
+	return &CloudFrontSigner{KeyPairID: keyPairID, PrivateKey: privateKey}
+}
+
+// Добавляет к ссылке параметры Expires, Signature и Key-Pair-Id
+func (s *CloudFrontSigner) SignURL(rawURL string, expiresAt time.Time) (string, error) {
+	if s.KeyPairID == "" || s.PrivateKey == nil {
+		return "", fmt.Errorf("CloudFrontSigner.SignURL: %w: key pair ID or private key is empty", ErrInvalidInput)
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	policy := `{"Statement":[{"Resource":"` + rawURL + `","Condition":{"DateLessThan":{"AWS:EpochTime":` + expires + `}}}]}`
+
+	hash := sha1.Sum([]byte(policy))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, crypto.SHA1, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("CloudFrontSigner.SignURL/SignPKCS1v15: %w", err)
+	}
+
+	// CloudFront использует base64 с заменой символов, недопустимых в URL: "+" -> "-", "=" -> "_", "/" -> "~"
+	encodedSignature := strings.NewReplacer("+", "-", "=", "_", "/", "~").Replace(base64.StdEncoding.EncodeToString(signature))
+
+	return addQueryParams(rawURL, url.Values{
+		"Expires":     {expires},
+		"Signature":   {encodedSignature},
+		"Key-Pair-Id": {s.KeyPairID},
+	}), nil
+}
+
+// Подпись ссылок токеном HMAC-SHA256 от пути и времени истечения ссылки. Подходит для CDN с проверкой токена на edge-сервере
+// (например, через правила или edge-функции): токен равен hex(HMAC-SHA256(Secret, путь + время истечения в Unix-секундах)).
+type TokenSigner struct {
+	Secret         []byte // Общий секрет CDN и сервиса
+	ExpiresParam   string // Имя параметра со временем истечения ссылки. По умолчанию "expires".
+	SignatureParam string // Имя параметра с токеном. По умолчанию "token".
+}
+
+// Создаёт подпись ссылок токеном с параметрами по умолчанию
+func NewTokenSigner(secret []byte) *TokenSigner {
+	return &TokenSigner{Secret: secret}
+}
+
+// Добавляет к ссылке параметры со временем истечения и токеном
+func (s *TokenSigner) SignURL(rawURL string, expiresAt time.Time) (string, error) {
+	if len(s.Secret) == 0 {
+		return "", fmt.Errorf("TokenSigner.SignURL: %w: secret is empty", ErrInvalidInput)
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("TokenSigner.SignURL/Parse: %w", err)
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write([]byte(parsedURL.EscapedPath() + expires))
+
+	expiresParam, signatureParam := s.ExpiresParam, s.SignatureParam
+	if expiresParam == "" {
+		expiresParam = "expires"
+	}
+	if signatureParam == "" {
+		signatureParam = "token"
+	}
+
+	return addQueryParams(rawURL, url.Values{
+		expiresParam:   {expires},
+		signatureParam: {hex.EncodeToString(mac.Sum(nil))},
+	}), nil
+}
+
+// Метод для получения подписанной ссылки на файл в CDN (см. Config.CDN и Config.CDNSigner). Время жизни ссылки по умолчанию — Config.PresignedURLExpireTime,
+// оно ограничивается Config.MaxPresignedURLExpireTime.
+func (r *s3Manager) GetSignedCDNURL(storagePath StoragePath, fileName string, expireTime time.Duration, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if r.cfg.CDN == "" || r.cfg.CDNSigner == nil {
+		return "", fmt.Errorf("GetSignedCDNURL: %w: CDN or CDN signer is not configured", ErrNotSupported)
+	}
+
+	if expireTime == 0 {
+		expireTime = r.cfg.PresignedURLExpireTime
+	}
+	if expireTime <= 0 {
+		return "", fmt.Errorf("GetSignedCDNURL: %w: expire time is not set", ErrInvalidInput)
+	}
+	if r.cfg.MaxPresignedURLExpireTime > 0 {
+		expireTime = min(expireTime, r.cfg.MaxPresignedURLExpireTime)
+	}
+
+	fileURL, err := r.GetObjectURL(storagePath, fileName, opts...)
+	if err != nil {
+		return "", fmt.Errorf("GetSignedCDNURL/GetObjectURL: %w", err)
+	}
+
+	signedURL, err := r.cfg.CDNSigner.SignURL(fileURL, time.Now().Add(expireTime))
+	if err != nil {
+		return "", fmt.Errorf("GetSignedCDNURL/SignURL: %w", err)
+	}
+
+	return signedURL, nil
+}
+
+// Добавляет параметры к query-строке ссылки, сохраняя существующие параметры
+func addQueryParams(rawURL string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+
+	return rawURL + separator + params.Encode()
+}
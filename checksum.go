@@ -0,0 +1,213 @@
+package s3_manager
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// Контрольная сумма содержимого объекта в формате S3 (значение в base64)
+type checksumValue struct {
+	algorithm types.ChecksumAlgorithm
+	value     string
+}
+
+// Устанавливает контрольную сумму в запросе на загрузку объекта
+func (c checksumValue) applyToPut(input *s3.PutObjectInput) error {
+	if c.value == "" {
+		return nil
+	}
+
+	field, err := checksumField(c.algorithm, &input.ChecksumCRC32, &input.ChecksumCRC32C, &input.ChecksumCRC64NVME, &input.ChecksumSHA1, &input.ChecksumSHA256)
+	if err != nil {
+		return err
+	}
+	*field = &c.value
+
+	return nil
+}
+
+// Устанавливает контрольную сумму в запросе на загрузку части объекта
+func (c checksumValue) applyToUploadPart(input *s3.UploadPartInput) error {
+	if c.value == "" {
+		return nil
+	}
+
+	field, err := checksumField(c.algorithm, &input.ChecksumCRC32, &input.ChecksumCRC32C, &input.ChecksumCRC64NVME, &input.ChecksumSHA1, &input.ChecksumSHA256)
+	if err != nil {
+		return err
+	}
+	*field = &c.value
+
+	return nil
+}
+
+// Сравнивает контрольную сумму с контрольной суммой, которую вернуло хранилище. Если хранилище её не вернуло (так делают не все S3-совместимые хранилища), проверка пропускается.
+func (c checksumValue) verify(actual *string) error {
+	if actual == nil || *actual == "" || *actual == c.value {
+		return nil
+	}
+	return fmt.Errorf("%w: %s expected %s, got %s", ErrChecksumMismatch, c.algorithm, c.value, *actual)
+}
+
+// Возвращает поле контрольной суммы для указанного алгоритма из набора полей запроса или ответа S3
+func checksumField(algorithm types.ChecksumAlgorithm, crc32Field, crc32cField, crc64nvmeField, sha1Field, sha256Field **string) (**string, error) {
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		return crc32Field, nil
+	case types.ChecksumAlgorithmCrc32c:
+		return crc32cField, nil
+	case types.ChecksumAlgorithmCrc64nvme:
+		return crc64nvmeField, nil
+	case types.ChecksumAlgorithmSha1:
+		return sha1Field, nil
+	case types.ChecksumAlgorithmSha256:
+		return sha256Field, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported checksum algorithm %q", ErrInvalidInput, algorithm)
+	}
+}
+
+// Создаёт хеш-функцию для алгоритма контрольной суммы S3
+func newChecksumHash(algorithm types.ChecksumAlgorithm) (hash.Hash, error) {
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		return crc32.NewIEEE(), nil
+	case types.ChecksumAlgorithmCrc32c:
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli)), nil
+	case types.ChecksumAlgorithmCrc64nvme:
+		return crc64.New(crc64.MakeTable(0x9a6c9329ac4bc9b5)), nil // Полином CRC-64/NVME в обратной записи
+	case types.ChecksumAlgorithmSha1:
+		return sha1.New(), nil
+	case types.ChecksumAlgorithmSha256:
+		return sha256.New(), nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported checksum algorithm %q", ErrInvalidInput, algorithm)
+	}
+}
+
+// Вычисляет контрольную сумму данных
+func computeChecksum(algorithm types.ChecksumAlgorithm, data []byte) (checksumValue, error) {
+	h, err := newChecksumHash(algorithm)
+	if err != nil {
+		return checksumValue{}, err
+	}
+	h.Write(data)
+
+	return checksumValue{algorithm: algorithm, value: base64.StdEncoding.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Вычисляет контрольную сумму оставшихся данных потока и возвращает поток на исходную позицию
+func computeReaderChecksum(algorithm types.ChecksumAlgorithm, reader io.ReadSeeker) (checksumValue, error) {
+	h, err := newChecksumHash(algorithm)
+	if err != nil {
+		return checksumValue{}, err
+	}
+
+	current, err := reader.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return checksumValue{}, fmt.Errorf("computeReaderChecksum/Seek: %w", err)
+	}
+	_, err = io.Copy(h, reader)
+	if err != nil {
+		return checksumValue{}, fmt.Errorf("computeReaderChecksum/Copy: %w", err)
+	}
+	_, err = reader.Seek(current, io.SeekStart)
+	if err != nil {
+		return checksumValue{}, fmt.Errorf("computeReaderChecksum/Seek: %w", err)
+	}
+
+	return checksumValue{algorithm: algorithm, value: base64.StdEncoding.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Поток содержимого скачиваемого объекта, который вычисляет контрольную сумму при чтении и сверяет её с ожидаемой, когда поток прочитан до конца.
+// При расхождении чтение завершается ошибкой ErrChecksumMismatch вместо io.EOF.
+type checksumReader struct {
+	io.ReadCloser
+	hash      hash.Hash
+	algorithm string              // Название алгоритма для сообщения об ошибке
+	expected  string              // Ожидаемая контрольная сумма в кодировке encode
+	encode    func([]byte) string // Кодирование вычисленной контрольной суммы (base64 для контрольных сумм S3, hex для ETag)
+	err       error               // Результат проверки после чтения всего потока
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	n, err := c.ReadCloser.Read(p)
+	c.hash.Write(p[:n])
+	if err == io.EOF {
+		c.err = io.EOF
+		if actual := c.encode(c.hash.Sum(nil)); actual != c.expected {
+			c.err = fmt.Errorf("%w: %s expected %s, got %s", ErrChecksumMismatch, c.algorithm, c.expected, actual)
+		}
+		return n, c.err
+	}
+
+	return n, err
+}
+
+// Оборачивает тело ответа GetObject в поток с проверкой контрольной суммы. Проверяется контрольная сумма всего объекта из ответа S3,
+// а если её нет — ETag, когда он равен MD5 содержимого (объект загружен одним запросом и не зашифрован SSE-KMS или SSE-C).
+// Если проверить нечем (например, у multipart-объекта без контрольной суммы), тело возвращается без проверки.
+func newChecksumReader(body io.ReadCloser, output *s3.GetObjectOutput) io.ReadCloser {
+	if output.ChecksumType != types.ChecksumTypeComposite {
+		for _, algorithm := range types.ChecksumAlgorithm("").Values() {
+			field, err := checksumField(algorithm, &output.ChecksumCRC32, &output.ChecksumCRC32C, &output.ChecksumCRC64NVME, &output.ChecksumSHA1, &output.ChecksumSHA256)
+			if err != nil {
+				continue
+			}
+			expected := aws.ToString(*field)
+			if expected == "" || strings.Contains(expected, "-") { // Контрольная сумма из контрольных сумм частей вида "<значение>-<количество частей>"
+				continue
+			}
+
+			h, err := newChecksumHash(algorithm)
+			if err != nil {
+				continue
+			}
+			return &checksumReader{ReadCloser: body, hash: h, algorithm: string(algorithm), expected: expected, encode: base64.StdEncoding.EncodeToString}
+		}
+	}
+
+	encrypted := output.ServerSideEncryption == types.ServerSideEncryptionAwsKms || output.ServerSideEncryption == types.ServerSideEncryptionAwsKmsDsse || output.SSECustomerAlgorithm != nil
+	if etag, ok := md5ETag(aws.ToString(output.ETag)); ok && !encrypted {
+		return &checksumReader{ReadCloser: body, hash: md5.New(), algorithm: "MD5", expected: etag, encode: hex.EncodeToString}
+	}
+
+	return body
+}
+
+// Возвращает ETag без кавычек в нижнем регистре, если он может быть MD5 содержимого объекта (у multipart-объектов ETag вида "<hex>-<количество частей>").
+// ETag объектов с SSE-KMS и SSE-C тоже имеет вид MD5, но им не является — это нужно проверять отдельно.
+func md5ETag(etag string) (string, bool) {
+	etag = strings.ToLower(strings.Trim(etag, `"`))
+	if _, err := hex.DecodeString(etag); err != nil || len(etag) != 2*md5.Size {
+		return "", false
+	}
+	return etag, true
+}
+
+// Отключает встроенную в SDK проверку контрольной суммы ответа: при WithChecksumValidation её выполняет checksumReader,
+// чтобы расхождение возвращалось как ErrChecksumMismatch, а не как внутренняя ошибка SDK
+func withoutSDKResponseValidation(options *s3.Options) {
+	options.APIOptions = append(options.APIOptions, func(stack *middleware.Stack) error {
+		_, _ = stack.Deserialize.Remove("AWSChecksum:ValidateOutputPayloadChecksum") // Middleware может отсутствовать, если проверка отключена в клиенте
+		return nil
+	})
+}
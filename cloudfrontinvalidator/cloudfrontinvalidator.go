@@ -0,0 +1,57 @@
+// Пакет со сбросом кеша CloudFront для менеджера (s3_manager.Invalidator). Вынесен из основного пакета, чтобы SDK CloudFront подключался
+// только в сервисах, которые раздают файлы через CloudFront.
+package cloudfrontinvalidator
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	cloudfronttypes "github.com/aws/aws-sdk-go-v2/service/cloudfront/types"
+
+	s3_manager "s3-manager"
+)
+
+// Подмножество методов *cloudfront.Client, необходимое для сброса кеша CloudFront
+type API interface {
+	CreateInvalidation(ctx context.Context, params *cloudfront.CreateInvalidationInput, optFns ...func(*cloudfront.Options)) (*cloudfront.CreateInvalidationOutput, error)
+}
+
+// Сброс кеша дистрибуции CloudFront
+type Invalidator struct {
+	Client         API    // Клиент CloudFront (например, cloudfront.NewFromConfig(awsCfg))
+	DistributionID string // Идентификатор дистрибуции CloudFront
+}
+
+var _ s3_manager.Invalidator = (*Invalidator)(nil)
+
+// Создаёт сброс кеша дистрибуции CloudFront
+func New(client API, distributionID string) *Invalidator {
+	return &Invalidator{Client: client, DistributionID: distributionID}
+}
+
+// Создаёт запрос на сброс кеша CloudFront для переданных путей. Запрос выполняется асинхронно на стороне CloudFront.
+func (i *Invalidator) Invalidate(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	_, err := i.Client.CreateInvalidation(ctx, &cloudfront.CreateInvalidationInput{
+		DistributionId: &i.DistributionID,
+		InvalidationBatch: &cloudfronttypes.InvalidationBatch{
+			CallerReference: aws.String(strconv.FormatInt(time.Now().UnixNano(), 10)), // Уникальная строка для защиты от повторной отправки запроса
+			Paths: &cloudfronttypes.Paths{
+				Items:    paths,
+				Quantity: aws.Int32(int32(len(paths))),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Invalidator.Invalidate/CreateInvalidation: %w", err)
+	}
+
+	return nil
+}
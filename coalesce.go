@@ -0,0 +1,185 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Хранилище, которое объединяет одинаковые одновременные запросы на чтение списка объектов и информации об объекте (GetFiles, ListObjects,
+// StatFile, FileExists) в один запрос к хранилищу: например, когда много пользователей одновременно открывают одну галерею.
+// Ответ общий для всех объединённых запросов. Изменяющие запросы через менеджер сбрасывают объединение, поэтому запрос, начатый после
+// загрузки или удаления файла, не получит ответ, полученный до неё. Подключается автоматически, если в конфиге не включён DisableCoalescing.
+type coalescingBackend struct {
+	next  StorageBackend
+	heads flightGroup[*s3.HeadObjectOutput]
+	lists flightGroup[*s3.ListObjectsV2Output]
+}
+
+var _ StorageBackend = (*coalescingBackend)(nil)
+
+// Возвращает хранилище, вокруг которого построена обёртка
+func (b *coalescingBackend) Unwrap() StorageBackend {
+	return b.next
+}
+
+func (b *coalescingBackend) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return b.heads.do(ctx, headFlightKey(params), func() (*s3.HeadObjectOutput, error) {
+		return b.next.HeadObject(ctx, params, optFns...)
+	})
+}
+
+// Опции клиента S3 берутся из первого из объединённых запросов: менеджер передаёт в списке объектов только опции пагинатора,
+// которые не влияют на ответ
+func (b *coalescingBackend) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return b.lists.do(ctx, listFlightKey(params), func() (*s3.ListObjectsV2Output, error) {
+		return b.next.ListObjectsV2(ctx, params, optFns...)
+	})
+}
+
+func (b *coalescingBackend) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return b.next.GetObject(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	defer b.forget()
+	return b.next.PutObject(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	defer b.forget()
+	return b.next.DeleteObject(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	defer b.forget()
+	return b.next.DeleteObjects(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	defer b.forget()
+	return b.next.CopyObject(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return b.next.CreateMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return b.next.UploadPart(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	return b.next.UploadPartCopy(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	defer b.forget()
+	return b.next.CompleteMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *coalescingBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return b.next.AbortMultipartUpload(ctx, params, optFns...)
+}
+
+// Сбрасывает объединение запросов после изменения бакета: следующие запросы выполняются заново
+func (b *coalescingBackend) forget() {
+	b.heads.forget()
+	b.lists.forget()
+}
+
+// Ключ объединения запроса HeadObject: все параметры, которые влияют на ответ
+func headFlightKey(params *s3.HeadObjectInput) string {
+	return fmt.Sprintf("%q %q %q %d %q %q %q %v %v %q %q %q %q %q",
+		aws.ToString(params.Bucket), aws.ToString(params.Key), aws.ToString(params.VersionId), aws.ToInt32(params.PartNumber),
+		aws.ToString(params.Range), aws.ToString(params.IfMatch), aws.ToString(params.IfNoneMatch),
+		aws.ToTime(params.IfModifiedSince).UnixNano(), aws.ToTime(params.IfUnmodifiedSince).UnixNano(),
+		params.ChecksumMode, aws.ToString(params.SSECustomerAlgorithm), aws.ToString(params.SSECustomerKey),
+		aws.ToString(params.ExpectedBucketOwner), params.RequestPayer,
+	)
+}
+
+// Ключ объединения запроса ListObjectsV2: все параметры, которые влияют на ответ
+func listFlightKey(params *s3.ListObjectsV2Input) string {
+	return fmt.Sprintf("%q %q %q %q %q %d %q %v %v %q %q",
+		aws.ToString(params.Bucket), aws.ToString(params.Prefix), aws.ToString(params.Delimiter),
+		aws.ToString(params.ContinuationToken), aws.ToString(params.StartAfter), aws.ToInt32(params.MaxKeys),
+		params.EncodingType, aws.ToBool(params.FetchOwner), params.OptionalObjectAttributes,
+		aws.ToString(params.ExpectedBucketOwner), params.RequestPayer,
+	)
+}
+
+// Группа одинаковых одновременных вызовов: вызов с ключом, который уже выполняется, ждёт его результата вместо повторного выполнения
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+// Выполняющийся вызов и его результат (заполняется до закрытия done)
+type flightCall[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+// Выполняет fn или ждёт результата уже выполняющегося вызова с тем же ключом. Ожидание прерывается при отмене ctx.
+// Если вызов прервался из-за отмены контекста того, кто его начал, ожидавшие с активным контекстом выполняют его заново.
+func (g *flightGroup[T]) do(ctx context.Context, key string, fn func() (T, error)) (T, error) {
+	for {
+		g.mu.Lock()
+		if call, ok := g.calls[key]; ok {
+			g.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				var zero T
+				return zero, ctx.Err()
+			}
+			if isContextError(call.err) && ctx.Err() == nil {
+				continue
+			}
+			return call.value, call.err
+		}
+
+		call := &flightCall[T]{done: make(chan struct{})}
+		if g.calls == nil {
+			g.calls = make(map[string]*flightCall[T])
+		}
+		g.calls[key] = call
+		g.mu.Unlock()
+
+		g.run(key, call, fn)
+		return call.value, call.err
+	}
+}
+
+// Выполняет вызов и сообщает результат ожидающим, в том числе при панике в fn
+func (g *flightGroup[T]) run(key string, call *flightCall[T], fn func() (T, error)) {
+	defer func() {
+		g.mu.Lock()
+		if g.calls[key] == call { // После forget под ключом может выполняться уже новый вызов
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.err = context.Canceled // Ожидающие выполнят вызов заново, если fn завершится паникой
+	call.value, call.err = fn()
+}
+
+// Отвязывает выполняющиеся вызовы от их ключей: они завершатся для тех, кто уже ждёт, а новые вызовы выполнятся заново
+func (g *flightGroup[T]) forget() {
+	g.mu.Lock()
+	clear(g.calls)
+	g.mu.Unlock()
+}
+
+// Проверяет, вызвана ли ошибка отменой или тайм-аутом контекста
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
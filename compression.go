@@ -0,0 +1,111 @@
+package s3_manager
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Алгоритм сжатия объектов при загрузке. Сжатый объект сохраняется с заголовком Content-Encoding и распаковывается при скачивании
+// через GetFile и DownloadToWriter; браузеры и CDN распаковывают gzip (и zstd в современных браузерах) сами.
+type Compression string
+
+const (
+	CompressionNone Compression = "none" // Не сжимать (отменяет сжатие каталога по умолчанию)
+	CompressionGzip Compression = "gzip"
+	CompressionZstd Compression = "zstd"
+)
+
+// Метод для добавления нового типа каталога с паттерном пути в бакете и сжатием загружаемых в него файлов по умолчанию
+// (например, для выгрузок JSON и CSV). Сжатие отдельного файла можно изменить опцией WithCompression.
+func (r *s3Manager) AddCatalogWithCompression(catalogType CatalogType, pathPattern string, compression Compression) {
+	r.AddCatalog(catalogType, pathPattern)
+
+	r.catalogMu.Lock()
+	defer r.catalogMu.Unlock()
+
+	r.catalogCompression[catalogType] = compression
+}
+
+// Определяет алгоритм сжатия загружаемого файла: из опции WithCompression или сжатие каталога по умолчанию. Возвращает пустую строку, если сжимать не нужно.
+func (r *s3Manager) uploadCompression(storagePath StoragePath, o operationOptions) Compression {
+	compression := o.compression
+	if compression == "" {
+		r.catalogMu.RLock()
+		compression = r.catalogCompression[storagePath.CatalogType]
+		r.catalogMu.RUnlock()
+	}
+	if compression == CompressionNone {
+		return ""
+	}
+
+	return compression
+}
+
+// Возвращает поток со сжатым содержимым body. Сжатие выполняется в отдельной горутине по мере чтения потока;
+// поток необходимо закрыть, чтобы горутина завершилась, даже если он прочитан не до конца.
+func compressReader(body io.Reader, compression Compression) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+
+	var compressor io.WriteCloser
+	switch compression {
+	case CompressionGzip:
+		compressor = gzip.NewWriter(pw)
+	case CompressionZstd:
+		encoder, err := zstd.NewWriter(pw)
+		if err != nil {
+			return nil, fmt.Errorf("compressReader/NewWriter: %w", err)
+		}
+		compressor = encoder
+	default:
+		return nil, fmt.Errorf("compressReader: %w: unknown compression %q", ErrInvalidInput, compression)
+	}
+
+	go func() {
+		_, err := io.Copy(compressor, body)
+		if closeErr := compressor.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err) // При err == nil читающая сторона получит io.EOF
+	}()
+
+	return pr, nil
+}
+
+// Возвращает поток с распакованным содержимым объекта, сжатого с Content-Encoding encoding. Содержимое с другим Content-Encoding возвращается как есть.
+func decompressReader(body io.ReadCloser, encoding string) (io.ReadCloser, error) {
+	switch Compression(encoding) {
+	case CompressionGzip:
+		decompressor, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decompressReader/NewReader: %w", err)
+		}
+		return &decompressedReader{Reader: decompressor, closers: []io.Closer{decompressor, body}}, nil
+	case CompressionZstd:
+		decoder, err := zstd.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("decompressReader/NewReader: %w", err)
+		}
+		return &decompressedReader{Reader: decoder, closers: []io.Closer{decoder.IOReadCloser(), body}}, nil
+	default:
+		return body, nil
+	}
+}
+
+// Поток с распакованным содержимым, который при закрытии закрывает и распаковщик, и исходный поток
+type decompressedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (d *decompressedReader) Close() error {
+	var err error
+	for _, closer := range d.closers {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
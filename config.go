@@ -0,0 +1,78 @@
+package s3_manager
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Проверяет конфиг и приводит RootCatalog к виду "path/to/catalog/" (без "/" в начале и с "/" в конце).
+// Адрес хранилища, регион и учётные данные проверяются только при checkConnection — когда менеджер сам создаёт клиент S3.
+// Возвращает ошибку ErrInvalidConfig со списком всех найденных проблем.
+func (c *Config) validate(checkConnection bool) error {
+	var problems []string
+
+	if c.Name == "" {
+		problems = append(problems, "bucket name is empty")
+	}
+	if c.Endpoint != "" {
+		endpoint, err := url.Parse(c.Endpoint)
+		switch {
+		case err != nil:
+			problems = append(problems, fmt.Sprintf("invalid endpoint: %v", err))
+		case endpoint.Scheme != "http" && endpoint.Scheme != "https":
+			problems = append(problems, fmt.Sprintf("endpoint %q must start with http:// or https://", c.Endpoint))
+		case endpoint.Host == "":
+			problems = append(problems, fmt.Sprintf("endpoint %q has no host", c.Endpoint))
+		}
+	}
+	if checkConnection {
+		if c.Endpoint == "" && c.Region == "" {
+			problems = append(problems, "either endpoint or region must be set")
+		}
+		if c.Credentials == nil && !c.UseDefaultCredentials && (c.AccessKey == "" || c.SecretKey == "") {
+			problems = append(problems, "access key and secret key are required unless Credentials or UseDefaultCredentials is set")
+		}
+	}
+
+	switch c.AddressingStyle {
+	case AddressingStyleDefault, AddressingStylePath, AddressingStyleVirtualHosted:
+	default:
+		problems = append(problems, fmt.Sprintf("unknown addressing style %q", c.AddressingStyle))
+	}
+	switch c.NamingStrategy {
+	case "", NamingUUID, NamingTimestampHash, NamingContentHash:
+	default:
+		problems = append(problems, fmt.Sprintf("unknown naming strategy %q", c.NamingStrategy))
+	}
+	if c.MultipartPartSize != 0 && c.MultipartPartSize < minMultipartPartSize {
+		problems = append(problems, fmt.Sprintf("multipart part size must be at least %d bytes", minMultipartPartSize))
+	}
+	if c.MultipartThreshold < 0 || c.MultipartConcurrency < 0 || c.MaxConcurrency < 0 || c.MaxUploadSize < 0 {
+		problems = append(problems, "multipart and concurrency limits must not be negative")
+	}
+	if c.PresignedURLExpireTime < 0 || c.MaxPresignedURLExpireTime < 0 {
+		problems = append(problems, "presigned URL expire time must not be negative")
+	}
+	if err := c.Encryption.validate(); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid encryption: %v", err))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+	}
+
+	c.RootCatalog = normalizeCatalog(c.RootCatalog)
+
+	return nil
+}
+
+// Приводит путь каталога к виду "path/to/catalog/": без "/" в начале и с одним "/" в конце. Пустой путь остаётся пустым.
+func normalizeCatalog(catalog string) string {
+	catalog = strings.Trim(catalog, "/")
+	if catalog == "" {
+		return ""
+	}
+
+	return catalog + "/"
+}
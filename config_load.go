@@ -0,0 +1,226 @@
+package s3_manager
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultRegion                 = "us-east-1"      // Регион по умолчанию для конфигов из окружения и файлов (MinIO и большинство S3-совместимых хранилищ его принимают)
+	defaultPresignedURLExpireTime = 15 * time.Minute // Время жизни подписанной ссылки по умолчанию для конфигов из окружения и файлов
+)
+
+// Настройки, которые можно задать переменными окружения или в файле конфига. Имена полей в файле совпадают с тегами json,
+// имена переменных окружения — с тегами в верхнем регистре и префиксом (например, "S3_ACCESS_KEY" для префикса "S3").
+// Длительности задаются строками time.ParseDuration (например, "15m"), размеры — числом байт.
+type fileConfig struct {
+	Endpoint                  string `json:"endpoint" yaml:"endpoint"`
+	Region                    string `json:"region" yaml:"region"`
+	AccessKey                 string `json:"access_key" yaml:"access_key"`
+	SecretKey                 string `json:"secret_key" yaml:"secret_key"`
+	UseDefaultCredentials     bool   `json:"use_default_credentials" yaml:"use_default_credentials"`
+	Bucket                    string `json:"bucket" yaml:"bucket"`
+	RootCatalog               string `json:"root_catalog" yaml:"root_catalog"`
+	CDN                       string `json:"cdn" yaml:"cdn"`
+	PresignedURLExpireTime    string `json:"presigned_url_expire_time" yaml:"presigned_url_expire_time"`
+	MaxPresignedURLExpireTime string `json:"max_presigned_url_expire_time" yaml:"max_presigned_url_expire_time"`
+	DefaultACL                string `json:"default_acl" yaml:"default_acl"`
+	MultipartThreshold        int64  `json:"multipart_threshold" yaml:"multipart_threshold"`
+	MultipartPartSize         int64  `json:"multipart_part_size" yaml:"multipart_part_size"`
+	MultipartConcurrency      int    `json:"multipart_concurrency" yaml:"multipart_concurrency"`
+	DownloadPartSize          int64  `json:"download_part_size" yaml:"download_part_size"`
+	DownloadConcurrency       int    `json:"download_concurrency" yaml:"download_concurrency"`
+	MaxConcurrency            int    `json:"max_concurrency" yaml:"max_concurrency"`
+	MaxUploadSize             int64  `json:"max_upload_size" yaml:"max_upload_size"`
+	SniffContentType          bool   `json:"sniff_content_type" yaml:"sniff_content_type"`
+	NamingStrategy            string `json:"naming_strategy" yaml:"naming_strategy"`
+	ChecksumAlgorithm         string `json:"checksum_algorithm" yaml:"checksum_algorithm"`
+	AddressingStyle           string `json:"addressing_style" yaml:"addressing_style"`
+	LegacyCatalogPaths        bool   `json:"legacy_catalog_paths" yaml:"legacy_catalog_paths"`
+	ReadOnly                  bool   `json:"read_only" yaml:"read_only"`
+	DisableCoalescing         bool   `json:"disable_coalescing" yaml:"disable_coalescing"`
+	CacheTTL                  string `json:"cache_ttl" yaml:"cache_ttl"`
+	CachePresignedURLs        bool   `json:"cache_presigned_urls" yaml:"cache_presigned_urls"`
+	DiskCacheDir              string `json:"disk_cache_dir" yaml:"disk_cache_dir"`
+	DiskCacheSize             int64  `json:"disk_cache_size" yaml:"disk_cache_size"`
+	TrashCatalog              string `json:"trash_catalog" yaml:"trash_catalog"`
+	RetryMaxAttempts          int    `json:"retry_max_attempts" yaml:"retry_max_attempts"`
+	UploadTimeout             string `json:"upload_timeout" yaml:"upload_timeout"`
+	DownloadTimeout           string `json:"download_timeout" yaml:"download_timeout"`
+	ListTimeout               string `json:"list_timeout" yaml:"list_timeout"`
+	DeleteTimeout             string `json:"delete_timeout" yaml:"delete_timeout"`
+	RequestTimeout            string `json:"request_timeout" yaml:"request_timeout"`
+	ProxyURL                  string `json:"proxy_url" yaml:"proxy_url"`
+	CAFile                    string `json:"ca_file" yaml:"ca_file"`
+	InsecureSkipVerify        bool   `json:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+	MaxIdleConnsPerHost       int    `json:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host"`
+	IdleConnTimeout           string `json:"idle_conn_timeout" yaml:"idle_conn_timeout"`
+	DisableHTTP2              bool   `json:"disable_http2" yaml:"disable_http2"`
+}
+
+// Функция для получения конфига из переменных окружения с префиксом prefix (например, для "S3": S3_ENDPOINT, S3_REGION, S3_ACCESS_KEY,
+// S3_SECRET_KEY, S3_BUCKET, S3_ROOT_CATALOG, S3_CDN, S3_PRESIGNED_URL_EXPIRE_TIME, S3_UPLOAD_TIMEOUT, S3_PROXY_URL, S3_CA_FILE и т.д.).
+// Незаданные переменные принимают значения по умолчанию: регион us-east-1, время жизни подписанной ссылки 15 минут.
+// Поля, которые нельзя задать строкой (Logger, CDNSigner, Credentials и т.п.), заполняются в возвращённом конфиге вручную.
+func ConfigFromEnv(prefix string) (*Config, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	var fc fileConfig
+	value := reflect.ValueOf(&fc).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		name, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		envName := prefix + strings.ToUpper(name)
+		envValue, ok := os.LookupEnv(envName)
+		if !ok || envValue == "" {
+			continue
+		}
+
+		field := value.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(envValue)
+		case reflect.Bool:
+			parsed, err := strconv.ParseBool(envValue)
+			if err != nil {
+				return nil, fmt.Errorf("ConfigFromEnv: %w: %s: %w", ErrInvalidConfig, envName, err)
+			}
+			field.SetBool(parsed)
+		case reflect.Int, reflect.Int64:
+			parsed, err := strconv.ParseInt(envValue, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("ConfigFromEnv: %w: %s: %w", ErrInvalidConfig, envName, err)
+			}
+			field.SetInt(parsed)
+		}
+	}
+
+	cfg, err := fc.toConfig()
+	if err != nil {
+		return nil, fmt.Errorf("ConfigFromEnv/toConfig: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Функция для загрузки конфига из файла JSON или YAML (формат определяется по расширению: .json, .yaml или .yml).
+// Имена полей — как у переменных окружения в ConfigFromEnv, но в нижнем регистре и без префикса (например, "access_key").
+// Ссылки на переменные окружения вида ${S3_SECRET_KEY} заменяются их значениями, поэтому секреты можно не хранить в файле.
+// Неизвестные поля считаются ошибкой, чтобы опечатки в именах не оставались незамеченными.
+func LoadConfigFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfigFile/ReadFile: %w", err)
+	}
+	data = []byte(os.ExpandEnv(string(data)))
+
+	var fc fileConfig
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&fc)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&fc)
+	default:
+		return nil, fmt.Errorf("LoadConfigFile: %w: unsupported config file extension %q", ErrInvalidConfig, filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfigFile/Decode: %w: %w", ErrInvalidConfig, err)
+	}
+
+	cfg, err := fc.toConfig()
+	if err != nil {
+		return nil, fmt.Errorf("LoadConfigFile/toConfig: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Преобразует настройки в Config и заполняет значения по умолчанию
+func (fc fileConfig) toConfig() (*Config, error) {
+	cfg := &Config{
+		Endpoint:              fc.Endpoint,
+		Region:                fc.Region,
+		AccessKey:             fc.AccessKey,
+		SecretKey:             fc.SecretKey,
+		UseDefaultCredentials: fc.UseDefaultCredentials,
+		Name:                  fc.Bucket,
+		RootCatalog:           fc.RootCatalog,
+		CDN:                   fc.CDN,
+		DefaultACL:            types.ObjectCannedACL(fc.DefaultACL),
+		MultipartThreshold:    fc.MultipartThreshold,
+		MultipartPartSize:     fc.MultipartPartSize,
+		MultipartConcurrency:  fc.MultipartConcurrency,
+		DownloadPartSize:      fc.DownloadPartSize,
+		DownloadConcurrency:   fc.DownloadConcurrency,
+		MaxConcurrency:        fc.MaxConcurrency,
+		MaxUploadSize:         fc.MaxUploadSize,
+		SniffContentType:      fc.SniffContentType,
+		NamingStrategy:        NamingStrategy(fc.NamingStrategy),
+		ChecksumAlgorithm:     types.ChecksumAlgorithm(fc.ChecksumAlgorithm),
+		AddressingStyle:       AddressingStyle(fc.AddressingStyle),
+		LegacyCatalogPaths:    fc.LegacyCatalogPaths,
+		ReadOnly:              fc.ReadOnly,
+		DisableCoalescing:     fc.DisableCoalescing,
+		CachePresignedURLs:    fc.CachePresignedURLs,
+		DiskCacheDir:          fc.DiskCacheDir,
+		DiskCacheSize:         fc.DiskCacheSize,
+		TrashCatalog:          fc.TrashCatalog,
+		Retry:                 RetryConfig{MaxAttempts: fc.RetryMaxAttempts},
+		Transport: HTTPTransport{
+			ProxyURL:            fc.ProxyURL,
+			CAFile:              fc.CAFile,
+			InsecureSkipVerify:  fc.InsecureSkipVerify,
+			MaxIdleConnsPerHost: fc.MaxIdleConnsPerHost,
+			DisableHTTP2:        fc.DisableHTTP2,
+		},
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+
+	durations := []struct {
+		name  string
+		value string
+		dst   *time.Duration
+	}{
+		{"presigned_url_expire_time", fc.PresignedURLExpireTime, &cfg.PresignedURLExpireTime},
+		{"max_presigned_url_expire_time", fc.MaxPresignedURLExpireTime, &cfg.MaxPresignedURLExpireTime},
+		{"upload_timeout", fc.UploadTimeout, &cfg.UploadTimeout},
+		{"download_timeout", fc.DownloadTimeout, &cfg.DownloadTimeout},
+		{"list_timeout", fc.ListTimeout, &cfg.ListTimeout},
+		{"delete_timeout", fc.DeleteTimeout, &cfg.DeleteTimeout},
+		{"request_timeout", fc.RequestTimeout, &cfg.RequestTimeout},
+		{"idle_conn_timeout", fc.IdleConnTimeout, &cfg.Transport.IdleConnTimeout},
+		{"cache_ttl", fc.CacheTTL, &cfg.CacheTTL},
+	}
+	for _, duration := range durations {
+		if duration.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(duration.value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %w", ErrInvalidConfig, duration.name, err)
+		}
+		*duration.dst = parsed
+	}
+	if cfg.PresignedURLExpireTime == 0 {
+		cfg.PresignedURLExpireTime = defaultPresignedURLExpireTime
+	}
+
+	return cfg, nil
+}
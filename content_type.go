@@ -0,0 +1,101 @@
+package s3_manager
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+const sniffLength = 512 // Количество байт, по которым определяется тип содержимого (см. http.DetectContentType)
+
+// MIME-типы для расширений, которых может не быть в системной таблице типов (mime.TypeByExtension)
+var extraContentTypes = map[string]string{
+	".m3u8": "application/vnd.apple.mpegurl",
+	".ts":   "video/mp2t",
+	".mp4":  "video/mp4",
+	".m4v":  "video/x-m4v",
+	".mov":  "video/quicktime",
+	".webm": "video/webm",
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+	".heic": "image/heic",
+	".ico":  "image/x-icon",
+	".bmp":  "image/bmp",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".txt":  "text/plain; charset=utf-8",
+	".csv":  "text/csv; charset=utf-8",
+	".md":   "text/markdown; charset=utf-8",
+	".zip":  "application/zip",
+	".gz":   "application/gzip",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".ppt":  "application/vnd.ms-powerpoint",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// Определяет MIME-тип по расширению имени файла. Возвращает пустую строку, если расширение неизвестно.
+func contentTypeByExtension(fileName string) string {
+	ext := strings.ToLower(path.Ext(fileName))
+	if ext == "" {
+		return ""
+	}
+	if contentType, ok := extraContentTypes[ext]; ok {
+		return contentType
+	}
+
+	return mime.TypeByExtension(ext)
+}
+
+// Определяет MIME-тип по первым байтам содержимого файла. Возвращает поток, из которого нужно читать содержимое дальше:
+// позиция в потоке с поддержкой Seek возвращается на место, а для потока без Seek прочитанные байты добавляются перед остатком потока.
+func sniffContentType(file io.Reader) (string, io.Reader, error) {
+	seeker, ok := file.(io.ReadSeeker)
+	if !ok {
+		buf := make([]byte, sniffLength)
+		n, err := io.ReadFull(file, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return "", nil, fmt.Errorf("sniffContentType/Read: %w", err)
+		}
+		return http.DetectContentType(buf[:n]), io.MultiReader(bytes.NewReader(buf[:n]), file), nil
+	}
+
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", nil, fmt.Errorf("sniffContentType/Seek: %w", err)
+	}
+
+	buf := make([]byte, sniffLength)
+	n, err := io.ReadFull(seeker, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", nil, fmt.Errorf("sniffContentType/Read: %w", err)
+	}
+
+	_, err = seeker.Seek(current, io.SeekStart)
+	if err != nil {
+		return "", nil, fmt.Errorf("sniffContentType/Seek: %w", err)
+	}
+
+	return http.DetectContentType(buf[:n]), seeker, nil
+}
+
+// Определяет MIME-тип загружаемого файла: сначала по расширению, затем (если включено Config.SniffContentType) по содержимому.
+// Возвращает поток, из которого нужно загружать содержимое файла (см. sniffContentType).
+func (r *s3Manager) detectContentType(fileName string, file io.Reader) (string, io.Reader, error) {
+	if contentType := contentTypeByExtension(fileName); contentType != "" {
+		return contentType, file, nil
+	}
+	if !r.cfg.SniffContentType {
+		return "", file, nil
+	}
+
+	return sniffContentType(file)
+}
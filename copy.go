@@ -0,0 +1,289 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	maxCopyObjectSize int64 = 5 << 30   // Максимальный размер объекта для копирования одним запросом CopyObject (5 ГиБ)
+	copyPartSize      int64 = 512 << 20 // Размер части при multipart-копировании (512 МиБ)
+)
+
+// Метод для копирования файла внутри бакета (например, из временного каталога загрузок в каталог сущности).
+// Копирование выполняется на стороне S3; объекты больше 5 ГиБ копируются по частям. Возвращает ссылку на новый файл.
+// С опцией WithVersionID копируется указанная версия исходного файла. Класс хранения новому файлу задаётся опцией WithStorageClass
+// или классом каталога назначения по умолчанию. ACL новому файлу задаётся только опцией WithACL (см. copyACL).
+func (r *s3Manager) CopyFile(ctx context.Context, srcPath StoragePath, srcName string, dstPath StoragePath, dstName string, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if srcName == "" || dstName == "" {
+		return "", fmt.Errorf("CopyFile: %w: file name is empty", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	o.storageClass = r.uploadStorageClass(dstPath, o)
+	srcKey, err := r.objectKey(srcPath, srcName)
+	if err != nil {
+		return "", fmt.Errorf("CopyFile/objectKey: %w", err)
+	}
+	dstKey, err := r.objectKey(dstPath, dstName)
+	if err != nil {
+		return "", fmt.Errorf("CopyFile/objectKey: %w", err)
+	}
+
+	err = r.copyObject(ctx, srcKey, dstKey, o)
+	if err != nil {
+		return "", fmt.Errorf("CopyFile/copyObject: %w", err)
+	}
+	r.invalidate(ctx, dstKey)
+
+	fileURL, err := r.GetObjectURL(dstPath, dstName, opts...)
+	if err != nil {
+		return "", fmt.Errorf("CopyFile/GetObjectURL: %w", err)
+	}
+
+	return fileURL, nil
+}
+
+// Метод для перемещения файла внутри бакета: копирование на стороне S3 с последующим удалением исходного файла. Возвращает ссылку на новый файл.
+// Если исходный и новый пути совпадают, файл не копируется и не удаляется.
+func (r *s3Manager) MoveFile(ctx context.Context, srcPath StoragePath, srcName string, dstPath StoragePath, dstName string, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if srcName == "" || dstName == "" {
+		return "", fmt.Errorf("MoveFile: %w: file name is empty", ErrInvalidInput)
+	}
+	srcKey, err := r.objectKey(srcPath, srcName)
+	if err != nil {
+		return "", fmt.Errorf("MoveFile/objectKey: %w", err)
+	}
+	dstKey, err := r.objectKey(dstPath, dstName)
+	if err != nil {
+		return "", fmt.Errorf("MoveFile/objectKey: %w", err)
+	}
+	if srcKey == dstKey { // Файл перемещается сам в себя: копировать и удалять нечего
+		fileURL, err := r.GetObjectURL(dstPath, dstName, opts...)
+		if err != nil {
+			return "", fmt.Errorf("MoveFile/GetObjectURL: %w", err)
+		}
+		return fileURL, nil
+	}
+
+	fileURL, err := r.CopyFile(ctx, srcPath, srcName, dstPath, dstName, opts...)
+	if err != nil {
+		return "", fmt.Errorf("MoveFile/CopyFile: %w", err)
+	}
+
+	err = r.DeleteFile(ctx, srcPath, srcName, append(opts, WithPermanentDelete())...) // Исходный файл уже скопирован, в корзину его класть не нужно
+	if err != nil {
+		return "", fmt.Errorf("MoveFile/DeleteFile: %w", err)
+	}
+
+	return fileURL, nil
+}
+
+// Метод для переименования файла внутри каталога (копирование на стороне S3 с удалением исходного файла). Возвращает ссылку на переименованный файл.
+func (r *s3Manager) RenameFile(ctx context.Context, storagePath StoragePath, oldName, newName string, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	fileURL, err := r.MoveFile(ctx, storagePath, oldName, storagePath, newName, opts...)
+	if err != nil {
+		return "", fmt.Errorf("RenameFile/MoveFile: %w", err)
+	}
+
+	return fileURL, nil
+}
+
+// Копирует объект внутри бакета по ключам: одним запросом CopyObject или по частям для объектов больше 5 ГиБ
+func (r *s3Manager) copyObject(ctx context.Context, srcKey, dstKey string, o operationOptions) error {
+	headInput := &s3.HeadObjectInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &srcKey,
+		VersionId: nonEmpty(o.versionID),
+	}
+	o.encryption.applyToHead(headInput)
+
+	headOutput, err := r.client.HeadObject(ctx, headInput)
+	if err != nil {
+		return fmt.Errorf("copyObject/HeadObject: %w", classifyError(err))
+	}
+
+	source := copySource(r.cfg.Name, srcKey)
+	if o.versionID != "" {
+		source += "?versionId=" + url.QueryEscape(o.versionID)
+	}
+
+	if aws.ToInt64(headOutput.ContentLength) > maxCopyObjectSize {
+		err = r.copyMultipart(ctx, source, dstKey, headOutput, o)
+		if err != nil {
+			return fmt.Errorf("copyObject/copyMultipart: %w", err)
+		}
+	} else {
+		copyInput := &s3.CopyObjectInput{
+			Bucket:       &r.cfg.Name,
+			Key:          &dstKey,
+			CopySource:   &source,
+			StorageClass: o.storageClass,
+		}
+		copyInput.ACL = copyACL(o)
+		o.encryption.applyToCopy(copyInput)
+
+		_, err = r.client.CopyObject(ctx, copyInput)
+		if err != nil {
+			return fmt.Errorf("copyObject/CopyObject: %w", classifyError(err))
+		}
+	}
+
+	return nil
+}
+
+// ACL копии объекта. Передаётся, только если задан опцией WithACL: иначе копирование закрытого файла сделало бы его публичным
+// по умолчанию Config.DefaultACL. Без ACL копия получает ACL по умолчанию бакета (S3 не копирует ACL исходного объекта).
+func copyACL(o operationOptions) types.ObjectCannedACL {
+	if !o.explicitACL || o.acl == NoACL {
+		return ""
+	}
+	return o.acl
+}
+
+// Копирует объект по частям (UploadPartCopy). Используется для объектов больше 5 ГиБ, которые нельзя скопировать одним запросом CopyObject.
+func (r *s3Manager) copyMultipart(ctx context.Context, source, dstKey string, src *s3.HeadObjectOutput, o operationOptions) error {
+	createInput := &s3.CreateMultipartUploadInput{
+		Bucket:             &r.cfg.Name,
+		Key:                &dstKey,
+		CacheControl:       src.CacheControl,
+		ContentDisposition: src.ContentDisposition,
+		ContentEncoding:    src.ContentEncoding,
+		ContentLanguage:    src.ContentLanguage,
+		ContentType:        src.ContentType,
+		Metadata:           src.Metadata,
+		StorageClass:       src.StorageClass,
+	}
+	if o.storageClass != "" {
+		createInput.StorageClass = o.storageClass
+	}
+	createInput.ACL = copyACL(o)
+	o.encryption.applyToCreateMultipart(createInput)
+
+	createOutput, err := r.client.CreateMultipartUpload(ctx, createInput)
+	if err != nil {
+		return fmt.Errorf("copyMultipart/CreateMultipartUpload: %w", classifyError(err))
+	}
+	uploadID := createOutput.UploadId
+
+	parts, err := r.copyParts(ctx, source, dstKey, uploadID, aws.ToInt64(src.ContentLength), o.encryption)
+	if err != nil {
+		r.abortMultipart(ctx, &r.cfg.Name, &dstKey, uploadID)
+		return fmt.Errorf("copyMultipart/copyParts: %w", err)
+	}
+
+	_, err = r.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &r.cfg.Name,
+		Key:             &dstKey,
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		r.abortMultipart(ctx, &r.cfg.Name, &dstKey, uploadID)
+		return fmt.Errorf("copyMultipart/CompleteMultipartUpload: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Параллельно копирует диапазоны исходного объекта в части multipart-загрузки. Возвращает список частей, отсортированный по номеру.
+func (r *s3Manager) copyParts(ctx context.Context, source, dstKey string, uploadID *string, size int64, encryption Encryption) ([]types.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		parts    []types.CompletedPart
+		firstErr error
+	)
+
+	semaphore := make(chan struct{}, r.multipartConcurrency())
+	partNumber := int32(1)
+	for start := int64(0); start < size; start += copyPartSize {
+		end := min(start+copyPartSize, size) - 1
+
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(partNumber int32, start, end int64) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			partInput := &s3.UploadPartCopyInput{
+				Bucket:          &r.cfg.Name,
+				Key:             &dstKey,
+				UploadId:        uploadID,
+				PartNumber:      aws.Int32(partNumber),
+				CopySource:      &source,
+				CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+			}
+			encryption.applyToUploadPartCopy(partInput)
+
+			output, err := r.client.UploadPartCopy(ctx, partInput)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("part %d: UploadPartCopy: %w", partNumber, classifyError(err))
+					cancel()
+				}
+				return
+			}
+			part := types.CompletedPart{PartNumber: aws.Int32(partNumber)}
+			if output.CopyPartResult != nil {
+				part.ETag = output.CopyPartResult.ETag
+			}
+			parts = append(parts, part)
+		}(partNumber, start, end)
+		partNumber++
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber)
+	})
+
+	return parts, nil
+}
+
+// Формирует значение заголовка x-amz-copy-source ("бакет/ключ" в URL-кодировке с сохранением разделителей пути)
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return url.PathEscape(bucket) + "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,74 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestCopyAndMoveACL(t *testing.T) {
+	tests := []struct {
+		name    string
+		move    bool
+		opts    []Option
+		wantACL types.ObjectCannedACL
+	}{
+		{"copy keeps private file private", false, nil, types.ObjectCannedACLPrivate},
+		{"move keeps private file private", true, nil, types.ObjectCannedACLPrivate},
+		{"explicit ACL is applied", true, []Option{WithACL(types.ObjectCannedACLPublicRead)}, types.ObjectCannedACLPublicRead},
+		{"NoACL sends no ACL", true, []Option{WithACL(NoACL)}, types.ObjectCannedACLPrivate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			backend := newACLBackend()
+			manager := newTestManagerWithBackend(t, backend, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+
+			_, err := manager.PutFile(ctx, StoragePath{}, &BucketFile{Name: "private.txt", File: bytes.NewReader([]byte("secret"))}, withRawName(), WithACL(types.ObjectCannedACLPrivate))
+			if err != nil {
+				t.Fatalf("PutFile: %v", err)
+			}
+
+			if tt.move {
+				_, err = manager.MoveFile(ctx, StoragePath{}, "private.txt", StoragePath{}, "moved.txt", tt.opts...)
+			} else {
+				_, err = manager.CopyFile(ctx, StoragePath{}, "private.txt", StoragePath{}, "moved.txt", tt.opts...)
+			}
+			if err != nil {
+				t.Fatalf("copy: %v", err)
+			}
+
+			if acl := backend.acl("moved.txt"); acl != tt.wantACL {
+				t.Errorf("ACL = %q, want %q", acl, tt.wantACL)
+			}
+		})
+	}
+}
+
+func TestMoveFileOntoItself(t *testing.T) {
+	ctx := context.Background()
+	manager, backend := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+
+	_, err := manager.PutFile(ctx, StoragePath{}, &BucketFile{Name: "a.txt", File: bytes.NewReader([]byte("content"))}, withRawName())
+	if err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+
+	fileURL, err := manager.MoveFile(ctx, StoragePath{}, "a.txt", StoragePath{}, "a.txt")
+	if err != nil {
+		t.Fatalf("MoveFile: %v", err)
+	}
+	if fileURL != "http://s3.test/b/a.txt" {
+		t.Errorf("MoveFile URL = %q, want %q", fileURL, "http://s3.test/b/a.txt")
+	}
+	if _, err := manager.RenameFile(ctx, StoragePath{}, "a.txt", "a.txt"); err != nil {
+		t.Fatalf("RenameFile: %v", err)
+	}
+
+	if content := readObject(t, backend, "a.txt"); content != "content" {
+		t.Errorf("content = %q, want %q", content, "content")
+	}
+}
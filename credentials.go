@@ -0,0 +1,21 @@
+package s3_manager
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const credentialsExpiryWindow = time.Minute // Запас времени до истечения учётных данных, за который они запрашиваются заново
+
+// Оборачивает провайдер учётных данных (Config.Credentials) в кеш, который запрашивает новые учётные данные только перед истечением срока
+// действия текущих, чтобы запросы не подписывались ключами, истекающими в пути. Провайдер, который уже кеширует учётные данные, возвращается как есть.
+func cachedCredentials(provider aws.CredentialsProvider) aws.CredentialsProvider {
+	if _, ok := provider.(*aws.CredentialsCache); ok {
+		return provider
+	}
+
+	return aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = credentialsExpiryWindow
+	})
+}
@@ -0,0 +1,295 @@
+package s3_manager
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultDiskCacheSize = 1 << 30 // Максимальный размер дискового кеша файлов по умолчанию (1 ГиБ)
+	diskCacheInfoExt     = ".json" // Расширение файла с информацией о файле в кеше; содержимое хранится в файле без расширения рядом
+	diskCacheTempPattern = "*.tmp" // Шаблон имени временного файла, в который записывается скачиваемое содержимое
+)
+
+// Дисковый кеш содержимого файлов для GetFile (см. Config.DiskCacheDir). Файл в кеше проверяется условным запросом с его ETag:
+// если файл в бакете не изменился, хранилище отвечает без содержимого и файл читается с диска. Кеш переживает перезапуск сервиса.
+// При превышении размера удаляются давно не использованные файлы.
+type diskCache struct {
+	dir     string
+	maxSize int64
+	mu      sync.Mutex
+	size    int64                    // Суммарный размер содержимого файлов в кеше
+	entries map[string]*list.Element // Записи по имени файла в каталоге кеша
+	order   *list.List               // Записи от недавно использованных к давно не использованным
+}
+
+type diskCacheEntry struct {
+	name string
+	info FileInfo
+}
+
+// Открывает дисковый кеш в каталоге dir (создаёт его, если нужно) и загружает записи, сохранённые при прошлых запусках
+func newDiskCache(dir string, maxSize int64) (*diskCache, error) {
+	if maxSize <= 0 {
+		maxSize = defaultDiskCacheSize
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("newDiskCache/MkdirAll: %w", err)
+	}
+
+	c := &diskCache{dir: dir, maxSize: maxSize, entries: make(map[string]*list.Element), order: list.New()}
+	if err := c.load(); err != nil {
+		return nil, fmt.Errorf("newDiskCache/load: %w", err)
+	}
+
+	return c, nil
+}
+
+// Загружает записи из каталога кеша в порядке последнего использования и удаляет незавершённые записи
+func (c *diskCache) load() error {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("load/ReadDir: %w", err)
+	}
+
+	type loaded struct {
+		entry   *diskCacheEntry
+		usedAt  time.Time
+		dataLen int64
+	}
+	var entries []loaded
+	for _, dirEntry := range dirEntries {
+		fileName := dirEntry.Name()
+		if matched, _ := filepath.Match(diskCacheTempPattern, fileName); matched {
+			_ = os.Remove(filepath.Join(c.dir, fileName)) // Скачивание, прерванное завершением процесса
+			continue
+		}
+		name, ok := strings.CutSuffix(fileName, diskCacheInfoExt)
+		if !ok {
+			continue
+		}
+
+		entry := &diskCacheEntry{name: name}
+		data, err := os.ReadFile(filepath.Join(c.dir, fileName))
+		if err == nil {
+			err = json.Unmarshal(data, &entry.info)
+		}
+		stat, statErr := os.Stat(filepath.Join(c.dir, name))
+		if err != nil || statErr != nil {
+			c.removeFiles(name)
+			continue
+		}
+		entries = append(entries, loaded{entry: entry, usedAt: stat.ModTime(), dataLen: stat.Size()})
+	}
+
+	slices.SortFunc(entries, func(a, b loaded) int { return a.usedAt.Compare(b.usedAt) })
+	for _, e := range entries {
+		c.entries[e.entry.name] = c.order.PushFront(e.entry)
+		c.size += e.dataLen
+	}
+	for _, dirEntry := range dirEntries {
+		if _, ok := c.entries[dirEntry.Name()]; !ok && filepath.Ext(dirEntry.Name()) == "" {
+			_ = os.Remove(filepath.Join(c.dir, dirEntry.Name())) // Содержимое без информации о файле: запись не завершена
+		}
+	}
+	c.evict()
+
+	return nil
+}
+
+// Имя файла в кеше для объекта: содержимое разных версий и бакетов хранится отдельно
+func diskCacheName(bucket, key, versionID string) string {
+	sum := sha256.Sum256([]byte(bucket + "/" + key + "?versionId=" + versionID))
+	return hex.EncodeToString(sum[:])
+}
+
+// Открывает файл из кеша. Файл открывается до проверки актуальности, чтобы его не удалили при вытеснении, пока идёт запрос к хранилищу.
+func (c *diskCache) open(name string) (*os.File, *FileInfo) {
+	c.mu.Lock()
+	element, ok := c.entries[name]
+	if !ok {
+		c.mu.Unlock()
+		return nil, nil
+	}
+	entry := element.Value.(*diskCacheEntry)
+	c.order.MoveToFront(element)
+	c.mu.Unlock()
+
+	file, err := os.Open(filepath.Join(c.dir, name))
+	if err != nil {
+		c.remove(name)
+		return nil, nil
+	}
+	now := time.Now()
+	_ = os.Chtimes(file.Name(), now, now) // Порядок использования сохраняется между запусками
+
+	info := entry.info
+	return file, &info
+}
+
+// Возвращает поток, который при чтении записывает содержимое файла во временный файл и добавляет его в кеш, когда файл прочитан полностью.
+// Если поток закрыт раньше или запись на диск не удалась, файл в кеш не добавляется.
+func (c *diskCache) tee(name string, body io.ReadCloser, info *FileInfo) io.ReadCloser {
+	if info.Size > c.maxSize {
+		return body
+	}
+	temp, err := os.CreateTemp(c.dir, diskCacheTempPattern)
+	if err != nil {
+		return body
+	}
+
+	return &diskCacheWriter{cache: c, name: name, info: *info, body: body, temp: temp}
+}
+
+// Переносит скачанное содержимое из временного файла в кеш
+func (c *diskCache) commit(name string, info FileInfo, temp string) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("commit/Marshal: %w", err)
+	}
+	if err := os.Rename(temp, filepath.Join(c.dir, name)); err != nil {
+		return fmt.Errorf("commit/Rename: %w", err)
+	}
+	// Информация о файле записывается последней: по её наличию при загрузке кеша запись считается завершённой
+	infoTemp, err := os.CreateTemp(c.dir, diskCacheTempPattern)
+	if err != nil {
+		c.remove(name)
+		return fmt.Errorf("commit/CreateTemp: %w", err)
+	}
+	_, err = infoTemp.Write(data)
+	if closeErr := infoTemp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(infoTemp.Name(), filepath.Join(c.dir, name+diskCacheInfoExt))
+	}
+	if err != nil {
+		_ = os.Remove(infoTemp.Name())
+		c.remove(name) // Содержимое уже заменено, поэтому прежняя запись тоже недействительна
+		return fmt.Errorf("commit/Write: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[name]; ok {
+		c.size -= element.Value.(*diskCacheEntry).info.Size
+		c.order.Remove(element)
+	}
+	c.entries[name] = c.order.PushFront(&diskCacheEntry{name: name, info: info})
+	c.size += info.Size
+	c.evict()
+
+	return nil
+}
+
+// Удаляет давно не использованные файлы, пока размер кеша превышает максимальный. Вызывается под mu.
+func (c *diskCache) evict() {
+	for c.size > c.maxSize && c.order.Len() > 0 {
+		entry := c.order.Remove(c.order.Back()).(*diskCacheEntry)
+		delete(c.entries, entry.name)
+		c.size -= entry.info.Size
+		c.removeFiles(entry.name)
+	}
+}
+
+// Удаляет запись и её файлы
+func (c *diskCache) remove(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[name]; ok {
+		c.size -= element.Value.(*diskCacheEntry).info.Size
+		c.order.Remove(element)
+		delete(c.entries, name)
+	}
+	c.removeFiles(name)
+}
+
+func (c *diskCache) removeFiles(name string) {
+	_ = os.Remove(filepath.Join(c.dir, name+diskCacheInfoExt))
+	_ = os.Remove(filepath.Join(c.dir, name))
+}
+
+// Возвращает поток с содержимым файла из кеша с теми же обработками, что и при скачивании (прогресс, распаковка)
+func readCachedFile(file *os.File, info *FileInfo, fileName string, o operationOptions) (io.ReadCloser, error) {
+	info.Name = fileName
+
+	var body io.ReadCloser = file
+	if o.progress != nil {
+		body = newProgressReadCloser(body, info.Size, o.progress)
+	}
+	if !o.rawContent {
+		decompressed, err := decompressReader(body, info.ContentEncoding)
+		if err != nil {
+			body.Close()
+			return nil, fmt.Errorf("readCachedFile/decompressReader: %w", err)
+		}
+		body = decompressed
+	}
+
+	return body, nil
+}
+
+// Поток содержимого файла, которое одновременно записывается в кеш
+type diskCacheWriter struct {
+	cache   *diskCache
+	name    string
+	info    FileInfo
+	body    io.ReadCloser
+	temp    *os.File // nil после переноса в кеш или отказа от него
+	written int64
+}
+
+func (w *diskCacheWriter) Read(p []byte) (int, error) {
+	n, err := w.body.Read(p)
+	if n > 0 && w.temp != nil {
+		if _, writeErr := w.temp.Write(p[:n]); writeErr != nil {
+			w.discard()
+		}
+		w.written += int64(n)
+	}
+	if errors.Is(err, io.EOF) && w.temp != nil {
+		w.finish()
+	}
+
+	return n, err
+}
+
+func (w *diskCacheWriter) Close() error {
+	w.discard()
+	return w.body.Close()
+}
+
+// Добавляет файл в кеш, если содержимое получено полностью
+func (w *diskCacheWriter) finish() {
+	if w.written != w.info.Size {
+		w.discard()
+		return
+	}
+	temp := w.temp
+	w.temp = nil
+	if err := temp.Close(); err != nil || w.cache.commit(w.name, w.info, temp.Name()) != nil {
+		_ = os.Remove(temp.Name())
+	}
+}
+
+func (w *diskCacheWriter) discard() {
+	if w.temp == nil {
+		return
+	}
+	_ = w.temp.Close()
+	_ = os.Remove(w.temp.Name())
+	w.temp = nil
+}
@@ -2,26 +2,80 @@ package s3_manager
 
 import (
 	"io"
+	"log/slog"
+	"net/http"
+	"sync"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type s3Manager struct {
-	client       *s3.Client
-	cfg          *Config
-	storagePaths map[CatalogType]string // Соответствие типов каталогов паттернам путей в бакете. Используется для формирования пути к файлу в бакете. Например, "users" -> "users/%d/", "product_certificates" -> "products/%d/certificates/".
+	client              StorageBackend
+	cfg                 *Config
+	isTestServer        bool                               // Менеджер создан в тестовом режиме: к корневому каталогу добавляется "test/"
+	rawKeys             bool                               // Имена файлов — полные ключи объектов (копия менеджера для вызова с withRawKey, см. forCall)
+	workers             chan struct{}                      // Общее ограничение количества одновременных задач пакетных операций (см. Config.MaxConcurrency), nil — без ограничения
+	presignCache        Cache                              // Кеш подписанных ссылок на скачивание (см. Config.CachePresignedURLs), nil — ссылки не кешируются
+	diskCache           *diskCache                         // Дисковый кеш содержимого файлов (см. Config.DiskCacheDir), nil — кеш выключен
+	catalogMu           *sync.RWMutex                      // Защищает содержимое карт каталогов ниже: каталоги можно добавлять во время работы с файлами. Карты создаются в конструкторе и общие для копий менеджера (см. forCall).
+	imageCatalogs       map[CatalogType]ImageConfig        // Обработка изображений по типам каталогов (см. AddImageCatalog)
+	catalogCompression  map[CatalogType]Compression        // Сжатие загружаемых файлов по умолчанию по типам каталогов (см. AddCatalogWithCompression)
+	catalogRules        map[CatalogType]UploadRules        // Правила проверки загружаемых файлов по типам каталогов (см. AddCatalogWithRules)
+	catalogStorageClass map[CatalogType]types.StorageClass // Класс хранения загружаемых файлов по умолчанию по типам каталогов (см. AddCatalogWithStorageClass)
+	catalogHeaders      map[CatalogType]ObjectHeaders      // HTTP-заголовки загружаемых файлов по умолчанию по типам каталогов (см. AddCatalogWithHeaders)
+	storagePaths        map[CatalogType]string             // Соответствие типов каталогов паттернам путей в бакете. Используется для формирования пути к файлу в бакете. Например, "users" -> "users/%d/", "product_certificates" -> "products/%d/certificates/".
 }
 
 type Config struct {
-	Endpoint               string
-	Region                 string
-	AccessKey              string
-	SecretKey              string
-	Name                   string        // Имя бакета
-	RootCatalog            string        // Путь до нужного (корневого для сервиса) каталога в бакете. Например, "/examplesiteservice" для файлов определённого сервиса.
-	CDN                    string        // CDN-ссылка для файлов в бакете (например, "https://cdn.examplesite.com"). Если заполнено, то заменяет собой хост ссылки при получении URL файлов.
-	PresignedURLExpireTime time.Duration // Время жизни подписанной ссылки по умолчанию (например, 15 минут)
+	Endpoint                  string
+	Region                    string
+	AccessKey                 string
+	SecretKey                 string
+	UseDefaultCredentials     bool                    // Не использовать AccessKey и SecretKey, а получать учётные данные стандартной цепочкой AWS SDK (переменные окружения, общий конфиг, IRSA в EKS, роли ECS и EC2)
+	Credentials               aws.CredentialsProvider // Провайдер учётных данных вместо AccessKey и SecretKey (например, aws.CredentialsProviderFunc, получающая ключи из Vault). Учётные данные кешируются и запрашиваются заново перед истечением срока Expires.
+	Name                      string                  // Имя бакета
+	RootCatalog               string                  // Путь до нужного (корневого для сервиса) каталога в бакете. Например, "examplesiteservice/" для файлов определённого сервиса. Приводится к виду без "/" в начале и с "/" в конце.
+	CDN                       string                  // CDN-ссылка для файлов в бакете (например, "https://cdn.examplesite.com"). Если заполнено, то заменяет собой хост ссылки при получении URL файлов.
+	CDNSigner                 URLSigner               // Подпись ссылок на файлы в CDN для GetSignedCDNURL (например, NewCloudFrontSigner или NewTokenSigner)
+	Invalidator               Invalidator             // Сброс кеша CDN после загрузки, копирования и удаления файлов (например, cloudfrontinvalidator.New или NewWebhookInvalidator)
+	PresignedURLExpireTime    time.Duration           // Время жизни подписанной ссылки по умолчанию (например, 15 минут)
+	MaxPresignedURLExpireTime time.Duration           // Максимальное время жизни подписанной ссылки: большие значения уменьшаются до него. Не может превышать 7 дней (лимит S3).
+	DefaultACL                types.ObjectCannedACL   // ACL загружаемых файлов по умолчанию (например, types.ObjectCannedACLPrivate). Если не заполнено, используется public-read. Для загрузки без ACL используется NoACL.
+	MultipartThreshold        int64                   // Размер файла в байтах, начиная с которого используется multipart upload. По умолчанию 64 МиБ.
+	MultipartPartSize         int64                   // Размер одной части multipart upload в байтах (не меньше 5 МиБ). По умолчанию 16 МиБ.
+	MultipartConcurrency      int                     // Количество одновременно загружаемых частей multipart upload. По умолчанию 4.
+	DownloadPartSize          int64                   // Размер одной части при параллельном скачивании (DownloadToWriterAt, WithParallelDownload) в байтах. По умолчанию 16 МиБ.
+	DownloadConcurrency       int                     // Количество одновременно скачиваемых частей при параллельном скачивании. По умолчанию 4.
+	MaxConcurrency            int                     // Максимальное количество одновременных операций с файлами во всех пакетных методах менеджера вместе (PutFiles, SyncUp, SyncDown, удаление в корзину). По умолчанию не ограничено.
+	MaxUploadSize             int64                   // Максимальный размер загружаемого файла в байтах (PutFile, PutFiles, PutFromMultipartForm, подписанные формы). Может быть переопределён для каталога правилами AddCatalogWithRules. По умолчанию не ограничен.
+	SniffContentType          bool                    // Определять MIME-тип загружаемых файлов по содержимому, если его не удалось определить по расширению
+	NameSanitization          NameSanitization        // Очистка имён загружаемых файлов (удаление каталогов и управляющих символов, транслитерация и т.д.). По умолчанию выключена.
+	NamingStrategy            NamingStrategy          // Способ формирования уникальных имён файлов при загрузке с WithUniqueName. По умолчанию NamingUUID.
+	ChecksumAlgorithm         types.ChecksumAlgorithm // Алгоритм контрольной суммы для проверки целостности загружаемых файлов (например, types.ChecksumAlgorithmSha256). По умолчанию проверка не выполняется.
+	RateLimit                 RateLimit               // Ограничение частоты запросов и скорости передачи данных для этого менеджера. По умолчанию не ограничено.
+	Retry                     RetryConfig             // Настройки повторных попыток запросов при временных ошибках (5xx, тайм-ауты, троттлинг)
+	UploadTimeout             time.Duration           // Тайм-аут загрузки файла (PutFile). По умолчанию не ограничен.
+	DownloadTimeout           time.Duration           // Тайм-аут скачивания файла, включая чтение его содержимого (GetFile, DownloadToWriter). По умолчанию не ограничен.
+	ListTimeout               time.Duration           // Тайм-аут получения списка файлов (GetFiles, ListObjects). По умолчанию не ограничен.
+	DeleteTimeout             time.Duration           // Тайм-аут удаления файлов (DeleteFile, DeleteFiles). По умолчанию не ограничен.
+	RequestTimeout            time.Duration           // Тайм-аут остальных запросов (StatFile, FileExists, CopyFile и т.д.). По умолчанию не ограничен.
+	AddressingStyle           AddressingStyle         // Способ адресации бакета (path-style или virtual-hosted-style) в запросах и ссылках на файлы. По умолчанию запросы — как решит SDK, ссылки — path-style.
+	Encryption                Encryption              // Шифрование загружаемых объектов на стороне сервера по умолчанию (SSE-S3, SSE-KMS или SSE-C)
+	LegacyCatalogPaths        bool                    // Прежняя адресация файлов для перехода со старых версий: при пустом или незарегистрированном типе каталога (или незаданном параметре паттерна) файл адресуется ключом без корневого каталога, в корне бакета, вместо ошибки ErrUnknownCatalog. По умолчанию выключено.
+	ReadOnly                  bool                    // Запретить изменение бакета: загрузка, удаление, копирование, ссылки на загрузку и изменение настроек бакета возвращают ErrReadOnly (например, для сервисов отчётов). Client и PresignClient возвращают nil.
+	DisableCoalescing         bool                    // Не объединять одинаковые одновременные запросы списка файлов и информации о файле (GetFiles, ListObjects, StatFile) в один запрос к хранилищу. По умолчанию объединяются.
+	Cache                     Cache                   // Кеш информации о файлах и списков файлов (StatFile, FileExists, GetFiles, ListObjects), сбрасываемый при изменении файлов через менеджер, и подписанных ссылок (см. CachePresignedURLs). Если не задан, но задан CacheTTL, используется NewMemoryCache.
+	CachePresignedURLs        bool                    // Кешировать подписанные ссылки на скачивание (GetDownloadPresignedURL) в Cache до истечения 80% их срока, чтобы не подписывать одинаковые ссылки заново. Ссылка из кеша действует ещё не меньше 20% запрошенного срока; для временных учётных данных срок в кеше ограничивается и сроком их действия. Если Cache не задан, используется NewMemoryCache.
+	CacheTTL                  time.Duration           // Время хранения информации о файлах и списков файлов в кеше: изменения в обход менеджера видны не позже чем через него. По умолчанию 1 минута, если задан Cache, иначе кеш выключен.
+	DiskCacheDir              string                  // Каталог дискового кеша содержимого файлов для GetFile и DownloadToWriter (например, для фоновых задач, которые при каждом запуске обрабатывают одни и те же файлы). Актуальность файла проверяется условным запросом по ETag. По умолчанию кеш выключен.
+	DiskCacheSize             int64                   // Максимальный размер дискового кеша в байтах: при превышении удаляются давно не использованные файлы. По умолчанию 1 ГиБ.
+	TrashCatalog              string                  // Каталог корзины от корня бакета (например, ".trash/"). Если заполнено, DeleteFile и DeleteFiles перемещают файлы в корзину вместо удаления.
+	HTTPClient                *http.Client            // HTTP-клиент для запросов к хранилищу (например, с корпоративным прокси). Если задан, Transport не используется.
+	Transport                 HTTPTransport           // Настройки HTTP-транспорта: прокси, TLS, тайм-ауты соединения и размер пула соединений
+	AuditSink                 AuditSink               // Журнал аудита: получает запись о каждом изменяющем запросе с исполнителем из контекста (см. WithAuditActor). По умолчанию не ведётся.
+	Logger                    *slog.Logger            // Логгер для отладки: каждый запрос к хранилищу логируется с бакетом, ключом, длительностью и результатом на уровне Debug
 }
 
 // Типы каталогов для хранения файлов в бакете. Используются для формирования пути к файлу в бакете.
@@ -36,15 +90,17 @@ const PathCustomCatalog CatalogType = "custom_catalog" // Используетс
 
 // Информация о пути к файлу в бакете (для единичных файлов). Используется для формирования пути к файлу в бакете.
 type StoragePath struct {
-	RootCatalog string      // Путь к каталогу сервиса, если файлы сервиса хранятся не в корне бакета (например, "static/myproject/"). Используется для формирования полного пути к файлу в бакете.
-	CatalogType CatalogType // Тип пути по назначению файла (например, "custom_catalog", "product", "product_certificates", "user" и т.д.). Если файл должен находиться в корне, то CatalogType должен быть пустым.
-	EntityID    int64       // Идентификатор сущности (например, ID товара или пользователя). Используется, если CatalogType != "custom_catalog".
-	CustomPath  string      // Кастомный путь к файлу в бакете (например, "custom/path/to/file/"). Используется, если CatalogType == "custom_catalog".
+	RootCatalog string         // Путь к каталогу сервиса для GetCatalogPattern (например, "static/myproject/"). Методы работы с файлами его не используют и не изменяют: корневой каталог для них задаётся Config.RootCatalog или опцией WithRootCatalog.
+	CatalogType CatalogType    // Тип пути по назначению файла (например, "custom_catalog", "product", "product_certificates", "user" и т.д.). Если файл должен находиться в корне, то CatalogType должен быть пустым.
+	EntityID    int64          // Идентификатор сущности (например, ID товара или пользователя). Используется, если CatalogType != "custom_catalog".
+	EntityKey   string         // Строковый идентификатор сущности (например, UUID). Если заполнен, подставляется в паттерн каталога вместо EntityID. Не может содержать "/", "\" и быть "." или "..".
+	CustomPath  string         // Кастомный путь к файлу в бакете (например, "custom/path/to/file/"). Используется, если CatalogType == "custom_catalog".
+	Params      map[string]any // Значения именованных параметров паттерна каталога (например, {"org_id": 7, "user_id": 42} для "orgs/{org_id}/users/{user_id}/")
 }
 
 type BucketFile struct {
-	File io.ReadSeeker
-	Name string // Имя файла, включая расширение (например, "image.jpg")
+	File io.Reader // Содержимое файла. Поток без Seek (например, тело HTTP-запроса) загружается по мере чтения, без буферизации всего файла.
+	Name string    // Имя файла, включая расширение (например, "image.jpg")
 }
 
 // Информация о пути в бакете и списке файлов. Используется для загрузки нескольких файлов в бакет по одному пути.
@@ -52,3 +108,30 @@ type BucketFilesData struct {
 	Path  StoragePath  // Путь к файлам в бакете
 	Files []BucketFile // Список файлов для загрузки
 }
+
+// Информация о файле в бакете
+type FileInfo struct {
+	Name            string            // Имя файла, включая расширение (например, "image.jpg")
+	Key             string            // Полный ключ объекта в бакете
+	Size            int64             // Размер файла в байтах
+	ContentType     string            // MIME-тип файла (например, "image/jpeg")
+	ContentEncoding string            // Сжатие содержимого объекта (заголовок Content-Encoding, например, "gzip"). Size — размер сжатого объекта.
+	LastModified    time.Time         // Время последнего изменения файла
+	ETag            string            // ETag объекта
+	Metadata        map[string]string // Пользовательские метаданные объекта (x-amz-meta-*). Ключи возвращаются в нижнем регистре.
+	VersionID       string            // Версия объекта (заполняется для бакетов с версионированием)
+	Retention       *Retention        // Срок хранения объекта (заполняется StatFile для бакетов с блокировкой объектов)
+	LegalHold       bool              // Установлена юридическая блокировка объекта (заполняется StatFile)
+	StorageClass    string            // Класс хранения объекта (заполняется StatFile; пустой для STANDARD)
+	Redirect        string            // Адрес перенаправления объекта-редиректа (заполняется StatFile, см. PutRedirect)
+}
+
+// Информация об объекте в бакете, полученная при просмотре списка объектов
+type ObjectInfo struct {
+	Key          string    // Полный ключ объекта в бакете
+	URL          string    // Ссылка на объект (как в GetObjectURL, с учётом CDN)
+	Size         int64     // Размер объекта в байтах
+	ETag         string    // ETag объекта
+	LastModified time.Time // Время последнего изменения объекта
+	StorageClass string    // Класс хранения объекта (например, "STANDARD")
+}
@@ -0,0 +1,258 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Количество попыток продолжить скачивание подряд после обрыва соединения, если Config.Retry.MaxAttempts не задан
+const defaultResumeAttempts = 3
+
+// Метод для скачивания части файла: length байт, начиная с offset (при length <= 0 — до конца файла).
+// Возвращает поток с содержимым части (его необходимо закрыть после чтения) и информацию о файле (Size — полный размер файла).
+// При обрыве соединения чтение продолжается с последнего полученного байта, если файл в бакете за это время не изменился.
+func (r *s3Manager) DownloadRange(ctx context.Context, storagePath StoragePath, fileName string, offset, length int64, opts ...Option) (io.ReadCloser, *FileInfo, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return nil, nil, fmt.Errorf("DownloadRange: %w: file name is empty", ErrInvalidInput)
+	}
+	if offset < 0 {
+		return nil, nil, fmt.Errorf("DownloadRange: %w: offset is negative", ErrInvalidInput)
+	}
+
+	end := int64(-1)
+	if length > 0 {
+		end = offset + length - 1
+	}
+
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("DownloadRange/objectKey: %w", err)
+	}
+
+	body, fileInfo, err := r.openRange(ctx, fileName, fullPath, offset, end, "", r.applyOptions(opts))
+	if err != nil {
+		return nil, nil, fmt.Errorf("DownloadRange/openRange: %w", err)
+	}
+
+	return body, fileInfo, nil
+}
+
+// Метод для скачивания файла из бакета в локальный файл localPath с возможностью продолжить прерванное скачивание.
+// Файл скачивается во временный файл localPath + ".part", который переименовывается в localPath после завершения. Если временный файл
+// остался от прерванного скачивания и файл в бакете с тех пор не изменялся, скачивание продолжается с его конца, а не с начала.
+// С опцией WithParallelDownload файл скачивается параллельными частями. Возвращает количество байт, скачанных этим вызовом.
+func (r *s3Manager) DownloadToFile(ctx context.Context, storagePath StoragePath, fileName, localPath string, opts ...Option) (int64, error) {
+	r = r.forCall(opts)
+
+	if localPath == "" {
+		return 0, fmt.Errorf("DownloadToFile: %w: local path is empty", ErrInvalidInput)
+	}
+
+	fileInfo, err := r.StatFile(ctx, storagePath, fileName, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("DownloadToFile/StatFile: %w", err)
+	}
+
+	partPath := localPath + ".part"
+	var offset int64
+	if partInfo, err := os.Stat(partPath); err == nil && partInfo.Size() <= fileInfo.Size && !fileInfo.LastModified.After(partInfo.ModTime()) {
+		offset = partInfo.Size()
+	}
+
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("DownloadToFile/OpenFile: %w", err)
+	}
+	defer file.Close()
+
+	err = file.Truncate(offset)
+	if err != nil {
+		return 0, fmt.Errorf("DownloadToFile/Truncate: %w", err)
+	}
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		return 0, fmt.Errorf("DownloadToFile/Seek: %w", err)
+	}
+
+	var written int64
+	if o := r.applyOptions(opts); o.parallelDownload && offset == 0 {
+		written, err = r.downloadParts(ctx, fileInfo, file, o)
+		if err != nil {
+			// Части скачиваются не по порядку, поэтому продолжить скачивание с конца временного файла нельзя
+			file.Close()
+			os.Remove(partPath)
+			return written, fmt.Errorf("DownloadToFile/downloadParts: %w", err)
+		}
+	} else if offset < fileInfo.Size {
+		// If-Match по ETag гарантирует, что продолжение скачивается из той же версии файла, что и начало
+		body, _, err := r.openRange(ctx, fileName, fileInfo.Key, offset, -1, fileInfo.ETag, r.applyOptions(opts))
+		if err != nil {
+			return 0, fmt.Errorf("DownloadToFile/openRange: %w", err)
+		}
+		defer body.Close()
+
+		written, err = io.Copy(file, body)
+		if err != nil {
+			return written, fmt.Errorf("DownloadToFile/Copy: %w", err)
+		}
+	}
+
+	err = file.Close()
+	if err != nil {
+		return written, fmt.Errorf("DownloadToFile/Close: %w", err)
+	}
+	err = os.Rename(partPath, localPath)
+	if err != nil {
+		return written, fmt.Errorf("DownloadToFile/Rename: %w", err)
+	}
+
+	return written, nil
+}
+
+// Открывает поток с содержимым объекта с позиции start до позиции end включительно (при end < 0 — до конца объекта),
+// который продолжает чтение с последнего полученного байта при обрыве соединения. Если ifMatch не пуст, объект должен иметь этот ETag.
+func (r *s3Manager) openRange(ctx context.Context, fileName, key string, start, end int64, ifMatch string, o operationOptions) (io.ReadCloser, *FileInfo, error) {
+	// Контекст с тайм-аутом отменяется при закрытии потока, так как тело ответа читается уже после выхода из метода
+	ctx, cancel := withTimeout(ctx, r.cfg.DownloadTimeout)
+
+	getRange := func(offset int64, etag string) (*s3.GetObjectOutput, error) {
+		byteRange := fmt.Sprintf("bytes=%d-", offset)
+		if end >= 0 {
+			byteRange += strconv.FormatInt(end, 10)
+		}
+
+		getInput := &s3.GetObjectInput{
+			Bucket:    &r.cfg.Name,
+			Key:       &key,
+			Range:     &byteRange,
+			IfMatch:   nonEmpty(etag),
+			VersionId: nonEmpty(o.versionID),
+		}
+		o.encryption.applyToGet(getInput)
+
+		output, err := r.client.GetObject(ctx, getInput)
+		if err != nil {
+			return nil, classifyError(err)
+		}
+		return output, nil
+	}
+
+	output, err := getRange(start, ifMatch)
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("openRange/GetObject: %w", err)
+	}
+
+	fileInfo := &FileInfo{
+		Name:            fileName,
+		Key:             key,
+		Size:            contentRangeSize(aws.ToString(output.ContentRange), aws.ToInt64(output.ContentLength)),
+		ContentType:     aws.ToString(output.ContentType),
+		ContentEncoding: aws.ToString(output.ContentEncoding),
+		LastModified:    aws.ToTime(output.LastModified),
+		ETag:            aws.ToString(output.ETag),
+		Metadata:        decodeMetadata(output.Metadata),
+		VersionID:       aws.ToString(output.VersionId),
+	}
+
+	attempts := r.cfg.Retry.MaxAttempts
+	if attempts <= 0 {
+		attempts = defaultResumeAttempts
+	}
+
+	var body io.ReadCloser = &resumableReader{
+		ctx:      ctx,
+		body:     output.Body,
+		offset:   start,
+		attempts: attempts,
+		open: func(offset int64) (io.ReadCloser, error) {
+			output, err := getRange(offset, fileInfo.ETag)
+			if err != nil {
+				return nil, err
+			}
+			return output.Body, nil
+		},
+	}
+
+	if o.progress != nil {
+		body = newProgressReadCloser(body, aws.ToInt64(output.ContentLength), o.progress)
+	}
+
+	return &cancelOnCloseReader{ReadCloser: body, cancel: cancel}, fileInfo, nil
+}
+
+// Поток, который при ошибке чтения (например, обрыве соединения) заново запрашивает объект с позиции последнего полученного байта.
+// Количество попыток подряд без получения данных ограничено attempts.
+type resumableReader struct {
+	ctx      context.Context
+	body     io.ReadCloser
+	open     func(offset int64) (io.ReadCloser, error) // Запрашивает объект с указанной позиции
+	offset   int64                                     // Позиция следующего байта в объекте
+	attempts int                                       // Максимальное количество попыток продолжить чтение подряд
+	failures int                                       // Количество неудачных попыток подряд
+	err      error                                     // Ошибка, после которой продолжить чтение невозможно
+}
+
+func (r *resumableReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if n > 0 {
+			r.failures = 0
+		}
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, err
+		}
+
+		r.failures++
+		if r.ctx.Err() != nil || r.failures >= r.attempts {
+			r.err = err
+			return n, err
+		}
+
+		_ = r.body.Close()
+		body, openErr := r.open(r.offset)
+		if openErr != nil {
+			r.err = fmt.Errorf("resumableReader/open: %w", openErr)
+			return n, r.err
+		}
+		r.body = body
+
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+func (r *resumableReader) Close() error {
+	return r.body.Close()
+}
+
+// Возвращает полный размер объекта из заголовка Content-Range вида "bytes 0-99/1000" или contentLength, если заголовка нет
+func contentRangeSize(contentRange string, contentLength int64) int64 {
+	_, total, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return contentLength
+	}
+
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return contentLength
+	}
+
+	return size
+}
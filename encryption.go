@@ -0,0 +1,103 @@
+package s3_manager
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const sseCustomerAlgorithm = "AES256" // Единственный алгоритм, поддерживаемый S3 для ключей клиента (SSE-C)
+
+// Настройки шифрования объектов на стороне сервера
+type Encryption struct {
+	Type        types.ServerSideEncryption // Тип шифрования: SSE-S3 (types.ServerSideEncryptionAes256) или SSE-KMS (types.ServerSideEncryptionAwsKms)
+	KMSKeyID    string                     // Идентификатор ключа KMS для SSE-KMS. Если не указан, используется ключ бакета по умолчанию.
+	CustomerKey []byte                     // Ключ клиента для SSE-C (32 байта). Тот же ключ нужен для чтения и копирования объекта. Не совместим с Type.
+}
+
+// Проверяет корректность настроек шифрования
+func (e Encryption) validate() error {
+	if len(e.CustomerKey) > 0 {
+		if len(e.CustomerKey) != 32 {
+			return fmt.Errorf("%w: SSE-C key must be 32 bytes, got %d", ErrInvalidInput, len(e.CustomerKey))
+		}
+		if e.Type != "" {
+			return fmt.Errorf("%w: SSE-C can not be combined with %s encryption", ErrInvalidInput, e.Type)
+		}
+	}
+	if e.KMSKeyID != "" && e.Type != types.ServerSideEncryptionAwsKms && e.Type != types.ServerSideEncryptionAwsKmsDsse {
+		return fmt.Errorf("%w: KMS key ID requires SSE-KMS encryption", ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// Возвращает заголовки SSE-C (алгоритм, ключ и MD5 ключа в base64) или nil, если ключ клиента не задан
+func (e Encryption) customerKeyHeaders() (algorithm, key, keyMD5 *string) {
+	if len(e.CustomerKey) == 0 {
+		return nil, nil, nil
+	}
+
+	sum := md5.Sum(e.CustomerKey)
+	return aws.String(sseCustomerAlgorithm),
+		aws.String(base64.StdEncoding.EncodeToString(e.CustomerKey)),
+		aws.String(base64.StdEncoding.EncodeToString(sum[:]))
+}
+
+// Устанавливает параметры шифрования для загружаемого объекта
+func (e Encryption) applyToPut(input *s3.PutObjectInput) {
+	input.ServerSideEncryption = e.Type
+	if e.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(e.KMSKeyID)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+}
+
+// Устанавливает параметры шифрования для копии объекта. Предполагается, что исходный объект зашифрован тем же ключом клиента (для SSE-C).
+func (e Encryption) applyToCopy(input *s3.CopyObjectInput) {
+	input.ServerSideEncryption = e.Type
+	if e.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(e.KMSKeyID)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+	input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5 = e.customerKeyHeaders()
+}
+
+// Устанавливает параметры шифрования для multipart-загрузки
+func (e Encryption) applyToCreateMultipart(input *s3.CreateMultipartUploadInput) {
+	input.ServerSideEncryption = e.Type
+	if e.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(e.KMSKeyID)
+	}
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+}
+
+// Устанавливает ключ клиента для загрузки части объекта, зашифрованного SSE-C
+func (e Encryption) applyToUploadPart(input *s3.UploadPartInput) {
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+}
+
+// Устанавливает ключ клиента для копирования части объекта при multipart-копировании
+func (e Encryption) applyToUploadPartCopy(input *s3.UploadPartCopyInput) {
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+	input.CopySourceSSECustomerAlgorithm, input.CopySourceSSECustomerKey, input.CopySourceSSECustomerKeyMD5 = e.customerKeyHeaders()
+}
+
+// Устанавливает ключ клиента для чтения объекта, зашифрованного SSE-C
+func (e Encryption) applyToGet(input *s3.GetObjectInput) {
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+}
+
+// Устанавливает ключ клиента для запроса S3 Select к объекту, зашифрованному SSE-C
+func (e Encryption) applyToSelect(input *s3.SelectObjectContentInput) {
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+}
+
+// Устанавливает ключ клиента для получения метаданных объекта, зашифрованного SSE-C
+func (e Encryption) applyToHead(input *s3.HeadObjectInput) {
+	input.SSECustomerAlgorithm, input.SSECustomerKey, input.SSECustomerKeyMD5 = e.customerKeyHeaders()
+}
@@ -0,0 +1,86 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Метод для удаления всех файлов сущности в каталоге catalogType (например, всех файлов товара при его удалении), включая вложенные каталоги.
+// Каталог должен быть зарегистрирован и содержать %d, за которым сразу идёт "/", иначе возвращается ErrUnknownCatalog или ErrInvalidInput:
+// удаление по неполному пути затронуло бы файлы других сущностей. Корзина и опции действуют так же, как в DeleteFiles. Возвращает количество удалённых объектов.
+func (r *s3Manager) DeleteEntity(ctx context.Context, catalogType CatalogType, entityID int64, opts ...Option) (int, error) {
+	deleted, err := r.DeleteEntityCatalogs(ctx, entityID, []CatalogType{catalogType}, opts...)
+	if err != nil {
+		return deleted, fmt.Errorf("DeleteEntity/DeleteEntityCatalogs: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// Метод для удаления всех файлов сущности в нескольких каталогах (например, "product" и "product_certificates", если сертификаты
+// хранятся отдельно от товара). Каталоги, вложенные в другие из списка, отдельно не обходятся. Все каталоги проверяются до удаления,
+// как в DeleteEntity. Если удалить файлы части каталогов не удалось, остальные всё равно удаляются и возвращается ошибка *BatchError
+// с ошибками по типам каталогов. Возвращает общее количество удалённых объектов.
+func (r *s3Manager) DeleteEntityCatalogs(ctx context.Context, entityID int64, catalogTypes []CatalogType, opts ...Option) (int, error) {
+	r = r.forCall(opts)
+
+	if len(catalogTypes) == 0 {
+		return 0, fmt.Errorf("DeleteEntityCatalogs: %w: no catalog types", ErrInvalidInput)
+	}
+
+	prefixes := make(map[CatalogType]string, len(catalogTypes))
+	for _, catalogType := range catalogTypes {
+		pathPattern, ok := r.catalogPattern(catalogType)
+		if !ok || catalogType == PathCustomCatalog {
+			return 0, fmt.Errorf("DeleteEntityCatalogs: %w: %q", ErrUnknownCatalog, catalogType)
+		}
+		if !strings.Contains(pathPattern, "%d") {
+			return 0, fmt.Errorf("DeleteEntityCatalogs: %w: catalog %q has no entity ID in pattern %q", ErrInvalidInput, catalogType, pathPattern)
+		}
+
+		prefix, err := r.objectKey(StoragePath{CatalogType: catalogType, EntityID: entityID}, "")
+		if err != nil {
+			return 0, fmt.Errorf("DeleteEntityCatalogs/objectKey: %w", err)
+		}
+		// Без "/" сразу после ID префикс "avatars/5" совпал бы и с файлами сущностей 50–59, 500 и т.д.
+		if _, afterID, _ := strings.Cut(pathPattern, "%d"); !strings.HasPrefix(afterID, "/") || !strings.HasSuffix(prefix, "/") {
+			return 0, fmt.Errorf("DeleteEntityCatalogs: %w: catalog %q pattern %q has no \"/\" after entity ID", ErrInvalidInput, catalogType, pathPattern)
+		}
+		prefixes[catalogType] = prefix
+	}
+
+	deleted := 0
+	failed := make(map[string]error)
+	for _, catalogType := range catalogTypes {
+		if nestedPrefix(prefixes[catalogType], prefixes, catalogType) {
+			continue // Файлы удаляются вместе с объемлющим каталогом
+		}
+
+		n, err := r.DeleteFiles(ctx, StoragePath{CatalogType: catalogType, EntityID: entityID}, "", opts...)
+		deleted += n
+		if err != nil {
+			failed[string(catalogType)] = err
+		}
+	}
+	if len(failed) > 0 {
+		return deleted, fmt.Errorf("DeleteEntityCatalogs: %w", &BatchError{Errors: failed})
+	}
+
+	return deleted, nil
+}
+
+// Проверяет, вложен ли префикс каталога catalogType в префикс другого каталога из списка. Из одинаковых префиксов
+// вложенными считаются все, кроме первого по имени типа каталога, чтобы каталог удалялся ровно один раз.
+func nestedPrefix(prefix string, prefixes map[CatalogType]string, catalogType CatalogType) bool {
+	for other, otherPrefix := range prefixes {
+		if other == catalogType || !strings.HasPrefix(prefix, otherPrefix) {
+			continue
+		}
+		if len(otherPrefix) < len(prefix) || other < catalogType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,78 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDeleteEntityCatalogsKeepsOtherEntities(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		catalogTypes []CatalogType
+		wantErr      error
+		wantDeleted  int
+		wantKeys     []string
+	}{
+		{
+			name:         "entity catalogs",
+			catalogTypes: []CatalogType{"product", "product_certificates"},
+			wantDeleted:  2,
+			wantKeys:     []string{"avatars/1.jpg", "avatars/10.jpg", "products/10/certificates/cert.pdf", "products/10/photo.jpg"},
+		},
+		{
+			name:         "no slash after entity ID",
+			catalogTypes: []CatalogType{"product", "avatar"},
+			wantErr:      ErrInvalidInput,
+			wantKeys:     []string{"avatars/1.jpg", "avatars/10.jpg", "products/1/certificates/cert.pdf", "products/1/photo.jpg", "products/10/certificates/cert.pdf", "products/10/photo.jpg"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+			manager.AddCatalog("product", "products/%d/")
+			manager.AddCatalog("product_certificates", "products/%d/certificates/")
+			manager.AddCatalog("avatar", "avatars/%d")
+			for _, entityID := range []int64{1, 10} {
+				files := []struct {
+					storagePath StoragePath
+					name        string
+				}{
+					{StoragePath{CatalogType: "product", EntityID: entityID}, "photo.jpg"},
+					{StoragePath{CatalogType: "product_certificates", EntityID: entityID}, "cert.pdf"},
+					{StoragePath{CatalogType: "avatar", EntityID: entityID}, ".jpg"},
+				}
+				for _, file := range files {
+					_, err := manager.PutFile(ctx, file.storagePath, &BucketFile{Name: file.name, File: bytes.NewReader([]byte("content"))}, withRawName())
+					if err != nil {
+						t.Fatalf("PutFile(%d, %q): %v", entityID, file.name, err)
+					}
+				}
+			}
+
+			deleted, err := manager.DeleteEntityCatalogs(ctx, 1, tt.catalogTypes)
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) || tt.wantErr == nil && err != nil {
+				t.Fatalf("DeleteEntityCatalogs error = %v, want %v", err, tt.wantErr)
+			}
+			if deleted != tt.wantDeleted {
+				t.Errorf("deleted = %d, want %d", deleted, tt.wantDeleted)
+			}
+
+			objects, err := manager.ListObjects(ctx, "")
+			if err != nil {
+				t.Fatalf("ListObjects: %v", err)
+			}
+			var keys []string
+			for _, object := range objects {
+				keys = append(keys, object.Key)
+			}
+			if !slices.Equal(keys, tt.wantKeys) {
+				t.Errorf("remaining keys = %v, want %v", keys, tt.wantKeys)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package s3_manager
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// Типы ошибок библиотеки. Ошибки S3 оборачиваются в StorageError с одним из этих типов, поэтому их можно проверять через errors.Is,
+// а исходную ошибку AWS по-прежнему можно получить через errors.As.
+var (
+	ErrObjectNotFound     = errors.New("object not found")
+	ErrBucketNotFound     = errors.New("bucket not found")
+	ErrAccessDenied       = errors.New("access denied")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrChecksumMismatch   = errors.New("checksum mismatch")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrNotModified        = errors.New("not modified")
+	ErrFileTooLarge       = errors.New("file too large")
+	ErrFileTypeNotAllowed = errors.New("file type not allowed")
+	ErrInvalidImage       = errors.New("invalid image")
+	ErrInvalidConfig      = errors.New("invalid config")
+	ErrUnavailable        = errors.New("storage unavailable")
+	ErrUnknownCatalog     = errors.New("unknown catalog type")
+	ErrReadOnly           = errors.New("manager is read-only")
+)
+
+// Ошибка хранилища с типом (одна из ошибок ErrObjectNotFound, ErrBucketNotFound и т.д.) и исходной ошибкой AWS
+type StorageError struct {
+	Kind error // Тип ошибки
+	Err  error // Исходная ошибка
+}
+
+func (e *StorageError) Error() string {
+	return e.Err.Error()
+}
+
+// Позволяет проверять как тип ошибки (errors.Is(err, ErrObjectNotFound)), так и исходную ошибку AWS (errors.As(err, &apiErr))
+func (e *StorageError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Коды ошибок S3, соответствующие типам ошибок библиотеки
+var errorCodeKinds = map[string]error{
+	"NoSuchKey":                 ErrObjectNotFound,
+	"NotFound":                  ErrObjectNotFound, // HeadObject возвращает ошибку без тела ответа, только с кодом
+	"NoSuchVersion":             ErrObjectNotFound,
+	"NoSuchBucket":              ErrBucketNotFound,
+	"AccessDenied":              ErrAccessDenied,
+	"Forbidden":                 ErrAccessDenied,
+	"AllAccessDisabled":         ErrAccessDenied,
+	"InvalidAccessKeyId":        ErrAccessDenied,
+	"SignatureDoesNotMatch":     ErrAccessDenied,
+	"InvalidArgument":           ErrInvalidInput,
+	"InvalidRequest":            ErrInvalidInput,
+	"InvalidBucketName":         ErrInvalidInput,
+	"InvalidObjectName":         ErrInvalidInput,
+	"KeyTooLongError":           ErrInvalidInput,
+	"InvalidRange":              ErrInvalidInput,
+	"MalformedXML":              ErrInvalidInput,
+	"BadDigest":                 ErrChecksumMismatch,
+	"XAmzContentSHA256Mismatch": ErrChecksumMismatch,
+	"PreconditionFailed":        ErrPreconditionFailed,
+	"NotModified":               ErrNotModified,
+}
+
+// Определяет тип ошибки S3 и оборачивает её в StorageError. Ошибки соединения и ответы 5xx относятся к ErrUnavailable,
+// неизвестные ошибки (например, отмена контекста) возвращаются без изменений.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var storageErr *StorageError
+	if errors.As(err, &storageErr) {
+		return err // Ошибка уже классифицирована
+	}
+
+	kind := errorKind(err)
+	if kind == nil {
+		return err
+	}
+
+	return &StorageError{Kind: kind, Err: err}
+}
+
+// Возвращает тип ошибки S3 по типу ошибки SDK, коду ошибки или HTTP-статусу ответа
+func errorKind(err error) error {
+	var noSuchKey *types.NoSuchKey
+	var notFound *types.NotFound
+	var noSuchBucket *types.NoSuchBucket
+	switch {
+	case errors.As(err, &noSuchKey), errors.As(err, &notFound):
+		return ErrObjectNotFound
+	case errors.As(err, &noSuchBucket):
+		return ErrBucketNotFound
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if kind, ok := errorCodeKinds[apiErr.ErrorCode()]; ok {
+			return kind
+		}
+	}
+
+	var responseErr *smithyhttp.ResponseError
+	if errors.As(err, &responseErr) {
+		switch responseErr.HTTPStatusCode() {
+		case http.StatusNotFound:
+			return ErrObjectNotFound
+		case http.StatusForbidden, http.StatusUnauthorized:
+			return ErrAccessDenied
+		case http.StatusBadRequest:
+			return ErrInvalidInput
+		case http.StatusPreconditionFailed:
+			return ErrPreconditionFailed
+		case http.StatusNotModified:
+			return ErrNotModified
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return ErrUnavailable
+		}
+	}
+
+	// Запрос не дошёл до хранилища: ошибка DNS, отказ в соединении, обрыв TLS-рукопожатия и т.п.
+	var sendErr *smithyhttp.RequestSendError
+	if errors.As(err, &sendErr) {
+		return ErrUnavailable
+	}
+
+	return nil
+}
+
+// Возвращает код ошибки API S3 (например, "NoSuchLifecycleConfiguration") или пустую строку, если ошибка не от API
+func apiErrorCode(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode()
+	}
+	return ""
+}
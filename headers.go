@@ -0,0 +1,50 @@
+package s3_manager
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// HTTP-заголовки, которые хранилище и CDN отдают вместе с файлом
+type ObjectHeaders struct {
+	CacheControl       string // Правила кеширования в браузере и CDN (например, "public, max-age=31536000, immutable" для файлов с уникальными именами)
+	ContentDisposition string // Способ отображения файла (например, "attachment" — скачивание вместо открытия в браузере)
+	ContentLanguage    string // Язык содержимого файла (например, "ru")
+}
+
+// Метод для добавления нового типа каталога с паттерном пути в бакете и HTTP-заголовками загружаемых в него файлов по умолчанию
+// (например, долгое кеширование в CDN для каталога изображений). Заголовки отдельного файла можно изменить опциями
+// WithCacheControl, WithContentDisposition и WithContentLanguage.
+func (r *s3Manager) AddCatalogWithHeaders(catalogType CatalogType, pathPattern string, headers ObjectHeaders) {
+	r.AddCatalog(catalogType, pathPattern)
+
+	r.catalogMu.Lock()
+	defer r.catalogMu.Unlock()
+
+	r.catalogHeaders[catalogType] = headers
+}
+
+// Определяет заголовки загружаемого файла: каждый заголовок берётся из опции вызова или из заголовков каталога по умолчанию
+func (r *s3Manager) uploadHeaders(storagePath StoragePath, o operationOptions) ObjectHeaders {
+	r.catalogMu.RLock()
+	headers := r.catalogHeaders[storagePath.CatalogType]
+	r.catalogMu.RUnlock()
+
+	if o.headers.CacheControl != "" {
+		headers.CacheControl = o.headers.CacheControl
+	}
+	if o.headers.ContentDisposition != "" {
+		headers.ContentDisposition = o.headers.ContentDisposition
+	}
+	if o.headers.ContentLanguage != "" {
+		headers.ContentLanguage = o.headers.ContentLanguage
+	}
+
+	return headers
+}
+
+// Добавляет заголовки в запрос загрузки объекта
+func (h ObjectHeaders) applyToPut(input *s3.PutObjectInput) {
+	input.CacheControl = nonEmpty(h.CacheControl)
+	input.ContentDisposition = nonEmpty(h.ContentDisposition)
+	input.ContentLanguage = nonEmpty(h.ContentLanguage)
+}
@@ -0,0 +1,42 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Метод для проверки доступности хранилища и бакета (например, в readiness-пробе сервиса). Выполняет HeadBucket
+// (для хранилищ, отличных от S3, — получение одного объекта из списка). Тип ошибки можно проверить через errors.Is:
+// ErrAccessDenied — неверные учётные данные или нет доступа к бакету, ErrBucketNotFound — бакета нет,
+// ErrUnavailable — хранилище недоступно по сети или отвечает ошибкой 5xx.
+func (r *s3Manager) Ping(ctx context.Context) error {
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	client, err := r.s3Client()
+	if err != nil {
+		_, err = r.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:  &r.cfg.Name,
+			MaxKeys: aws.Int32(1),
+		})
+		if err != nil {
+			return fmt.Errorf("Ping/ListObjectsV2: %w", classifyError(err))
+		}
+		return nil
+	}
+
+	_, err = client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: &r.cfg.Name})
+	if err != nil {
+		classified := classifyError(err)
+		if errors.Is(classified, ErrObjectNotFound) { // HeadBucket возвращает 404 без кода ошибки
+			classified = &StorageError{Kind: ErrBucketNotFound, Err: err}
+		}
+		return fmt.Errorf("Ping/HeadBucket: %w", classified)
+	}
+
+	return nil
+}
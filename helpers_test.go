@@ -0,0 +1,89 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func putInput(bucket, key, content string) *s3.PutObjectInput {
+	return &s3.PutObjectInput{Bucket: aws.String(bucket), Key: aws.String(key), Body: bytes.NewReader([]byte(content))}
+}
+
+func getInput(bucket, key string) *s3.GetObjectInput {
+	return &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)}
+}
+
+func newTestManager(t *testing.T, cfg *Config, isTestServer bool) (*s3Manager, StorageBackend) {
+	t.Helper()
+
+	backend := NewMemoryBackend()
+	return newTestManagerWithBackend(t, backend, cfg, isTestServer), backend
+}
+
+func newTestManagerWithBackend(t *testing.T, backend StorageBackend, cfg *Config, isTestServer bool) *s3Manager {
+	t.Helper()
+
+	manager, err := newS3Manager(backend, cfg, isTestServer)
+	if err != nil {
+		t.Fatalf("newS3Manager: %v", err)
+	}
+
+	return manager
+}
+
+// Хранилище в памяти, которое запоминает ACL объектов так, как их назначает S3: ACL из запроса или private, если ACL не передан
+// (при копировании ACL исходного объекта не сохраняется)
+type aclBackend struct {
+	StorageBackend
+	mu   sync.Mutex
+	acls map[string]types.ObjectCannedACL
+}
+
+func newACLBackend() *aclBackend {
+	return &aclBackend{StorageBackend: NewMemoryBackend(), acls: make(map[string]types.ObjectCannedACL)}
+}
+
+func (b *aclBackend) setACL(key *string, acl types.ObjectCannedACL) {
+	if acl == "" {
+		acl = types.ObjectCannedACLPrivate
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.acls[aws.ToString(key)] = acl
+}
+
+func (b *aclBackend) acl(key string) types.ObjectCannedACL {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.acls[key]
+}
+
+func (b *aclBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	output, err := b.StorageBackend.PutObject(ctx, params, optFns...)
+	if err == nil {
+		b.setACL(params.Key, params.ACL)
+	}
+	return output, err
+}
+
+func (b *aclBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	output, err := b.StorageBackend.CopyObject(ctx, params, optFns...)
+	if err == nil {
+		b.setACL(params.Key, params.ACL)
+	}
+	return output, err
+}
+
+func (b *aclBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	output, err := b.StorageBackend.CreateMultipartUpload(ctx, params, optFns...)
+	if err == nil {
+		b.setACL(params.Key, params.ACL)
+	}
+	return output, err
+}
@@ -0,0 +1,88 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+)
+
+// Метод для загрузки видео в формате HLS: мастер-плейлиста (.m3u8) и всех файлов, на которые он ссылается (плейлистов качеств и сегментов .ts).
+// Имена файлов задаются относительно каталога storagePath и могут содержать подкаталоги (например, "720p/segment0.ts") — так же, как ссылки в плейлистах.
+// Сначала параллельно загружаются сегменты (см. WithConcurrency), затем мастер-плейлист, поэтому по ссылке на него видео доступно только целиком.
+// Если какой-либо файл загрузить не удалось, удаляются загруженные файлы, которых не было в бакете до вызова: файлы прежней загрузки
+// по тому же пути не удаляются (перезаписанные к этому моменту файлы содержат новые данные). Возвращает ссылку на мастер-плейлист.
+func (r *s3Manager) PutHLSAsset(ctx context.Context, storagePath StoragePath, masterPlaylist BucketFile, segments []BucketFile, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if masterPlaylist.File == nil || !fs.ValidPath(masterPlaylist.Name) || masterPlaylist.Name == "." {
+		return "", fmt.Errorf("PutHLSAsset: %w: invalid master playlist", ErrInvalidInput)
+	}
+	for _, segment := range segments {
+		// Имена сохраняются как есть, поэтому пути вида "../file" не должны выходить за пределы каталога
+		if segment.File == nil || !fs.ValidPath(segment.Name) || segment.Name == "." {
+			return "", fmt.Errorf("PutHLSAsset: %w: invalid segment %q", ErrInvalidInput, segment.Name)
+		}
+	}
+
+	o := r.applyOptions(opts)
+	putOpts := append([]Option{withRawName()}, opts...) // Имена файлов должны совпадать со ссылками в плейлистах
+
+	var (
+		mu       sync.Mutex
+		uploaded []string // Новые файлы, которые удаляются при ошибке
+		failed   = make(map[string]error)
+	)
+
+	tasks := r.newBatch(o.concurrency)
+	for i := range segments {
+		tasks.Go(func() {
+			existed, err := r.FileExists(ctx, storagePath, segments[i].Name, opts...)
+			if err == nil {
+				_, err = r.PutFile(ctx, storagePath, &segments[i], putOpts...)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[segments[i].Name] = err
+				return
+			}
+			if !existed {
+				key, _ := r.objectKey(storagePath, segments[i].Name) // Путь уже проверен в PutFile
+				uploaded = append(uploaded, key)
+			}
+		})
+	}
+	tasks.Wait()
+
+	if len(failed) > 0 {
+		err := fmt.Errorf("PutHLSAsset: %w", &BatchError{Errors: failed})
+		return "", errors.Join(err, r.rollbackUploads(ctx, uploaded))
+	}
+
+	masterURL, err := r.PutFile(ctx, storagePath, &masterPlaylist, putOpts...)
+	if err != nil {
+		err = fmt.Errorf("PutHLSAsset/PutFile: %w", err)
+		return "", errors.Join(err, r.rollbackUploads(ctx, uploaded))
+	}
+
+	return masterURL, nil
+}
+
+// Удаляет новые файлы, загруженные до ошибки. Выполняется и после отмены контекста вызова, чтобы не оставлять в бакете неполный набор файлов.
+func (r *s3Manager) rollbackUploads(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	_, err := r.deleteObjects(ctx, keys)
+	if err != nil {
+		return fmt.Errorf("rollbackUploads/deleteObjects: %w", err)
+	}
+	r.invalidate(ctx, keys...)
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+)
+
+// Поток, чтение которого завершается ошибкой
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestPutHLSAssetRollback(t *testing.T) {
+	segment := func(name string) BucketFile {
+		return BucketFile{Name: name, File: bytes.NewReader([]byte(name))}
+	}
+	master := func() BucketFile {
+		return BucketFile{Name: "master.m3u8", File: bytes.NewReader([]byte("#EXTM3U"))}
+	}
+
+	tests := []struct {
+		name     string
+		existing []string // Файлы прежней загрузки по тому же пути
+		wantLeft []string
+	}{
+		{"new asset is removed", nil, nil},
+		{"previous asset survives failed re-upload", []string{"720p/0.ts", "720p/1.ts", "master.m3u8"}, []string{"video/720p/0.ts", "video/720p/1.ts", "video/master.m3u8"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			manager, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b", RootCatalog: "video"}, false)
+
+			if tt.existing != nil {
+				var segments []BucketFile
+				for _, name := range tt.existing[:len(tt.existing)-1] {
+					segments = append(segments, segment(name))
+				}
+				if _, err := manager.PutHLSAsset(ctx, StoragePath{}, master(), segments); err != nil {
+					t.Fatalf("PutHLSAsset: %v", err)
+				}
+			}
+
+			segments := []BucketFile{segment("720p/0.ts"), segment("720p/1.ts"), {Name: "720p/2.ts", File: io.MultiReader(bytes.NewReader([]byte("x")), failingReader{})}}
+			_, err := manager.PutHLSAsset(ctx, StoragePath{}, master(), segments, WithConcurrency(1))
+			var batchErr *BatchError
+			if !errors.As(err, &batchErr) {
+				t.Fatalf("PutHLSAsset: err = %v, want *BatchError", err)
+			}
+
+			objects, err := manager.ListObjects(ctx, "")
+			if err != nil {
+				t.Fatalf("ListObjects: %v", err)
+			}
+			var left []string
+			for _, object := range objects {
+				left = append(left, object.Key)
+			}
+			if !slices.Equal(left, tt.wantLeft) {
+				t.Errorf("objects = %v, want %v", left, tt.wantLeft)
+			}
+		})
+	}
+}
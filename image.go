@@ -0,0 +1,368 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // Декодирование GIF
+	"image/jpeg"
+	"image/png"
+	"io"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Декодирование WebP
+)
+
+const (
+	OriginalImage      = "original" // Ключ исходного изображения в результате PutImage
+	defaultJPEGQuality = 85         // Качество JPEG по умолчанию
+)
+
+// Формат, в котором сохраняются обработанные изображения. Библиотека содержит JPEGEncoder, PNGEncoder и WebPEncoder;
+// для других форматов (например, AVIF) можно реализовать интерфейс поверх сторонней библиотеки кодирования.
+type ImageEncoder interface {
+	Encode(w io.Writer, img image.Image) error
+	Extension() string   // Расширение файлов в этом формате (например, ".webp")
+	ContentType() string // MIME-тип файлов в этом формате (например, "image/webp")
+}
+
+// Сохранение изображений в JPEG
+type JPEGEncoder struct {
+	Quality int // Качество от 1 до 100. По умолчанию 85.
+}
+
+func (e JPEGEncoder) Encode(w io.Writer, img image.Image) error {
+	quality := e.Quality
+	if quality <= 0 {
+		quality = defaultJPEGQuality
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+func (JPEGEncoder) Extension() string   { return ".jpg" }
+func (JPEGEncoder) ContentType() string { return "image/jpeg" }
+
+// Сохранение изображений в PNG
+type PNGEncoder struct{}
+
+func (PNGEncoder) Encode(w io.Writer, img image.Image) error { return png.Encode(w, img) }
+func (PNGEncoder) Extension() string                         { return ".png" }
+func (PNGEncoder) ContentType() string                       { return "image/png" }
+
+// Сохранение изображений в WebP без потерь (VP8L). Кодирование на чистом Go, без cgo и libwebp.
+// Сжатия с потерями нет: для фотографий файлы обычно больше, чем в JPEG, но меньше, чем в PNG.
+type WebPEncoder struct{}
+
+func (WebPEncoder) Encode(w io.Writer, img image.Image) error { return nativewebp.Encode(w, img, nil) }
+func (WebPEncoder) Extension() string                         { return ".webp" }
+func (WebPEncoder) ContentType() string                       { return "image/webp" }
+
+// Вариант изображения (например, превью), который создаётся при загрузке исходного изображения
+type ImageVariant struct {
+	Name   string // Имя варианта: ключ в результате PutImage и суффикс имени файла (например, "thumb" -> "photo_thumb.jpg")
+	Width  int    // Максимальная ширина в пикселях (0 - не ограничена)
+	Height int    // Максимальная высота в пикселях (0 - не ограничена)
+	Crop   bool   // Обрезать изображение по центру до пропорций Width x Height вместо вписывания в них целиком
+}
+
+// Обработка изображений, загружаемых в каталог через PutImage (см. AddImageCatalog)
+// Файлы, загружаемые в такой каталог через PutFile и PutImage, проверяются по заголовку изображения: формат и размеры в пикселях.
+type ImageConfig struct {
+	Variants       []ImageVariant // Варианты изображения, которые создаются и загружаются вместе с исходным. Изображения не увеличиваются.
+	StripMetadata  bool           // Перекодировать исходное изображение, чтобы удалить метаданные (EXIF, в том числе координаты GPS). Поворот из EXIF применяется к изображению.
+	Encoder        ImageEncoder   // Формат вариантов и перекодированного исходного изображения. По умолчанию JPEG для JPEG-изображений, для остальных — PNG.
+	AllowedFormats []string       // Допустимые форматы изображений: "jpeg", "png", "gif", "webp". По умолчанию допустимы все.
+	MinWidth       int            // Минимальная ширина в пикселях (0 - без ограничений)
+	MinHeight      int            // Минимальная высота в пикселях (0 - без ограничений)
+	MaxWidth       int            // Максимальная ширина в пикселях (0 - без ограничений). Защищает от изображений, которые занимают слишком много памяти при обработке.
+	MaxHeight      int            // Максимальная высота в пикселях (0 - без ограничений)
+}
+
+// Метод для добавления нового типа каталога с паттерном пути в бакете и обработкой загружаемых в него изображений (см. PutImage)
+func (r *s3Manager) AddImageCatalog(catalogType CatalogType, pathPattern string, cfg ImageConfig) {
+	r.AddCatalog(catalogType, pathPattern)
+
+	r.catalogMu.Lock()
+	defer r.catalogMu.Unlock()
+
+	r.imageCatalogs[catalogType] = cfg
+}
+
+// Возвращает настройки обработки изображений каталога и признак того, что каталог зарегистрирован через AddImageCatalog
+func (r *s3Manager) imageCatalog(catalogType CatalogType) (ImageConfig, bool) {
+	r.catalogMu.RLock()
+	defer r.catalogMu.RUnlock()
+
+	cfg, ok := r.imageCatalogs[catalogType]
+
+	return cfg, ok
+}
+
+// Метод для загрузки изображения (JPEG, PNG, GIF или WebP) с обработкой по настройкам каталога (см. AddImageCatalog): исходное изображение
+// при необходимости перекодируется, а его варианты создаются и загружаются параллельно под именами вида "photo_thumb.jpg".
+// Опции применяются к каждому загружаемому файлу так же, как в PutFile. Возвращает ссылки на файлы по именам вариантов
+// (исходное изображение — по ключу OriginalImage). Если часть вариантов загрузить не удалось, возвращаются ссылки на остальные и ошибка *BatchError.
+func (r *s3Manager) PutImage(ctx context.Context, storagePath StoragePath, data *BucketFile, opts ...Option) (map[string]string, error) {
+	r = r.forCall(opts)
+
+	if data == nil || data.File == nil || data.Name == "" {
+		return nil, fmt.Errorf("PutImage: %w: invalid file data", ErrInvalidInput)
+	}
+
+	o := r.applyOptions(opts)
+	cfg, _ := r.imageCatalog(storagePath.CatalogType)
+
+	content, err := io.ReadAll(&sizeLimitReader{reader: data.File, limit: r.maxUploadSize(r.uploadRules(storagePath), o)})
+	if err != nil {
+		return nil, fmt.Errorf("PutImage/ReadAll: %w", err)
+	}
+	// Заголовок проверяется до декодирования, чтобы не распаковывать в память изображения недопустимого размера
+	_, err = cfg.validate(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("PutImage/validate: %w", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("PutImage/Decode: %w", &ImageValidationError{Problems: []ImageProblem{ImageCorrupted}, Err: err})
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(content))
+	}
+
+	encoder := cfg.Encoder
+	if encoder == nil {
+		encoder = ImageEncoder(PNGEncoder{})
+		if format == "jpeg" {
+			encoder = JPEGEncoder{}
+		}
+	}
+
+	name, originalOpts := data.Name, append(slices.Clip(opts), withValidatedImage())
+	if cfg.StripMetadata || cfg.Encoder != nil {
+		var buf bytes.Buffer
+		err = encoder.Encode(&buf, img)
+		if err != nil {
+			return nil, fmt.Errorf("PutImage/Encode: %w", err)
+		}
+		content = buf.Bytes()
+		name = strings.TrimSuffix(name, path.Ext(name)) + encoder.Extension()
+		originalOpts = append(originalOpts, WithContentType(encoder.ContentType()))
+	}
+
+	var storedName string
+	originalURL, err := r.PutFile(ctx, storagePath, &BucketFile{File: bytes.NewReader(content), Name: name}, append(originalOpts, WithStoredName(&storedName))...)
+	if err != nil {
+		return nil, fmt.Errorf("PutImage/PutFile: %w", err)
+	}
+	if o.storedName != nil {
+		*o.storedName = storedName
+	}
+
+	var (
+		mu     sync.Mutex
+		urls   = map[string]string{OriginalImage: originalURL}
+		failed = make(map[string]error)
+		base   = strings.TrimSuffix(storedName, path.Ext(storedName))
+	)
+
+	tasks := r.newBatch(o.concurrency)
+	for _, variant := range cfg.Variants {
+		tasks.Go(func() {
+			variantURL, err := r.putImageVariant(ctx, storagePath, img, variant, base+"_"+variant.Name+encoder.Extension(), encoder, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[variant.Name] = err
+				return
+			}
+			urls[variant.Name] = variantURL
+		})
+	}
+	tasks.Wait()
+
+	if len(failed) > 0 {
+		return urls, fmt.Errorf("PutImage: %w", &BatchError{Errors: failed})
+	}
+
+	return urls, nil
+}
+
+// Создаёт и загружает вариант изображения под именем fileName
+func (r *s3Manager) putImageVariant(ctx context.Context, storagePath StoragePath, img image.Image, variant ImageVariant, fileName string, encoder ImageEncoder, opts []Option) (string, error) {
+	var buf bytes.Buffer
+	err := encoder.Encode(&buf, resizeImage(img, variant))
+	if err != nil {
+		return "", fmt.Errorf("putImageVariant/Encode: %w", err)
+	}
+
+	// Имя варианта уже сформировано из сохранённого имени исходного изображения, поэтому повторно не очищается и не заменяется уникальным
+	variantOpts := append(slices.Clip(opts), withRawName(), withValidatedImage(), WithContentType(encoder.ContentType()), WithStoredName(nil))
+	variantURL, err := r.PutFile(ctx, storagePath, &BucketFile{File: &buf, Name: fileName}, variantOpts...)
+	if err != nil {
+		return "", fmt.Errorf("putImageVariant/PutFile: %w", err)
+	}
+
+	return variantURL, nil
+}
+
+// Уменьшает изображение до размеров варианта: вписывает в Width x Height или (при Crop) обрезает по центру до их пропорций.
+// Изображения меньше заданных размеров не увеличиваются.
+func resizeImage(img image.Image, variant ImageVariant) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if variant.Crop && variant.Width > 0 && variant.Height > 0 {
+		cropWidth, cropHeight := width, width*variant.Height/variant.Width
+		if cropHeight > height {
+			cropWidth, cropHeight = height*variant.Width/variant.Height, height
+		}
+		x := bounds.Min.X + (width-cropWidth)/2
+		y := bounds.Min.Y + (height-cropHeight)/2
+		return scaleImage(img, image.Rect(x, y, x+cropWidth, y+cropHeight), min(variant.Width, cropWidth), min(variant.Height, cropHeight))
+	}
+
+	scale := 1.0
+	if variant.Width > 0 {
+		scale = min(scale, float64(variant.Width)/float64(width))
+	}
+	if variant.Height > 0 {
+		scale = min(scale, float64(variant.Height)/float64(height))
+	}
+	if scale == 1 {
+		return img
+	}
+
+	return scaleImage(img, bounds, max(1, int(math.Round(float64(width)*scale))), max(1, int(math.Round(float64(height)*scale))))
+}
+
+// Масштабирует область src изображения до размеров width x height
+func scaleImage(img image.Image, src image.Rectangle, width, height int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, src, xdraw.Src, nil)
+	return dst
+}
+
+// Возвращает ориентацию изображения (тег EXIF Orientation, значения 1–8) из содержимого JPEG-файла или 1, если её нет
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		if marker == 0xFF {
+			pos++ // Байт-заполнитель перед маркером
+			continue
+		}
+		if marker == 0xDA || marker == 0xD9 {
+			return 1 // Начались данные изображения: метаданные располагаются до них
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+
+	return 1
+}
+
+// Находит тег Orientation в первом каталоге (IFD0) данных EXIF в формате TIFF
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8:]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// Поворачивает и отражает изображение так, как его показывают просмотрщики с учётом ориентации EXIF
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width // Повороты на 90° и отражения относительно диагоналей меняют ширину и высоту местами
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // Отражение по горизонтали
+				sx, sy = width-1-x, y
+			case 3: // Поворот на 180°
+				sx, sy = width-1-x, height-1-y
+			case 4: // Отражение по вертикали
+				sx, sy = x, height-1-y
+			case 5: // Отражение относительно главной диагонали
+				sx, sy = y, x
+			case 6: // Поворот на 90° по часовой стрелке
+				sx, sy = y, height-1-x
+			case 7: // Отражение относительно побочной диагонали
+				sx, sy = width-1-y, height-1-x
+			case 8: // Поворот на 90° против часовой стрелки
+				sx, sy = width-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,107 @@
+package s3_manager
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Причина, по которой изображение не прошло проверку (см. ImageValidationError)
+type ImageProblem string
+
+const (
+	ImageUnknownFormat    ImageProblem = "unknown_format"     // Содержимое не является изображением поддерживаемого формата
+	ImageCorrupted        ImageProblem = "corrupted"          // Заголовок изображения корректен, но данные повреждены
+	ImageFormatNotAllowed ImageProblem = "format_not_allowed" // Формат не входит в ImageConfig.AllowedFormats
+	ImageTooNarrow        ImageProblem = "too_narrow"         // Ширина меньше ImageConfig.MinWidth
+	ImageTooShort         ImageProblem = "too_short"          // Высота меньше ImageConfig.MinHeight
+	ImageTooWide          ImageProblem = "too_wide"           // Ширина больше ImageConfig.MaxWidth
+	ImageTooTall          ImageProblem = "too_tall"           // Высота больше ImageConfig.MaxHeight
+)
+
+// Ошибка проверки изображения, загружаемого в каталог изображений. Содержит фактические формат и размеры изображения
+// и все найденные нарушения, например, для вывода пользователю. Проверяется через errors.Is(err, ErrInvalidImage) или errors.As.
+type ImageValidationError struct {
+	Format   string         // Фактический формат изображения ("jpeg", "png" и т.д.); пустой, если формат не распознан
+	Width    int            // Ширина изображения в пикселях
+	Height   int            // Высота изображения в пикселях
+	Problems []ImageProblem // Найденные нарушения
+	Err      error          // Ошибка декодирования изображения, если она есть
+}
+
+func (e *ImageValidationError) Error() string {
+	problems := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		problems = append(problems, string(problem))
+	}
+
+	message := fmt.Sprintf("%v: %s", ErrInvalidImage, strings.Join(problems, ", "))
+	if e.Format != "" {
+		message += fmt.Sprintf(" (%s %dx%d)", e.Format, e.Width, e.Height)
+	}
+	if e.Err != nil {
+		message += ": " + e.Err.Error()
+	}
+
+	return message
+}
+
+func (e *ImageValidationError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{ErrInvalidImage, e.Err}
+	}
+	return []error{ErrInvalidImage}
+}
+
+// Проверяет формат и размеры изображения по его заголовку. Возвращает поток, из которого нужно загружать файл:
+// позиция в потоке с поддержкой Seek возвращается на место, а для потока без Seek прочитанный заголовок добавляется перед остатком потока.
+func (c ImageConfig) validate(body io.Reader) (io.Reader, error) {
+	var (
+		config image.Config
+		format string
+		err    error
+	)
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		current, seekErr := seeker.Seek(0, io.SeekCurrent)
+		if seekErr != nil {
+			return nil, fmt.Errorf("validate/Seek: %w", seekErr)
+		}
+		config, format, err = image.DecodeConfig(seeker)
+		_, seekErr = seeker.Seek(current, io.SeekStart)
+		if seekErr != nil {
+			return nil, fmt.Errorf("validate/Seek: %w", seekErr)
+		}
+	} else {
+		var head bytes.Buffer
+		config, format, err = image.DecodeConfig(io.TeeReader(body, &head))
+		body = io.MultiReader(&head, body)
+	}
+	if err != nil {
+		return nil, &ImageValidationError{Problems: []ImageProblem{ImageUnknownFormat}, Err: err}
+	}
+
+	var problems []ImageProblem
+	if len(c.AllowedFormats) > 0 && !slices.Contains(c.AllowedFormats, format) {
+		problems = append(problems, ImageFormatNotAllowed)
+	}
+	if c.MinWidth > 0 && config.Width < c.MinWidth {
+		problems = append(problems, ImageTooNarrow)
+	}
+	if c.MinHeight > 0 && config.Height < c.MinHeight {
+		problems = append(problems, ImageTooShort)
+	}
+	if c.MaxWidth > 0 && config.Width > c.MaxWidth {
+		problems = append(problems, ImageTooWide)
+	}
+	if c.MaxHeight > 0 && config.Height > c.MaxHeight {
+		problems = append(problems, ImageTooTall)
+	}
+	if len(problems) > 0 {
+		return nil, &ImageValidationError{Format: format, Width: config.Width, Height: config.Height, Problems: problems}
+	}
+
+	return body, nil
+}
@@ -0,0 +1,93 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Сбрасывает кеш CDN для изменённых или удалённых файлов. Вызывается автоматически после загрузки, копирования и удаления файлов, если задан Config.Invalidator.
+// Пути передаются в формате пути ссылки CDN (например, "/myservice/users/1/avatar.png"); путь, оканчивающийся на "*", означает все файлы с этим префиксом.
+type Invalidator interface {
+	Invalidate(ctx context.Context, paths []string) error
+}
+
+// Сброс кеша через вебхук: отправляет POST-запрос с JSON {"paths": [...]} на указанный адрес.
+// Подходит для CDN без поддержки CloudFront API (обработчик вебхука вызывает API своего CDN).
+type WebhookInvalidator struct {
+	URL     string            // Адрес вебхука
+	Headers map[string]string // Дополнительные заголовки запроса (например, токен авторизации)
+	Client  *http.Client      // HTTP-клиент. По умолчанию http.DefaultClient.
+}
+
+// Создаёт сброс кеша через вебхук
+func NewWebhookInvalidator(webhookURL string) *WebhookInvalidator {
+	return &WebhookInvalidator{URL: webhookURL}
+}
+
+// Отправляет пути на вебхук. Ответ со статусом не из диапазона 2xx считается ошибкой.
+func (i *WebhookInvalidator) Invalidate(ctx context.Context, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string][]string{"paths": paths})
+	if err != nil {
+		return fmt.Errorf("WebhookInvalidator.Invalidate/Marshal: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, i.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("WebhookInvalidator.Invalidate/NewRequest: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range i.Headers {
+		request.Header.Set(name, value)
+	}
+
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("WebhookInvalidator.Invalidate/Do: %w", err)
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("WebhookInvalidator.Invalidate: unexpected status %s", response.Status)
+	}
+
+	return nil
+}
+
+// Сбрасывает кеш CDN для объектов с указанными ключами (ключ, оканчивающийся на "*", означает префикс).
+// Ошибка сброса кеша не считается ошибкой операции с файлом (файл уже изменён), поэтому она только логируется.
+func (r *s3Manager) invalidate(ctx context.Context, keys ...string) {
+	if r.cfg.Invalidator == nil || len(keys) == 0 {
+		return
+	}
+
+	basePath := ""
+	if cdnURL, err := url.Parse(r.cfg.CDN); err == nil {
+		basePath = strings.TrimSuffix(cdnURL.Path, "/")
+	}
+
+	paths := make([]string, 0, len(keys))
+	for _, key := range keys {
+		paths = append(paths, basePath+"/"+key)
+	}
+
+	err := r.cfg.Invalidator.Invalidate(ctx, paths)
+	if err != nil && r.cfg.Logger != nil {
+		r.cfg.Logger.WarnContext(ctx, "cdn invalidation failed", "paths", paths, "error", err)
+	}
+}
@@ -0,0 +1,238 @@
+package s3_manager
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Манифест отчёта S3 Inventory (файл manifest.json в каталоге отчёта)
+type InventoryManifest struct {
+	SourceBucket      string                  `json:"sourceBucket"`      // Бакет, по которому составлен отчёт
+	DestinationBucket string                  `json:"destinationBucket"` // ARN бакета с отчётом
+	FileFormat        string                  `json:"fileFormat"`        // Формат файлов отчёта ("CSV", "ORC" или "Parquet")
+	FileSchema        string                  `json:"fileSchema"`        // Список полей файлов отчёта через запятую (например, "Bucket, Key, Size")
+	CreationTimestamp string                  `json:"creationTimestamp"` // Время создания отчёта в миллисекундах Unix
+	Files             []InventoryManifestFile `json:"files"`             // Файлы отчёта
+}
+
+// Файл отчёта S3 Inventory
+type InventoryManifestFile struct {
+	Key  string `json:"key"`  // Ключ файла в бакете с отчётом
+	Size int64  `json:"size"` // Размер сжатого файла в байтах
+}
+
+// Поля файлов отчёта S3 Inventory
+func (m *InventoryManifest) fields() []string {
+	fields := strings.Split(m.FileSchema, ",")
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+	return fields
+}
+
+// Запись отчёта S3 Inventory об одном объекте (или версии объекта). Поля, не включённые в конфигурацию отчёта, остаются пустыми.
+type InventoryRecord struct {
+	Bucket         string            // Бакет объекта
+	Key            string            // Ключ объекта (уже декодированный)
+	VersionID      string            // Версия объекта (для отчётов по всем версиям)
+	IsLatest       bool              // Версия является текущей
+	IsDeleteMarker bool              // Версия является маркером удаления
+	Size           int64             // Размер объекта в байтах
+	LastModified   time.Time         // Время последнего изменения объекта
+	ETag           string            // ETag объекта
+	StorageClass   string            // Класс хранения объекта
+	Fields         map[string]string // Все поля записи по именам из манифеста (например, "EncryptionStatus", "ObjectLockMode")
+}
+
+// Метод для поиска последнего отчёта S3 Inventory в бакете. prefix — каталог конфигурации отчёта
+// (обычно "<префикс назначения>/<исходный бакет>/<ID конфигурации>/"). Возвращает ключ файла manifest.json самого нового отчёта.
+func (r *s3Manager) FindLatestInventoryManifest(ctx context.Context, prefix string, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	ctx, cancel := withTimeout(ctx, r.cfg.ListTimeout)
+	defer cancel()
+
+	// Каталоги отчётов называются по времени создания (например, "2024-05-01T01-00Z"), поэтому последний по порядку ключ — самый новый
+	var latest string
+	paginator := s3.NewListObjectsV2Paginator(r.client, &s3.ListObjectsV2Input{
+		Bucket: &r.cfg.Name,
+		Prefix: &prefix,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("FindLatestInventoryManifest/ListObjectsV2: %w", classifyError(err))
+		}
+
+		for _, object := range page.Contents {
+			key := aws.ToString(object.Key)
+			if strings.HasSuffix(key, "/manifest.json") && key > latest {
+				latest = key
+			}
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("FindLatestInventoryManifest: %w: no inventory manifest under %q", ErrObjectNotFound, prefix)
+	}
+
+	return latest, nil
+}
+
+// Метод для чтения манифеста отчёта S3 Inventory по полному ключу файла manifest.json в бакете
+func (r *s3Manager) GetInventoryManifest(ctx context.Context, manifestKey string, opts ...Option) (*InventoryManifest, error) {
+	r = r.forCall(opts)
+
+	if manifestKey == "" {
+		return nil, fmt.Errorf("GetInventoryManifest: %w: manifest key is empty", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	output, err := r.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &r.cfg.Name,
+		Key:    &manifestKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetInventoryManifest/GetObject: %w", classifyError(err))
+	}
+	defer output.Body.Close()
+
+	var manifest InventoryManifest
+	err = json.NewDecoder(output.Body).Decode(&manifest)
+	if err != nil {
+		return nil, fmt.Errorf("GetInventoryManifest/Decode: %w", err)
+	}
+
+	return &manifest, nil
+}
+
+// Метод для чтения отчёта S3 Inventory: для каждой записи всех файлов отчёта вызывает fn. Отчёт должен храниться в бакете менеджера
+// (для отчёта в другом бакете нужен менеджер этого бакета). Записи читаются потоком, без загрузки отчёта в память, поэтому подходят для аудита
+// бакетов с миллионами объектов без обхода ListObjectsV2. Поддерживаются только отчёты в формате CSV. Ошибка fn прерывает чтение и возвращается.
+func (r *s3Manager) ReadInventory(ctx context.Context, manifestKey string, fn func(InventoryRecord) error, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if fn == nil {
+		return fmt.Errorf("ReadInventory: %w: record handler is nil", ErrInvalidInput)
+	}
+
+	manifest, err := r.GetInventoryManifest(ctx, manifestKey, opts...)
+	if err != nil {
+		return fmt.Errorf("ReadInventory/GetInventoryManifest: %w", err)
+	}
+	if !strings.EqualFold(manifest.FileFormat, "CSV") {
+		return fmt.Errorf("ReadInventory: %w: inventory format %q", ErrNotSupported, manifest.FileFormat)
+	}
+
+	fields := manifest.fields()
+	for _, file := range manifest.Files {
+		err = r.readInventoryFile(ctx, file.Key, fields, fn)
+		if err != nil {
+			return fmt.Errorf("ReadInventory/readInventoryFile: %s: %w", file.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// Читает один файл отчёта (CSV, сжатый gzip) и вызывает fn для каждой записи
+func (r *s3Manager) readInventoryFile(ctx context.Context, key string, fields []string, fn func(InventoryRecord) error) error {
+	ctx, cancel := withTimeout(ctx, r.cfg.DownloadTimeout)
+	defer cancel()
+
+	output, err := r.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &r.cfg.Name,
+		Key:    &key,
+	})
+	if err != nil {
+		return fmt.Errorf("readInventoryFile/GetObject: %w", classifyError(err))
+	}
+	defer output.Body.Close()
+
+	var body io.Reader = output.Body
+	if strings.HasSuffix(key, ".gz") {
+		gzipReader, err := gzip.NewReader(output.Body)
+		if err != nil {
+			return fmt.Errorf("readInventoryFile/NewReader: %w", err)
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = len(fields)
+	reader.ReuseRecord = true
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("readInventoryFile/Read: %w", err)
+		}
+
+		record, err := parseInventoryRecord(fields, values)
+		if err != nil {
+			return fmt.Errorf("readInventoryFile/parseInventoryRecord: %w", err)
+		}
+		err = fn(record)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Преобразует строку CSV отчёта в запись по именам полей из манифеста
+func parseInventoryRecord(fields, values []string) (InventoryRecord, error) {
+	record := InventoryRecord{Fields: make(map[string]string, len(fields))}
+	for i, field := range fields {
+		value := values[i]
+		record.Fields[field] = value
+
+		var err error
+		switch field {
+		case "Bucket":
+			record.Bucket = value
+		case "Key":
+			// Ключи в отчёте закодированы как в URL (пробел — "+")
+			record.Key, err = url.QueryUnescape(value)
+			record.Fields[field] = record.Key
+		case "VersionId":
+			record.VersionID = value
+		case "IsLatest":
+			record.IsLatest = value == "true"
+		case "IsDeleteMarker":
+			record.IsDeleteMarker = value == "true"
+		case "Size":
+			if value != "" {
+				record.Size, err = strconv.ParseInt(value, 10, 64)
+			}
+		case "LastModifiedDate":
+			if value != "" {
+				record.LastModified, err = time.Parse(time.RFC3339, value)
+			}
+		case "ETag":
+			record.ETag = value
+		case "StorageClass":
+			record.StorageClass = value
+		}
+		if err != nil {
+			return InventoryRecord{}, fmt.Errorf("%w: field %s: %w", ErrInvalidInput, field, err)
+		}
+	}
+
+	return record, nil
+}
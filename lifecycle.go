@@ -0,0 +1,175 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Правило жизненного цикла объектов бакета (например, автоматическое удаление тестовых файлов через 30 дней)
+type LifecycleRule struct {
+	ID                           string                // Уникальный идентификатор правила в бакете
+	Prefix                       string                // Префикс ключей, к которым применяется правило (например, "myservice/test/"). Пустой префикс — весь бакет.
+	Disabled                     bool                  // Правило сохранено, но не применяется
+	ExpirationDays               int32                 // Через сколько дней после создания объекты удаляются (0 - не удаляются)
+	Transitions                  []LifecycleTransition // Переводы объектов в другие классы хранения
+	AbortIncompleteMultipartDays int32                 // Через сколько дней отменяются незавершённые multipart-загрузки (0 - не отменяются)
+	NoncurrentVersionExpiration  int32                 // Через сколько дней удаляются неактуальные версии объектов в бакете с версионированием (0 - не удаляются)
+}
+
+// Перевод объектов в другой класс хранения (например, в холодное хранилище)
+type LifecycleTransition struct {
+	Days         int32                        // Через сколько дней после создания объект переводится в класс хранения
+	StorageClass types.TransitionStorageClass // Класс хранения (например, types.TransitionStorageClassGlacier)
+}
+
+// Метод для получения правил жизненного цикла бакета. Если правила не настроены, возвращается пустой список.
+func (r *s3Manager) GetLifecycleRules(ctx context.Context, opts ...Option) ([]LifecycleRule, error) {
+	r = r.forCall(opts)
+
+	client, err := r.s3Client()
+	if err != nil {
+		return nil, fmt.Errorf("GetLifecycleRules/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	output, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: &r.cfg.Name,
+	})
+	if err != nil {
+		if apiErrorCode(err) == "NoSuchLifecycleConfiguration" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetLifecycleRules/GetBucketLifecycleConfiguration: %w", classifyError(err))
+	}
+
+	rules := make([]LifecycleRule, 0, len(output.Rules))
+	for _, rule := range output.Rules {
+		rules = append(rules, lifecycleRuleFromS3(rule))
+	}
+
+	return rules, nil
+}
+
+// Метод для замены правил жизненного цикла бакета. Все существующие правила заменяются переданными;
+// чтобы добавить правило, нужно получить текущие правила через GetLifecycleRules и передать их вместе с новым.
+// Пустой список удаляет конфигурацию жизненного цикла.
+func (r *s3Manager) PutLifecycleRules(ctx context.Context, rules []LifecycleRule, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return fmt.Errorf("PutLifecycleRules: %w", err)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return fmt.Errorf("PutLifecycleRules/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	if len(rules) == 0 {
+		start := time.Now()
+		_, err = client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: &r.cfg.Name,
+		})
+		r.audit(ctx, "DeleteBucketLifecycle", "", start, err)
+		if err != nil {
+			return fmt.Errorf("PutLifecycleRules/DeleteBucketLifecycle: %w", classifyError(err))
+		}
+		return nil
+	}
+
+	s3Rules := make([]types.LifecycleRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.ID == "" {
+			return fmt.Errorf("PutLifecycleRules: %w: rule ID is empty", ErrInvalidInput)
+		}
+		s3Rules = append(s3Rules, rule.toS3())
+	}
+
+	start := time.Now()
+	_, err = client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket: &r.cfg.Name,
+		LifecycleConfiguration: &types.BucketLifecycleConfiguration{
+			Rules: s3Rules,
+		},
+	})
+	r.audit(ctx, "PutBucketLifecycleConfiguration", "", start, err)
+	if err != nil {
+		return fmt.Errorf("PutLifecycleRules/PutBucketLifecycleConfiguration: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Преобразует правило в формат API S3
+func (rule LifecycleRule) toS3() types.LifecycleRule {
+	s3Rule := types.LifecycleRule{
+		ID:     aws.String(rule.ID),
+		Filter: &types.LifecycleRuleFilter{Prefix: aws.String(rule.Prefix)},
+		Status: types.ExpirationStatusEnabled,
+	}
+	if rule.Disabled {
+		s3Rule.Status = types.ExpirationStatusDisabled
+	}
+
+	if rule.ExpirationDays > 0 {
+		s3Rule.Expiration = &types.LifecycleExpiration{Days: aws.Int32(rule.ExpirationDays)}
+	}
+	for _, transition := range rule.Transitions {
+		s3Rule.Transitions = append(s3Rule.Transitions, types.Transition{
+			Days:         aws.Int32(transition.Days),
+			StorageClass: transition.StorageClass,
+		})
+	}
+	if rule.AbortIncompleteMultipartDays > 0 {
+		s3Rule.AbortIncompleteMultipartUpload = &types.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: aws.Int32(rule.AbortIncompleteMultipartDays),
+		}
+	}
+	if rule.NoncurrentVersionExpiration > 0 {
+		s3Rule.NoncurrentVersionExpiration = &types.NoncurrentVersionExpiration{
+			NoncurrentDays: aws.Int32(rule.NoncurrentVersionExpiration),
+		}
+	}
+
+	return s3Rule
+}
+
+// Преобразует правило из формата API S3
+func lifecycleRuleFromS3(s3Rule types.LifecycleRule) LifecycleRule {
+	rule := LifecycleRule{
+		ID:       aws.ToString(s3Rule.ID),
+		Prefix:   aws.ToString(s3Rule.Prefix), //nolint:staticcheck // Устаревшее поле, которое всё ещё возвращают некоторые хранилища
+		Disabled: s3Rule.Status == types.ExpirationStatusDisabled,
+	}
+	if s3Rule.Filter != nil && s3Rule.Filter.Prefix != nil {
+		rule.Prefix = *s3Rule.Filter.Prefix
+	}
+
+	if s3Rule.Expiration != nil {
+		rule.ExpirationDays = aws.ToInt32(s3Rule.Expiration.Days)
+	}
+	for _, transition := range s3Rule.Transitions {
+		rule.Transitions = append(rule.Transitions, LifecycleTransition{
+			Days:         aws.ToInt32(transition.Days),
+			StorageClass: transition.StorageClass,
+		})
+	}
+	if s3Rule.AbortIncompleteMultipartUpload != nil {
+		rule.AbortIncompleteMultipartDays = aws.ToInt32(s3Rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+	}
+	if s3Rule.NoncurrentVersionExpiration != nil {
+		rule.NoncurrentVersionExpiration = aws.ToInt32(s3Rule.NoncurrentVersionExpiration.NoncurrentDays)
+	}
+
+	return rule
+}
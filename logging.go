@@ -0,0 +1,150 @@
+package s3_manager
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Хранилище, которое логирует каждый запрос: операцию, бакет, ключ, длительность и результат (на уровне Debug).
+// Подключается автоматически, если в конфиге указан Logger.
+type loggingBackend struct {
+	next   StorageBackend
+	logger *slog.Logger
+}
+
+var _ StorageBackend = (*loggingBackend)(nil)
+
+// Возвращает хранилище, вокруг которого построена обёртка
+func (b *loggingBackend) Unwrap() StorageBackend {
+	return b.next
+}
+
+func (b *loggingBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	start := time.Now()
+	output, err := b.next.PutObject(ctx, params, optFns...)
+	b.log(ctx, "PutObject", params.Bucket, start, err, slog.String("key", aws.ToString(params.Key)))
+	return output, err
+}
+
+func (b *loggingBackend) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	start := time.Now()
+	output, err := b.next.GetObject(ctx, params, optFns...)
+	b.log(ctx, "GetObject", params.Bucket, start, err, slog.String("key", aws.ToString(params.Key)))
+	return output, err
+}
+
+func (b *loggingBackend) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	start := time.Now()
+	output, err := b.next.HeadObject(ctx, params, optFns...)
+	b.log(ctx, "HeadObject", params.Bucket, start, err, slog.String("key", aws.ToString(params.Key)))
+	return output, err
+}
+
+func (b *loggingBackend) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	start := time.Now()
+	output, err := b.next.ListObjectsV2(ctx, params, optFns...)
+	attrs := []slog.Attr{slog.String("prefix", aws.ToString(params.Prefix))}
+	if output != nil {
+		attrs = append(attrs, slog.Int("objects", len(output.Contents)))
+	}
+	b.log(ctx, "ListObjectsV2", params.Bucket, start, err, attrs...)
+	return output, err
+}
+
+func (b *loggingBackend) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	start := time.Now()
+	output, err := b.next.DeleteObject(ctx, params, optFns...)
+	b.log(ctx, "DeleteObject", params.Bucket, start, err, slog.String("key", aws.ToString(params.Key)))
+	return output, err
+}
+
+func (b *loggingBackend) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	start := time.Now()
+	output, err := b.next.DeleteObjects(ctx, params, optFns...)
+	var attrs []slog.Attr
+	if params.Delete != nil {
+		attrs = append(attrs, slog.Int("objects", len(params.Delete.Objects)))
+	}
+	if output != nil && len(output.Errors) > 0 {
+		attrs = append(attrs, slog.Int("failed", len(output.Errors)))
+	}
+	b.log(ctx, "DeleteObjects", params.Bucket, start, err, attrs...)
+	return output, err
+}
+
+func (b *loggingBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	start := time.Now()
+	output, err := b.next.CopyObject(ctx, params, optFns...)
+	b.log(ctx, "CopyObject", params.Bucket, start, err,
+		slog.String("key", aws.ToString(params.Key)),
+		slog.String("source", aws.ToString(params.CopySource)),
+	)
+	return output, err
+}
+
+func (b *loggingBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	start := time.Now()
+	output, err := b.next.CreateMultipartUpload(ctx, params, optFns...)
+	b.log(ctx, "CreateMultipartUpload", params.Bucket, start, err, slog.String("key", aws.ToString(params.Key)))
+	return output, err
+}
+
+func (b *loggingBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	start := time.Now()
+	output, err := b.next.UploadPart(ctx, params, optFns...)
+	b.log(ctx, "UploadPart", params.Bucket, start, err,
+		slog.String("key", aws.ToString(params.Key)),
+		slog.Int("part", int(aws.ToInt32(params.PartNumber))),
+	)
+	return output, err
+}
+
+func (b *loggingBackend) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	start := time.Now()
+	output, err := b.next.UploadPartCopy(ctx, params, optFns...)
+	b.log(ctx, "UploadPartCopy", params.Bucket, start, err,
+		slog.String("key", aws.ToString(params.Key)),
+		slog.String("source", aws.ToString(params.CopySource)),
+		slog.Int("part", int(aws.ToInt32(params.PartNumber))),
+	)
+	return output, err
+}
+
+func (b *loggingBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	start := time.Now()
+	output, err := b.next.CompleteMultipartUpload(ctx, params, optFns...)
+	b.log(ctx, "CompleteMultipartUpload", params.Bucket, start, err, slog.String("key", aws.ToString(params.Key)))
+	return output, err
+}
+
+func (b *loggingBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	start := time.Now()
+	output, err := b.next.AbortMultipartUpload(ctx, params, optFns...)
+	b.log(ctx, "AbortMultipartUpload", params.Bucket, start, err, slog.String("key", aws.ToString(params.Key)))
+	return output, err
+}
+
+// Записывает в лог результат запроса к хранилищу
+func (b *loggingBackend) log(ctx context.Context, operation string, bucket *string, start time.Time, err error, attrs ...slog.Attr) {
+	if !b.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs = append([]slog.Attr{
+		slog.String("operation", operation),
+		slog.String("bucket", aws.ToString(bucket)),
+	}, attrs...)
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+
+	if err != nil {
+		attrs = append(attrs, slog.String("result", "error"), slog.Any("error", err))
+	} else {
+		attrs = append(attrs, slog.String("result", "ok"))
+	}
+
+	b.logger.LogAttrs(ctx, slog.LevelDebug, "s3 request", attrs...)
+}
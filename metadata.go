@@ -0,0 +1,54 @@
+package s3_manager
+
+import (
+	"mime"
+	"unicode/utf8"
+)
+
+// Кодирует значения пользовательских метаданных, содержащие не-ASCII символы (например, кириллические имена файлов), по RFC 2047,
+// так как S3 передаёт метаданные в HTTP-заголовках, где допустим только ASCII
+func encodeMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	encoded := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		if isASCII(value) {
+			encoded[key] = value
+		} else {
+			encoded[key] = mime.BEncoding.Encode("utf-8", value)
+		}
+	}
+
+	return encoded
+}
+
+// Декодирует значения пользовательских метаданных, закодированные по RFC 2047 (см. encodeMetadata)
+func decodeMetadata(metadata map[string]string) map[string]string {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	decoder := new(mime.WordDecoder)
+	decoded := make(map[string]string, len(metadata))
+	for key, value := range metadata {
+		decodedValue, err := decoder.DecodeHeader(value)
+		if err != nil {
+			decodedValue = value // Значение записано не этой библиотекой и не является закодированным словом
+		}
+		decoded[key] = decodedValue
+	}
+
+	return decoded
+}
+
+// Проверяет, что строка содержит только ASCII-символы
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
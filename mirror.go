@@ -0,0 +1,158 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Параметры зеркалирования файлов между хранилищами
+type MirrorOptions struct {
+	Delete   bool                  // Удалять файлы в приёмнике, которых нет в источнике
+	Progress func(done, total int) // Вызывается после обработки каждого файла (из разных горутин, но не одновременно)
+}
+
+// Копирует файлы с префиксом prefix из хранилища src в хранилище dst (например, при переезде на другого провайдера или в другой бакет).
+// Ключи файлов в приёмнике совпадают с ключами в источнике. Копируются новые файлы и файлы, которые отличаются размером или ETag;
+// если ETag нельзя сравнить (например, файл загружен через multipart с другим размером частей), файл копируется, когда в приёмнике он старше.
+// Файлы читаются из источника диапазонными запросами и загружаются в приёмник параллельно (см. WithConcurrency);
+// опции загрузки (ACL, шифрование и т.д.) применяются к каждому файлу в приёмнике, MIME-тип и метаданные копируются из источника.
+// Если часть файлов обработать не удалось, возвращается результат по остальным файлам и ошибка *BatchError.
+func MirrorPrefix(ctx context.Context, src, dst S3Manager, prefix string, mirrorOpts MirrorOptions, opts ...Option) (*SyncResult, error) {
+	if src == nil || dst == nil {
+		return nil, fmt.Errorf("MirrorPrefix: %w: source or destination is nil", ErrInvalidInput)
+	}
+
+	o := operationOptions{concurrency: defaultBatchConcurrency}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	srcObjects, err := src.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("MirrorPrefix/ListObjects: source: %w", err)
+	}
+	dstObjects, err := dst.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("MirrorPrefix/ListObjects: destination: %w", err)
+	}
+
+	existing := make(map[string]ObjectInfo, len(dstObjects))
+	for _, object := range dstObjects {
+		existing[object.Key] = object
+	}
+
+	var (
+		mu     sync.Mutex
+		done   int
+		result = &SyncResult{}
+		failed = make(map[string]error)
+		inSrc  = make(map[string]bool, len(srcObjects))
+	)
+
+	tasks := newBatch(o.concurrency, nil) // Менеджеры src и dst могут быть любыми реализациями S3Manager, поэтому их общие ограничения здесь недоступны
+	for _, object := range srcObjects {
+		inSrc[object.Key] = true
+
+		tasks.Go(func() {
+			var err error
+			dstObject, ok := existing[object.Key]
+			changed := !ok || objectChanged(object, dstObject)
+			if changed {
+				err = mirrorObject(ctx, src, dst, object.Key, opts)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed[object.Key] = err
+			case changed:
+				result.Copied = append(result.Copied, object.Key)
+			default:
+				result.Unchanged++
+			}
+
+			done++
+			if mirrorOpts.Progress != nil {
+				mirrorOpts.Progress(done, len(srcObjects))
+			}
+		})
+	}
+	tasks.Wait()
+
+	if mirrorOpts.Delete {
+		deleteOpts := append([]Option{WithPermanentDelete()}, opts...)
+		for _, object := range dstObjects {
+			if inSrc[object.Key] {
+				continue
+			}
+
+			err := dst.DeleteFile(ctx, StoragePath{}, object.Key, append(deleteOpts, withRawKey())...)
+			if err != nil {
+				failed[object.Key] = err
+				continue
+			}
+			result.Deleted = append(result.Deleted, object.Key)
+		}
+	}
+
+	sort.Strings(result.Copied)
+	sort.Strings(result.Deleted)
+
+	if len(failed) > 0 {
+		return result, fmt.Errorf("MirrorPrefix: %w", &BatchError{Errors: failed})
+	}
+
+	return result, nil
+}
+
+// Сравнивает объект в источнике с объектом в приёмнике: по размеру, затем по ETag, если оба ETag равны MD5 содержимого,
+// иначе по времени изменения (объект в приёмнике старше объекта в источнике)
+func objectChanged(src, dst ObjectInfo) bool {
+	if src.Size != dst.Size {
+		return true
+	}
+	if src.ETag == dst.ETag {
+		return false
+	}
+
+	srcETag, srcOK := md5ETag(src.ETag)
+	dstETag, dstOK := md5ETag(dst.ETag)
+	if srcOK && dstOK {
+		return srcETag != dstETag
+	}
+
+	return dst.LastModified.Before(src.LastModified)
+}
+
+// Копирует объект с ключом key из src в dst, сохраняя MIME-тип, сжатие и метаданные
+func mirrorObject(ctx context.Context, src, dst S3Manager, key string, opts []Option) error {
+	// Ключи из ListObjects полные, поэтому файлы адресуются ключом без корневого каталога менеджеров
+	object, err := src.OpenObject(ctx, StoragePath{}, key, withRawKey())
+	if err != nil {
+		return fmt.Errorf("mirrorObject/OpenObject: %w", err)
+	}
+	defer object.Close()
+
+	info := object.Info()
+	putOpts := append([]Option{WithMetadata(info.Metadata)}, opts...)
+	putOpts = append(putOpts, withRawName(), withRawKey())
+	if info.ContentType != "" {
+		putOpts = append(putOpts, WithContentType(info.ContentType))
+	}
+	if info.ContentEncoding != "" {
+		// Содержимое читается как есть, поэтому сжатые объекты копируются без повторного сжатия
+		putOpts = append(putOpts, WithCompression(CompressionNone), withContentEncoding(info.ContentEncoding))
+	}
+
+	_, err = dst.PutFile(ctx, StoragePath{}, &BucketFile{File: object, Name: key}, putOpts...)
+	if err != nil {
+		return fmt.Errorf("mirrorObject/PutFile: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestMirrorPrefixWithRootCatalog(t *testing.T) {
+	ctx := context.Background()
+	src, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "src", RootCatalog: "service"}, false)
+	dst, dstBackend := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "dst", RootCatalog: "other"}, true)
+
+	for _, name := range []string{"a.txt", "nested/b.txt"} {
+		_, err := src.PutFile(ctx, StoragePath{}, &BucketFile{Name: name, File: bytes.NewReader([]byte("content of " + name))}, withRawName())
+		if err != nil {
+			t.Fatalf("PutFile(%q): %v", name, err)
+		}
+	}
+	_, err := dstBackend.PutObject(ctx, putInput("dst", "service/stale.txt", "stale"))
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	_, err = dstBackend.PutObject(ctx, putInput("dst", "unrelated.txt", "keep"))
+	if err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+
+	result, err := MirrorPrefix(ctx, src, dst, "service/", MirrorOptions{Delete: true})
+	if err != nil {
+		t.Fatalf("MirrorPrefix: %v", err)
+	}
+
+	wantCopied := []string{"service/a.txt", "service/nested/b.txt"}
+	if !slices.Equal(result.Copied, wantCopied) {
+		t.Errorf("Copied = %v, want %v", result.Copied, wantCopied)
+	}
+	if want := []string{"service/stale.txt"}; !slices.Equal(result.Deleted, want) {
+		t.Errorf("Deleted = %v, want %v", result.Deleted, want)
+	}
+
+	objects, err := dst.ListObjects(ctx, "")
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	var keys []string
+	for _, object := range objects {
+		keys = append(keys, object.Key)
+	}
+	if want := []string{"service/a.txt", "service/nested/b.txt", "unrelated.txt"}; !slices.Equal(keys, want) {
+		t.Errorf("destination keys = %v, want %v", keys, want)
+	}
+
+	output, err := dstBackend.GetObject(ctx, getInput("dst", "service/nested/b.txt"))
+	if err != nil {
+		t.Fatalf("GetObject: %v", err)
+	}
+	defer output.Body.Close()
+	content, _ := io.ReadAll(output.Body)
+	if string(content) != "content of nested/b.txt" {
+		t.Errorf("content = %q, want %q", content, "content of nested/b.txt")
+	}
+}
@@ -0,0 +1,351 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	defaultMultipartThreshold   int64 = 64 << 20 // Размер файла, начиная с которого используется multipart upload (64 МиБ)
+	defaultMultipartPartSize    int64 = 16 << 20 // Размер одной части multipart upload (16 МиБ)
+	defaultMultipartConcurrency       = 4        // Количество одновременно загружаемых частей
+	minMultipartPartSize        int64 = 5 << 20  // Минимальный размер части, допустимый в S3 (кроме последней части)
+	maxMultipartParts                 = 10000    // Максимальное количество частей в одной multipart-загрузке
+)
+
+// Загружает объект в бакет: небольшие файлы одним запросом PutObject, крупные — через multipart upload
+func (r *s3Manager) putObject(ctx context.Context, input *s3.PutObjectInput) error {
+	size, err := readerSize(input.Body)
+	if err != nil {
+		return fmt.Errorf("putObject/readerSize: %w", err)
+	}
+	if size < 0 {
+		// Размер потока без Seek неизвестен: читаем его начало (не больше одной части, чтобы не держать в памяти весь поток),
+		// и если поток закончился раньше, загружаем его одним запросом, иначе — по частям, начиная с уже прочитанных данных
+		limit := min(r.multipartThreshold(), r.multipartPartSize(-1))
+		head, err := io.ReadAll(io.LimitReader(input.Body, limit))
+		if err != nil {
+			return fmt.Errorf("putObject/ReadAll: %w", err)
+		}
+		if int64(len(head)) < limit {
+			input.Body, size = bytes.NewReader(head), int64(len(head))
+		} else {
+			input.Body = io.MultiReader(bytes.NewReader(head), input.Body)
+		}
+	}
+
+	if size >= 0 && size < r.multipartThreshold() {
+		var checksum checksumValue
+		if input.ChecksumAlgorithm != "" {
+			checksum, err = computeReaderChecksum(input.ChecksumAlgorithm, input.Body.(io.ReadSeeker)) // Поток с известным размером всегда поддерживает Seek
+			if err != nil {
+				return fmt.Errorf("putObject/computeReaderChecksum: %w", err)
+			}
+			err = checksum.applyToPut(input)
+			if err != nil {
+				return fmt.Errorf("putObject/applyToPut: %w", err)
+			}
+		}
+
+		output, err := r.client.PutObject(ctx, input)
+		if err != nil {
+			return fmt.Errorf("putObject/PutObject: %w", classifyError(err))
+		}
+
+		if checksum.value != "" {
+			actual, _ := checksumField(checksum.algorithm, &output.ChecksumCRC32, &output.ChecksumCRC32C, &output.ChecksumCRC64NVME, &output.ChecksumSHA1, &output.ChecksumSHA256)
+			err = checksum.verify(*actual)
+			if err != nil {
+				return fmt.Errorf("putObject/verify: %w", err)
+			}
+		}
+		return nil
+	}
+
+	err = r.uploadMultipart(ctx, input, r.multipartPartSize(size))
+	if err != nil {
+		return fmt.Errorf("putObject/uploadMultipart: %w", err)
+	}
+
+	return nil
+}
+
+// Загружает объект по частям размером partSize. Части читаются из тела запроса последовательно, а отправляются параллельно (не более MultipartConcurrency одновременно).
+// При любой ошибке незавершённая загрузка отменяется, чтобы не оставлять в бакете «висящие» части.
+func (r *s3Manager) uploadMultipart(ctx context.Context, input *s3.PutObjectInput, partSize int64) error {
+	createOutput, err := r.client.CreateMultipartUpload(ctx, createMultipartInput(input))
+	if err != nil {
+		return fmt.Errorf("uploadMultipart/CreateMultipartUpload: %w", classifyError(err))
+	}
+	uploadID := createOutput.UploadId
+
+	parts, err := r.uploadParts(ctx, input, uploadID, partSize)
+	if err != nil {
+		r.abortMultipart(ctx, input.Bucket, input.Key, uploadID)
+		return fmt.Errorf("uploadMultipart/uploadParts: %w", err)
+	}
+
+	_, err = r.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          input.Bucket,
+		Key:             input.Key,
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		IfNoneMatch:     input.IfNoneMatch,
+	})
+	if err != nil {
+		r.abortMultipart(ctx, input.Bucket, input.Key, uploadID)
+		return fmt.Errorf("uploadMultipart/CompleteMultipartUpload: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Читает тело запроса частями и загружает их параллельно. Возвращает список загруженных частей, отсортированный по номеру.
+func (r *s3Manager) uploadParts(ctx context.Context, input *s3.PutObjectInput, uploadID *string, partSize int64) ([]types.CompletedPart, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		parts    []types.CompletedPart
+		firstErr error
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	semaphore := make(chan struct{}, r.multipartConcurrency())
+	for partNumber := int32(1); ; partNumber++ {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		buf := make([]byte, partSize)
+		n, readErr := io.ReadFull(input.Body, buf)
+		isLastPart := errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF)
+		if readErr != nil && !isLastPart {
+			<-semaphore
+			setErr(fmt.Errorf("read part %d: %w", partNumber, readErr))
+			break
+		}
+		// Пустая часть загружается только если файл пустой целиком
+		if n == 0 && partNumber > 1 {
+			<-semaphore
+			break
+		}
+		// Размер части потока неизвестного размера не подбирается под объём данных, поэтому поток может не уместиться в лимит частей S3
+		if partNumber > maxMultipartParts {
+			<-semaphore
+			setErr(fmt.Errorf("%w: stream exceeds %d parts of %d bytes, increase MultipartPartSize", ErrFileTooLarge, maxMultipartParts, partSize))
+			break
+		}
+
+		wg.Add(1)
+		go func(partNumber int32, data []byte) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			part, err := r.uploadPart(ctx, input, uploadID, partNumber, data)
+			if err != nil {
+				setErr(fmt.Errorf("part %d: %w", partNumber, err))
+				return
+			}
+
+			mu.Lock()
+			parts = append(parts, part)
+			mu.Unlock()
+		}(partNumber, buf[:n])
+
+		if isLastPart {
+			break
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber)
+	})
+
+	return parts, nil
+}
+
+// Загружает одну часть multipart upload
+// Если алгоритм контрольной суммы задан явно, контрольная сумма части вычисляется заранее и сверяется с ответом хранилища.
+func (r *s3Manager) uploadPart(ctx context.Context, input *s3.PutObjectInput, uploadID *string, partNumber int32, data []byte) (types.CompletedPart, error) {
+	partInput := &s3.UploadPartInput{
+		Bucket:               input.Bucket,
+		Key:                  input.Key,
+		UploadId:             uploadID,
+		PartNumber:           aws.Int32(partNumber),
+		Body:                 bytes.NewReader(data),
+		ContentLength:        aws.Int64(int64(len(data))),
+		ChecksumAlgorithm:    multipartChecksumAlgorithm(input),
+		SSECustomerAlgorithm: input.SSECustomerAlgorithm,
+		SSECustomerKey:       input.SSECustomerKey,
+		SSECustomerKeyMD5:    input.SSECustomerKeyMD5,
+	}
+
+	var checksum checksumValue
+	if input.ChecksumAlgorithm != "" {
+		var err error
+		checksum, err = computeChecksum(input.ChecksumAlgorithm, data)
+		if err != nil {
+			return types.CompletedPart{}, fmt.Errorf("uploadPart/computeChecksum: %w", err)
+		}
+		err = checksum.applyToUploadPart(partInput)
+		if err != nil {
+			return types.CompletedPart{}, fmt.Errorf("uploadPart/applyToUploadPart: %w", err)
+		}
+	}
+
+	output, err := r.client.UploadPart(ctx, partInput)
+	if err != nil {
+		return types.CompletedPart{}, fmt.Errorf("uploadPart/UploadPart: %w", classifyError(err))
+	}
+
+	if checksum.value != "" {
+		actual, _ := checksumField(checksum.algorithm, &output.ChecksumCRC32, &output.ChecksumCRC32C, &output.ChecksumCRC64NVME, &output.ChecksumSHA1, &output.ChecksumSHA256)
+		err = checksum.verify(*actual)
+		if err != nil {
+			return types.CompletedPart{}, fmt.Errorf("uploadPart/verify: %w", err)
+		}
+	}
+
+	return types.CompletedPart{
+		PartNumber:        aws.Int32(partNumber),
+		ETag:              output.ETag,
+		ChecksumCRC32:     output.ChecksumCRC32,
+		ChecksumCRC32C:    output.ChecksumCRC32C,
+		ChecksumCRC64NVME: output.ChecksumCRC64NVME,
+		ChecksumSHA1:      output.ChecksumSHA1,
+		ChecksumSHA256:    output.ChecksumSHA256,
+	}, nil
+}
+
+// Отменяет незавершённую multipart-загрузку. Выполняется даже при отменённом контексте, чтобы освободить место, занятое частями.
+func (r *s3Manager) abortMultipart(ctx context.Context, bucket, key, uploadID *string) {
+	_, _ = r.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   bucket,
+		Key:      key,
+		UploadId: uploadID,
+	})
+}
+
+// Формирует запрос на создание multipart-загрузки с теми же параметрами объекта, что и у обычной загрузки
+func createMultipartInput(input *s3.PutObjectInput) *s3.CreateMultipartUploadInput {
+	return &s3.CreateMultipartUploadInput{
+		Bucket:                    input.Bucket,
+		Key:                       input.Key,
+		ACL:                       input.ACL,
+		CacheControl:              input.CacheControl,
+		ContentDisposition:        input.ContentDisposition,
+		ContentEncoding:           input.ContentEncoding,
+		ContentLanguage:           input.ContentLanguage,
+		ContentType:               input.ContentType,
+		Expires:                   input.Expires,
+		Metadata:                  input.Metadata,
+		StorageClass:              input.StorageClass,
+		Tagging:                   input.Tagging,
+		WebsiteRedirectLocation:   input.WebsiteRedirectLocation,
+		ChecksumAlgorithm:         multipartChecksumAlgorithm(input),
+		ServerSideEncryption:      input.ServerSideEncryption,
+		SSEKMSKeyId:               input.SSEKMSKeyId,
+		SSEKMSEncryptionContext:   input.SSEKMSEncryptionContext,
+		BucketKeyEnabled:          input.BucketKeyEnabled,
+		SSECustomerAlgorithm:      input.SSECustomerAlgorithm,
+		SSECustomerKey:            input.SSECustomerKey,
+		SSECustomerKeyMD5:         input.SSECustomerKeyMD5,
+		ObjectLockMode:            input.ObjectLockMode,
+		ObjectLockRetainUntilDate: input.ObjectLockRetainUntilDate,
+		ObjectLockLegalHoldStatus: input.ObjectLockLegalHoldStatus,
+	}
+}
+
+// Алгоритм контрольной суммы частей. Если не задан явно, используется CRC32 (как и в SDK по умолчанию для одиночных загрузок).
+func multipartChecksumAlgorithm(input *s3.PutObjectInput) types.ChecksumAlgorithm {
+	if input.ChecksumAlgorithm != "" {
+		return input.ChecksumAlgorithm
+	}
+	return types.ChecksumAlgorithmCrc32
+}
+
+// Определяет оставшийся размер данных в потоке. Для потоков без поддержки Seek возвращает -1.
+func readerSize(reader io.Reader) (int64, error) {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return -1, nil
+	}
+
+	current, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, fmt.Errorf("readerSize/Seek: %w", err)
+	}
+	end, err := seeker.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, fmt.Errorf("readerSize/Seek: %w", err)
+	}
+	_, err = seeker.Seek(current, io.SeekStart)
+	if err != nil {
+		return 0, fmt.Errorf("readerSize/Seek: %w", err)
+	}
+
+	return end - current, nil
+}
+
+// Размер файла, начиная с которого используется multipart upload
+func (r *s3Manager) multipartThreshold() int64 {
+	if r.cfg.MultipartThreshold > 0 {
+		return r.cfg.MultipartThreshold
+	}
+	return defaultMultipartThreshold
+}
+
+// Размер части multipart upload. Увеличивается, если при заданном размере части не уложиться в лимит S3 на количество частей.
+func (r *s3Manager) multipartPartSize(size int64) int64 {
+	partSize := r.cfg.MultipartPartSize
+	if partSize <= 0 {
+		partSize = defaultMultipartPartSize
+	}
+	if partSize < minMultipartPartSize {
+		partSize = minMultipartPartSize
+	}
+	if size > 0 && size/partSize >= maxMultipartParts {
+		partSize = size/maxMultipartParts + 1
+	}
+
+	return partSize
+}
+
+// Количество одновременно загружаемых частей
+func (r *s3Manager) multipartConcurrency() int {
+	if r.cfg.MultipartConcurrency > 0 {
+		return r.cfg.MultipartConcurrency
+	}
+	return defaultMultipartConcurrency
+}
@@ -0,0 +1,84 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Хранилище в памяти, которое запоминает начатые, завершённые и отменённые multipart-загрузки и номера загруженных частей
+type multipartBackend struct {
+	StorageBackend
+	mu         sync.Mutex
+	created    int
+	completed  int
+	aborted    int
+	parts      int
+	maxPartNum int32
+}
+
+func (b *multipartBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	output, err := b.StorageBackend.CreateMultipartUpload(ctx, params, optFns...)
+	if err == nil {
+		b.mu.Lock()
+		b.created++
+		b.mu.Unlock()
+	}
+	return output, err
+}
+
+func (b *multipartBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	b.mu.Lock()
+	b.parts++
+	b.maxPartNum = max(b.maxPartNum, aws.ToInt32(params.PartNumber))
+	b.mu.Unlock()
+	return b.StorageBackend.UploadPart(ctx, params, optFns...)
+}
+
+func (b *multipartBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	b.mu.Lock()
+	b.completed++
+	b.mu.Unlock()
+	return b.StorageBackend.CompleteMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *multipartBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	output, err := b.StorageBackend.AbortMultipartUpload(ctx, params, optFns...)
+	if err == nil {
+		b.mu.Lock()
+		b.aborted++
+		b.mu.Unlock()
+	}
+	return output, err
+}
+
+func TestUploadMultipartPartLimit(t *testing.T) {
+	ctx := context.Background()
+	backend := &multipartBackend{StorageBackend: NewMemoryBackend()}
+	manager := newTestManagerWithBackend(t, backend, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+
+	// Поток неизвестного размера с частями по 1 байту: на последний байт не хватает лимита частей
+	body := io.MultiReader(bytes.NewReader(make([]byte, maxMultipartParts+1)))
+	input := &s3.PutObjectInput{Bucket: aws.String("b"), Key: aws.String("large.bin"), Body: body}
+	err := manager.uploadMultipart(ctx, input, 1)
+	if !errors.Is(err, ErrFileTooLarge) {
+		t.Fatalf("uploadMultipart error = %v, want ErrFileTooLarge", err)
+	}
+
+	if backend.maxPartNum > maxMultipartParts {
+		t.Errorf("part %d was sent, want no parts over the %d-part limit", backend.maxPartNum, maxMultipartParts)
+	}
+	if backend.created != 1 || backend.aborted != backend.created || backend.completed != 0 {
+		t.Errorf("uploads created %d, aborted %d, completed %d; want the only upload aborted", backend.created, backend.aborted, backend.completed)
+	}
+	exists, err := manager.FileExists(ctx, StoragePath{}, "large.bin", withRawName())
+	if err != nil || exists {
+		t.Errorf("FileExists = %v, %v; want false after the failed upload", exists, err)
+	}
+}
@@ -0,0 +1,79 @@
+package s3_manager
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Способ формирования уникального имени загружаемого файла (см. WithUniqueName). Расширение исходного имени сохраняется.
+type NamingStrategy string
+
+const (
+	NamingUUID          NamingStrategy = "uuid"           // Случайный UUID версии 4 (например, "3f0c9a6e-8d4b-4f4e-9b1a-2c7d5e6f8a90.jpg")
+	NamingTimestampHash NamingStrategy = "timestamp_hash" // Время загрузки и случайный хеш (например, "20240131T120000-1a2b3c4d5e6f7a8b.jpg"): файлы сортируются по времени загрузки
+	NamingContentHash   NamingStrategy = "content_hash"   // SHA-256 содержимого файла: одинаковые файлы получают одно имя. Требует потока с поддержкой Seek.
+)
+
+// Формирует уникальное имя файла по стратегии. Для NamingContentHash содержимое file читается целиком, после чего позиция возвращается на место.
+func uniqueFileName(strategy NamingStrategy, name string, file io.Reader) (string, error) {
+	ext := strings.ToLower(path.Ext(name))
+
+	switch strategy {
+	case NamingUUID, "":
+		id, err := newUUID()
+		if err != nil {
+			return "", fmt.Errorf("uniqueFileName/newUUID: %w", err)
+		}
+		return id + ext, nil
+	case NamingTimestampHash:
+		random := make([]byte, 16)
+		_, err := rand.Read(random)
+		if err != nil {
+			return "", fmt.Errorf("uniqueFileName/Read: %w", err)
+		}
+		h := sha256.New()
+		h.Write([]byte(name))
+		h.Write(random)
+		return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(h.Sum(nil))[:16] + ext, nil
+	case NamingContentHash:
+		seeker, ok := file.(io.ReadSeeker)
+		if !ok {
+			return "", fmt.Errorf("uniqueFileName: %w: content hash naming requires a seekable file", ErrInvalidInput)
+		}
+		current, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return "", fmt.Errorf("uniqueFileName/Seek: %w", err)
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, seeker)
+		if err != nil {
+			return "", fmt.Errorf("uniqueFileName/Copy: %w", err)
+		}
+		_, err = seeker.Seek(current, io.SeekStart)
+		if err != nil {
+			return "", fmt.Errorf("uniqueFileName/Seek: %w", err)
+		}
+		return hex.EncodeToString(h.Sum(nil)) + ext, nil
+	default:
+		return "", fmt.Errorf("uniqueFileName: %w: unknown naming strategy %q", ErrInvalidInput, strategy)
+	}
+}
+
+// Генерирует случайный UUID версии 4 (RFC 9562)
+func newUUID() (string, error) {
+	var id [16]byte
+	_, err := rand.Read(id[:])
+	if err != nil {
+		return "", err
+	}
+	id[6] = id[6]&0x0f | 0x40 // Версия 4
+	id[8] = id[8]&0x3f | 0x80 // Вариант RFC 9562
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16]), nil
+}
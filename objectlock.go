@@ -0,0 +1,321 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Срок хранения объекта (WORM): до RetainUntil объект нельзя удалить или перезаписать.
+// В режиме GOVERNANCE ограничение снимается опцией WithBypassGovernanceRetention (при наличии права s3:BypassGovernanceRetention),
+// в режиме COMPLIANCE — не снимается никем, в том числе владельцем бакета, а срок можно только продлить.
+type Retention struct {
+	Mode        types.ObjectLockRetentionMode // Режим хранения (types.ObjectLockRetentionModeGovernance или types.ObjectLockRetentionModeCompliance)
+	RetainUntil time.Time                     // Время окончания срока хранения
+}
+
+// Проверяет, что режим и срок хранения заданы
+func (rt Retention) validate() error {
+	switch rt.Mode {
+	case types.ObjectLockRetentionModeGovernance, types.ObjectLockRetentionModeCompliance:
+	default:
+		return fmt.Errorf("%w: unknown retention mode %q", ErrInvalidInput, rt.Mode)
+	}
+	if rt.RetainUntil.IsZero() {
+		return fmt.Errorf("%w: retain until date is empty", ErrInvalidInput)
+	}
+
+	return nil
+}
+
+// Настройки блокировки объектов бакета. Блокировку можно включить только при создании бакета (см. WithObjectLock);
+// срок хранения по умолчанию применяется ко всем новым объектам, для которых он не задан опцией WithRetention.
+type ObjectLockConfig struct {
+	Enabled bool                          // Блокировка объектов включена в бакете
+	Mode    types.ObjectLockRetentionMode // Режим хранения по умолчанию (пустой — срок хранения по умолчанию не задан)
+	Days    int32                         // Срок хранения по умолчанию в днях
+	Years   int32                         // Срок хранения по умолчанию в годах (задаётся либо Days, либо Years)
+}
+
+// Метод для получения настроек блокировки объектов бакета. Если блокировка в бакете не включена, возвращается пустая конфигурация.
+func (r *s3Manager) GetObjectLockConfig(ctx context.Context, opts ...Option) (*ObjectLockConfig, error) {
+	r = r.forCall(opts)
+
+	client, err := r.s3Client()
+	if err != nil {
+		return nil, fmt.Errorf("GetObjectLockConfig/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	output, err := client.GetObjectLockConfiguration(ctx, &s3.GetObjectLockConfigurationInput{
+		Bucket: &r.cfg.Name,
+	})
+	if err != nil {
+		if apiErrorCode(err) == "ObjectLockConfigurationNotFoundError" {
+			return &ObjectLockConfig{}, nil
+		}
+		return nil, fmt.Errorf("GetObjectLockConfig/GetObjectLockConfiguration: %w", classifyError(err))
+	}
+
+	lockConfig := &ObjectLockConfig{}
+	if output.ObjectLockConfiguration == nil {
+		return lockConfig, nil
+	}
+	lockConfig.Enabled = output.ObjectLockConfiguration.ObjectLockEnabled == types.ObjectLockEnabledEnabled
+	if rule := output.ObjectLockConfiguration.Rule; rule != nil && rule.DefaultRetention != nil {
+		lockConfig.Mode = rule.DefaultRetention.Mode
+		lockConfig.Days = aws.ToInt32(rule.DefaultRetention.Days)
+		lockConfig.Years = aws.ToInt32(rule.DefaultRetention.Years)
+	}
+
+	return lockConfig, nil
+}
+
+// Метод для изменения срока хранения по умолчанию в бакете с включённой блокировкой объектов.
+// Конфигурация без Mode удаляет срок хранения по умолчанию; на уже загруженные объекты изменение не влияет.
+func (r *s3Manager) PutObjectLockConfig(ctx context.Context, lockConfig ObjectLockConfig, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return fmt.Errorf("PutObjectLockConfig: %w", err)
+	}
+
+	input := &s3.PutObjectLockConfigurationInput{
+		Bucket: &r.cfg.Name,
+		ObjectLockConfiguration: &types.ObjectLockConfiguration{
+			ObjectLockEnabled: types.ObjectLockEnabledEnabled,
+		},
+	}
+	if lockConfig.Mode != "" {
+		if (lockConfig.Days > 0) == (lockConfig.Years > 0) {
+			return fmt.Errorf("PutObjectLockConfig: %w: exactly one of days and years must be positive", ErrInvalidInput)
+		}
+		retention := &types.DefaultRetention{Mode: lockConfig.Mode}
+		if lockConfig.Days > 0 {
+			retention.Days = aws.Int32(lockConfig.Days)
+		} else {
+			retention.Years = aws.Int32(lockConfig.Years)
+		}
+		input.ObjectLockConfiguration.Rule = &types.ObjectLockRule{DefaultRetention: retention}
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return fmt.Errorf("PutObjectLockConfig/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.PutObjectLockConfiguration(ctx, input)
+	r.audit(ctx, "PutObjectLockConfiguration", "", start, err)
+	if err != nil {
+		return fmt.Errorf("PutObjectLockConfig/PutObjectLockConfiguration: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Метод для установки срока хранения загруженного файла. Срок в режиме COMPLIANCE можно только продлить;
+// сократить срок или сменить режим GOVERNANCE можно с опцией WithBypassGovernanceRetention.
+func (r *s3Manager) SetFileRetention(ctx context.Context, storagePath StoragePath, fileName string, retention Retention, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return fmt.Errorf("SetFileRetention: %w", err)
+	}
+
+	if fileName == "" {
+		return fmt.Errorf("SetFileRetention: %w: file name is empty", ErrInvalidInput)
+	}
+	err := retention.validate()
+	if err != nil {
+		return fmt.Errorf("SetFileRetention: %w", err)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return fmt.Errorf("SetFileRetention/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return fmt.Errorf("SetFileRetention/objectKey: %w", err)
+	}
+
+	input := &s3.PutObjectRetentionInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &fullPath,
+		VersionId: nonEmpty(o.versionID),
+		Retention: &types.ObjectLockRetention{
+			Mode:            retention.Mode,
+			RetainUntilDate: aws.Time(retention.RetainUntil),
+		},
+	}
+	if o.bypassGovernance {
+		input.BypassGovernanceRetention = aws.Bool(true)
+	}
+
+	start := time.Now()
+	_, err = client.PutObjectRetention(ctx, input)
+	r.audit(ctx, "PutObjectRetention", fullPath, start, err)
+	r.forgetObjects(ctx, fullPath)
+	if err != nil {
+		return fmt.Errorf("SetFileRetention/PutObjectRetention: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Метод для получения срока хранения файла. Если срок не установлен, возвращается nil без ошибки.
+func (r *s3Manager) GetFileRetention(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (*Retention, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return nil, fmt.Errorf("GetFileRetention: %w: file name is empty", ErrInvalidInput)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return nil, fmt.Errorf("GetFileRetention/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("GetFileRetention/objectKey: %w", err)
+	}
+
+	output, err := client.GetObjectRetention(ctx, &s3.GetObjectRetentionInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &fullPath,
+		VersionId: nonEmpty(o.versionID),
+	})
+	if err != nil {
+		if apiErrorCode(err) == "NoSuchObjectLockConfiguration" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetFileRetention/GetObjectRetention: %w", classifyError(err))
+	}
+	if output.Retention == nil || output.Retention.Mode == "" {
+		return nil, nil
+	}
+
+	return &Retention{
+		Mode:        output.Retention.Mode,
+		RetainUntil: aws.ToTime(output.Retention.RetainUntilDate),
+	}, nil
+}
+
+// Метод для установки или снятия юридической блокировки файла (legal hold). Пока блокировка установлена, файл нельзя удалить
+// или перезаписать независимо от срока хранения; снять её может пользователь с правом s3:PutObjectLegalHold.
+func (r *s3Manager) SetFileLegalHold(ctx context.Context, storagePath StoragePath, fileName string, enabled bool, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return fmt.Errorf("SetFileLegalHold: %w", err)
+	}
+
+	if fileName == "" {
+		return fmt.Errorf("SetFileLegalHold: %w: file name is empty", ErrInvalidInput)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return fmt.Errorf("SetFileLegalHold/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return fmt.Errorf("SetFileLegalHold/objectKey: %w", err)
+	}
+
+	start := time.Now()
+	_, err = client.PutObjectLegalHold(ctx, &s3.PutObjectLegalHoldInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &fullPath,
+		VersionId: nonEmpty(o.versionID),
+		LegalHold: &types.ObjectLockLegalHold{Status: legalHoldStatus(enabled)},
+	})
+	r.audit(ctx, "PutObjectLegalHold", fullPath, start, err)
+	r.forgetObjects(ctx, fullPath)
+	if err != nil {
+		return fmt.Errorf("SetFileLegalHold/PutObjectLegalHold: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Метод для проверки, установлена ли юридическая блокировка файла
+func (r *s3Manager) GetFileLegalHold(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (bool, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return false, fmt.Errorf("GetFileLegalHold: %w: file name is empty", ErrInvalidInput)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return false, fmt.Errorf("GetFileLegalHold/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return false, fmt.Errorf("GetFileLegalHold/objectKey: %w", err)
+	}
+
+	output, err := client.GetObjectLegalHold(ctx, &s3.GetObjectLegalHoldInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &fullPath,
+		VersionId: nonEmpty(o.versionID),
+	})
+	if err != nil {
+		if apiErrorCode(err) == "NoSuchObjectLockConfiguration" {
+			return false, nil
+		}
+		return false, fmt.Errorf("GetFileLegalHold/GetObjectLegalHold: %w", classifyError(err))
+	}
+
+	return output.LegalHold != nil && output.LegalHold.Status == types.ObjectLockLegalHoldStatusOn, nil
+}
+
+// Добавляет к загрузке объекта срок хранения и юридическую блокировку из опций WithRetention и WithLegalHold
+func (o operationOptions) applyObjectLock(input *s3.PutObjectInput) {
+	if o.retention != nil {
+		input.ObjectLockMode = types.ObjectLockMode(o.retention.Mode)
+		input.ObjectLockRetainUntilDate = aws.Time(o.retention.RetainUntil)
+	}
+	if o.legalHold {
+		input.ObjectLockLegalHoldStatus = types.ObjectLockLegalHoldStatusOn
+	}
+}
+
+// Возвращает статус юридической блокировки для запроса S3
+func legalHoldStatus(enabled bool) types.ObjectLockLegalHoldStatus {
+	if enabled {
+		return types.ObjectLockLegalHoldStatusOn
+	}
+	return types.ObjectLockLegalHoldStatusOff
+}
@@ -0,0 +1,461 @@
+package s3_manager
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Специальное значение ACL, при котором ACL не передаётся в запросе вовсе.
+// Используется для бакетов с отключёнными ACL (Object Ownership = BucketOwnerEnforced), где доступ управляется политиками бакета.
+const NoACL types.ObjectCannedACL = "none"
+
+// Функциональная опция для настройки отдельного вызова метода S3Manager.
+// Все методы принимают один и тот же тип опций; опции, не относящиеся к вызываемому методу, игнорируются.
+type Option func(*operationOptions)
+
+// Параметры отдельного вызова метода. Заполняются из Config и переопределяются опциями вызова.
+type operationOptions struct {
+	acl               types.ObjectCannedACL   // ACL загружаемого объекта
+	explicitACL       bool                    // ACL задан опцией WithACL, а не взят из конфига
+	contentType       string                  // MIME-тип загружаемого объекта
+	metadata          map[string]string       // Пользовательские метаданные загружаемого объекта (x-amz-meta-*)
+	encryption        Encryption              // Параметры шифрования объекта на стороне сервера
+	downloadName      string                  // Имя файла для скачивания (заголовок Content-Disposition в подписанной ссылке)
+	concurrency       int                     // Количество одновременных операций в пакетных методах
+	maxResults        int                     // Максимальное количество результатов в методах получения списков (0 - без ограничений)
+	bucketACL         types.BucketCannedACL   // ACL создаваемого бакета
+	versioning        bool                    // Включить версионирование бакета
+	cors              []CORSRule              // Правила CORS бакета
+	versionID         string                  // Версия объекта в бакете с версионированием
+	permanentDelete   bool                    // Удалять файлы окончательно, минуя корзину
+	contentLength     int64                   // Размер загружаемого файла в байтах, который подписывается в ссылке на загрузку
+	checksum          checksumValue           // Контрольная сумма загружаемого файла, которая подписывается в ссылке на загрузку
+	checksumAlgorithm types.ChecksumAlgorithm // Алгоритм контрольной суммы, которая вычисляется и проверяется при загрузке файла
+	validateChecksum  bool                    // Проверять контрольную сумму содержимого при скачивании файла
+	progress          ProgressFunc            // Функция для отслеживания прогресса загрузки или скачивания файла
+	maxFileSize       int64                   // Максимальный размер загружаемого файла в байтах (0 - без ограничений)
+	uniqueName        bool                    // Сохранять файл под уникальным именем (см. Config.NamingStrategy)
+	storedName        *string                 // Куда записать имя, под которым файл сохранён в бакете
+	ifNoneMatch       bool                    // Не перезаписывать существующий объект (см. WithIfNoneMatch)
+	notMatchETag      string                  // Скачивать файл, только если его ETag отличается (см. GetFileIfChanged)
+	modifiedSince     time.Time               // Скачивать файл, только если он изменён после этого времени (см. WithIfModifiedSince)
+	parallelDownload  bool                    // Скачивать файл параллельными частями (см. WithParallelDownload)
+	downloadPartSize  int64                   // Размер части при параллельном скачивании; 0 — из конфига
+	downloadWorkers   int                     // Количество одновременно скачиваемых частей; 0 — из конфига
+	deduplicate       bool                    // Не загружать файл, если в каталоге уже есть файл с таким же содержимым (см. WithDeduplication)
+	rawName           bool                    // Сохранять файл под переданным именем (см. withRawName)
+	rawKey            bool                    // Имя файла — полный ключ объекта в бакете (см. withRawKey)
+	compression       Compression             // Сжатие загружаемого файла (см. WithCompression)
+	contentEncoding   string                  // Content-Encoding загружаемого файла, содержимое которого уже сжато (см. withContentEncoding)
+	rawContent        bool                    // Не распаковывать сжатое содержимое при скачивании
+	imageValidated    bool                    // Изображение уже проверено по настройкам каталога изображений (см. withValidatedImage)
+	retention         *Retention              // Срок хранения загружаемого объекта (см. WithRetention)
+	legalHold         bool                    // Установить юридическую блокировку загружаемого объекта
+	bypassGovernance  bool                    // Обходить срок хранения в режиме GOVERNANCE
+	objectLock        bool                    // Включить блокировку объектов в создаваемом бакете
+	storageClass      types.StorageClass      // Класс хранения загружаемого или копируемого объекта
+	headers           ObjectHeaders           // HTTP-заголовки загружаемого объекта (см. WithCacheControl, WithContentDisposition, WithContentLanguage)
+	prefixFanout      bool                    // Обходить подкаталоги параллельно при подсчёте статистики
+	bucket            string                  // Бакет вызова вместо бакета из конфига (см. WithBucket)
+	rootCatalog       *string                 // Корневой каталог вызова вместо Config.RootCatalog (см. WithRootCatalog)
+}
+
+// Устанавливает ACL для загружаемого объекта (например, types.ObjectCannedACLPrivate). Переопределяет Config.DefaultACL.
+// Для загрузки без ACL используется NoACL. При копировании и перемещении файлов ACL передаётся только этой опцией.
+func WithACL(acl types.ObjectCannedACL) Option {
+	return func(o *operationOptions) {
+		o.acl = acl
+		o.explicitACL = true
+	}
+}
+
+// Устанавливает MIME-тип загружаемого объекта (например, "image/png"). Отключает автоматическое определение типа по имени и содержимому файла.
+// В GetUploadPresignedURL тип входит в подпись ссылки: клиент должен передать заголовок Content-Type с этим значением.
+func WithContentType(contentType string) Option {
+	return func(o *operationOptions) {
+		o.contentType = contentType
+	}
+}
+
+// Добавляет пользовательские метаданные к загружаемому объекту (передаются в заголовках x-amz-meta-*), например, исходное имя файла или ID загрузившего пользователя.
+// Метаданные возвращаются в FileInfo.Metadata методов GetFile и StatFile; S3 приводит ключи к нижнему регистру.
+// Значения с не-ASCII символами кодируются по RFC 2047 и декодируются при чтении.
+func WithMetadata(metadata map[string]string) Option {
+	return func(o *operationOptions) {
+		if o.metadata == nil {
+			o.metadata = make(map[string]string, len(metadata))
+		}
+		for key, value := range metadata {
+			o.metadata[key] = value
+		}
+	}
+}
+
+// Устанавливает параметры шифрования объекта на стороне сервера (SSE-S3, SSE-KMS или SSE-C). Переопределяет Config.Encryption.
+// Для объектов с SSE-C этот же ключ нужно передавать при чтении, получении информации и копировании объекта.
+func WithEncryption(encryption Encryption) Option {
+	return func(o *operationOptions) {
+		o.encryption = encryption
+	}
+}
+
+// Устанавливает имя, под которым браузер сохранит файл при переходе по подписанной ссылке на скачивание
+func WithDownloadName(downloadName string) Option {
+	return func(o *operationOptions) {
+		o.downloadName = downloadName
+	}
+}
+
+// Устанавливает количество одновременных операций в пакетных методах (например, PutFiles)
+func WithConcurrency(concurrency int) Option {
+	return func(o *operationOptions) {
+		if concurrency > 0 {
+			o.concurrency = concurrency
+		}
+	}
+}
+
+// Ограничивает количество результатов в методах получения списков файлов (например, GetFiles)
+func WithMaxResults(maxResults int) Option {
+	return func(o *operationOptions) {
+		o.maxResults = maxResults
+	}
+}
+
+// Устанавливает ACL создаваемого бакета в EnsureBucket (например, types.BucketCannedACLPrivate)
+func WithBucketACL(acl types.BucketCannedACL) Option {
+	return func(o *operationOptions) {
+		o.bucketACL = acl
+	}
+}
+
+// Включает версионирование бакета в EnsureBucket
+func WithVersioning() Option {
+	return func(o *operationOptions) {
+		o.versioning = true
+	}
+}
+
+// Устанавливает правила CORS бакета в EnsureBucket (заменяют существующие правила)
+func WithCORS(rules ...CORSRule) Option {
+	return func(o *operationOptions) {
+		o.cors = append(o.cors, rules...)
+	}
+}
+
+// Устанавливает версию объекта для чтения, получения информации, удаления или копирования (GetFile, StatFile, DeleteFile, CopyFile и т.д.).
+// Без опции используется текущая версия объекта. Список версий возвращает ListFileVersions.
+func WithVersionID(versionID string) Option {
+	return func(o *operationOptions) {
+		o.versionID = versionID
+	}
+}
+
+// Ограничивает размер файла, загружаемого по ссылке из GetUploadPresignedURL: размер входит в подпись,
+// и S3 отклонит загрузку с другим заголовком Content-Length
+func WithContentLength(size int64) Option {
+	return func(o *operationOptions) {
+		o.contentLength = size
+	}
+}
+
+// Задаёт ожидаемую контрольную сумму файла, загружаемого по ссылке из GetUploadPresignedURL (значение в base64, например SHA-256 содержимого).
+// S3 отклонит загрузку, если контрольная сумма содержимого не совпадёт.
+func WithChecksum(algorithm types.ChecksumAlgorithm, checksum string) Option {
+	return func(o *operationOptions) {
+		o.checksum = checksumValue{algorithm: algorithm, value: checksum}
+	}
+}
+
+// Включает проверку целостности загружаемого файла: контрольная сумма (например, types.ChecksumAlgorithmSha256 или types.ChecksumAlgorithmCrc32c)
+// вычисляется до отправки, передаётся в запросе и сверяется с ответом хранилища. При расхождении возвращается ErrChecksumMismatch.
+// Переопределяет Config.ChecksumAlgorithm.
+func WithChecksumAlgorithm(algorithm types.ChecksumAlgorithm) Option {
+	return func(o *operationOptions) {
+		o.checksumAlgorithm = algorithm
+	}
+}
+
+// Включает проверку целостности скачиваемого файла в GetFile и DownloadToWriter: контрольная сумма вычисляется при чтении потока
+// и сверяется с контрольной суммой объекта в хранилище (или с ETag, если он равен MD5 содержимого). При расхождении чтение потока
+// завершается ошибкой ErrChecksumMismatch. Объекты, для которых хранилище не возвращает ни контрольной суммы, ни MD5, не проверяются.
+func WithChecksumValidation() Option {
+	return func(o *operationOptions) {
+		o.validateChecksum = true
+	}
+}
+
+// Устанавливает функцию для отслеживания прогресса передачи файла в PutFile, GetFile, DownloadToWriter, DownloadRange и DownloadToFile
+// (например, для отображения прогресса загрузки видео). Функция вызывается после чтения каждого фрагмента данных; в пакетных методах
+// (PutFiles) она вызывается для каждого файла отдельно и может вызываться одновременно из нескольких горутин.
+func WithProgress(progress func(transferred, total int64)) Option {
+	return func(o *operationOptions) {
+		o.progress = progress
+	}
+}
+
+// Ограничивает размер загружаемого файла (в пакетных методах — каждого файла). Может только уменьшить лимит из Config.MaxUploadSize
+// и правил каталога. Файл большего размера не сохраняется, а вызов завершается ошибкой ErrFileTooLarge.
+func WithMaxFileSize(size int64) Option {
+	return func(o *operationOptions) {
+		o.maxFileSize = size
+	}
+}
+
+// Сохраняет загружаемый файл под уникальным именем, сформированным по Config.NamingStrategy, вместо BucketFile.Name,
+// чтобы одновременные загрузки файлов с одинаковым именем (например, "image.jpg" для одной сущности) не перезаписывали друг друга.
+// Сгенерированное имя входит в возвращаемую ссылку; получить само имя можно опцией WithStoredName.
+func WithUniqueName() Option {
+	return func(o *operationOptions) {
+		o.uniqueName = true
+	}
+}
+
+// Записывает в name имя, под которым файл сохранён в бакете после очистки (см. Config.NameSanitization) и генерации уникального имени
+// (см. WithUniqueName). По этому имени файл доступен в остальных методах. Используется только в PutFile.
+func WithStoredName(name *string) Option {
+	return func(o *operationOptions) {
+		o.storedName = name
+	}
+}
+
+// Загружает файл, только если объекта с таким ключом ещё нет (условный запрос If-None-Match: *). Если файл уже существует
+// или его одновременно загружает другой запрос, возвращается ErrPreconditionFailed, а существующий файл не изменяется.
+// Проверка выполняется хранилищем атомарно, в отличие от FileExists перед загрузкой. Используется в PutFile (см. PutFileIfAbsent).
+func WithIfNoneMatch() Option {
+	return func(o *operationOptions) {
+		o.ifNoneMatch = true
+	}
+}
+
+// Скачивает файл, только если он изменён после t (условный запрос If-Modified-Since, например, из заголовка запроса клиента).
+// Иначе возвращается ErrNotModified без передачи содержимого. Используется в GetFile и GetFileIfChanged.
+func WithIfModifiedSince(t time.Time) Option {
+	return func(o *operationOptions) {
+		o.modifiedSince = t
+	}
+}
+
+// Скачивает файл, только если его ETag отличается от etag (условный запрос If-None-Match). Используется GetFileIfChanged.
+func withIfNoneMatchETag(etag string) Option {
+	return func(o *operationOptions) {
+		o.notMatchETag = etag
+	}
+}
+
+// Скачивает файл в DownloadToFile параллельными диапазонными запросами (см. DownloadToWriterAt) с размером части partSize
+// и не более concurrency одновременных запросов (0 — значения из Config.DownloadPartSize и Config.DownloadConcurrency).
+// Прерванное параллельное скачивание не продолжается: временный файл удаляется, и следующий вызов скачивает файл заново.
+// В DownloadToWriterAt переопределяет размер части и количество запросов.
+func WithParallelDownload(partSize int64, concurrency int) Option {
+	return func(o *operationOptions) {
+		o.parallelDownload = true
+		o.downloadPartSize = partSize
+		o.downloadWorkers = concurrency
+	}
+}
+
+// Сохраняет загружаемый файл под именем из SHA-256 содержимого (как NamingContentHash) и не загружает его, если файл с таким именем
+// уже есть в каталоге: возвращается ссылка на существующий файл. Нужно, чтобы одинаковые файлы (например, одно изображение,
+// загруженное пользователем несколько раз) хранились один раз. Метаданные и настройки существующего файла не изменяются.
+// Требует потока с поддержкой Seek. Используется только в PutFile.
+func WithDeduplication() Option {
+	return func(o *operationOptions) {
+		o.deduplicate = true
+	}
+}
+
+// Сохраняет файл под переданным именем без очистки и генерации уникального имени. Используется методами, которые сохраняют
+// относительные пути файлов (SyncUp, MirrorPrefix) или формируют имена сами (варианты изображений в PutImage).
+func withRawName() Option {
+	return func(o *operationOptions) {
+		o.rawName = true
+	}
+}
+
+// Адресует файл полным ключом объекта в бакете: имя файла используется как ключ без корневого каталога и пути каталога.
+// Используется методами, которые работают с ключами из ListObjects (MirrorPrefix).
+func withRawKey() Option {
+	return func(o *operationOptions) {
+		o.rawKey = true
+	}
+}
+
+// Отключает проверку изображения в PutFile для файлов, которые PutImage уже проверил или создал сам (варианты меньше ImageConfig.MinWidth и т.п.)
+func withValidatedImage() Option {
+	return func(o *operationOptions) {
+		o.imageValidated = true
+	}
+}
+
+// Сжимает загружаемый файл алгоритмом compression (CompressionGzip или CompressionZstd). Переопределяет сжатие каталога по умолчанию
+// (см. AddCatalogWithCompression); CompressionNone отключает его. MIME-тип определяется по исходному содержимому.
+func WithCompression(compression Compression) Option {
+	return func(o *operationOptions) {
+		o.compression = compression
+	}
+}
+
+// Отключает распаковку сжатого содержимого в GetFile и DownloadToWriter: возвращается содержимое объекта как есть
+// (например, для передачи клиенту вместе с заголовком Content-Encoding из FileInfo).
+func WithoutDecompression() Option {
+	return func(o *operationOptions) {
+		o.rawContent = true
+	}
+}
+
+// Сохраняет заголовок Content-Encoding для файла, содержимое которого уже сжато (например, при копировании объекта между хранилищами)
+func withContentEncoding(encoding string) Option {
+	return func(o *operationOptions) {
+		o.contentEncoding = encoding
+	}
+}
+
+// Устанавливает класс хранения загружаемого или копируемого объекта (например, types.StorageClassStandardIa).
+// Переопределяет класс хранения каталога по умолчанию (см. AddCatalogWithStorageClass).
+func WithStorageClass(storageClass types.StorageClass) Option {
+	return func(o *operationOptions) {
+		o.storageClass = storageClass
+	}
+}
+
+// Устанавливает заголовок Cache-Control загружаемого файла (например, "public, max-age=86400"), с которым его отдают хранилище и CDN.
+// Переопределяет заголовок каталога по умолчанию (см. AddCatalogWithHeaders).
+func WithCacheControl(cacheControl string) Option {
+	return func(o *operationOptions) {
+		o.headers.CacheControl = cacheControl
+	}
+}
+
+// Устанавливает заголовок Content-Disposition загружаемого файла (например, `attachment; filename="report.pdf"`).
+// Переопределяет заголовок каталога по умолчанию (см. AddCatalogWithHeaders). Для ссылки на скачивание под другим именем используется WithDownloadName.
+func WithContentDisposition(contentDisposition string) Option {
+	return func(o *operationOptions) {
+		o.headers.ContentDisposition = contentDisposition
+	}
+}
+
+// Устанавливает заголовок Content-Language загружаемого файла (например, "ru").
+// Переопределяет заголовок каталога по умолчанию (см. AddCatalogWithHeaders).
+func WithContentLanguage(contentLanguage string) Option {
+	return func(o *operationOptions) {
+		o.headers.ContentLanguage = contentLanguage
+	}
+}
+
+// Обходит подкаталоги первого уровня параллельно при подсчёте статистики каталога (GetCatalogStats, GetCatalogTypeStats).
+// Ускоряет подсчёт для каталогов с большим количеством файлов, разложенных по подкаталогам (например, по сущностям).
+func WithPrefixFanout() Option {
+	return func(o *operationOptions) {
+		o.prefixFanout = true
+	}
+}
+
+// Устанавливает срок хранения загружаемого объекта (см. Retention). Переопределяет срок хранения по умолчанию бакета;
+// бакет должен быть создан с блокировкой объектов (см. WithObjectLock).
+func WithRetention(retention Retention) Option {
+	return func(o *operationOptions) {
+		o.retention = &retention
+	}
+}
+
+// Устанавливает юридическую блокировку загружаемого объекта (см. SetFileLegalHold)
+func WithLegalHold() Option {
+	return func(o *operationOptions) {
+		o.legalHold = true
+	}
+}
+
+// Позволяет удалить объект или сократить срок его хранения в режиме GOVERNANCE (DeleteFile, SetFileRetention).
+// Требует права s3:BypassGovernanceRetention; на режим COMPLIANCE и юридическую блокировку не действует.
+func WithBypassGovernanceRetention() Option {
+	return func(o *operationOptions) {
+		o.bypassGovernance = true
+	}
+}
+
+// Включает блокировку объектов при создании бакета в EnsureBucket (вместе с ней S3 включает версионирование).
+// Для существующего бакета опция не действует: блокировку можно включить только при создании.
+func WithObjectLock() Option {
+	return func(o *operationOptions) {
+		o.objectLock = true
+	}
+}
+
+// Удаляет файлы окончательно, минуя корзину (см. Config.TrashCatalog)
+func WithPermanentDelete() Option {
+	return func(o *operationOptions) {
+		o.permanentDelete = true
+	}
+}
+
+// Выполняет операцию с бакетом name вместо Config.Name на том же хранилище и с теми же учётными данными,
+// чтобы не создавать отдельный менеджер для каждого бакета. Каталоги, корзина и остальные настройки менеджера действуют и в этом бакете.
+// Config.CDN, Config.CDNSigner и Config.Invalidator относятся к бакету из конфига, поэтому ссылки на файлы формируются по адресу хранилища.
+func WithBucket(name string) Option {
+	return func(o *operationOptions) {
+		o.bucket = name
+	}
+}
+
+// Выполняет операцию с корневым каталогом root вместо Config.RootCatalog (например, для файлов другого сервиса в том же бакете).
+// Пустая строка означает корень бакета. Путь приводится к виду "path/to/catalog/"; в тестовом режиме к нему, как и к Config.RootCatalog, добавляется "test/".
+func WithRootCatalog(root string) Option {
+	return func(o *operationOptions) {
+		o.rootCatalog = &root
+	}
+}
+
+// Собирает параметры вызова: значения по умолчанию из конфига, затем переданные опции
+func (r *s3Manager) applyOptions(opts []Option) operationOptions {
+	o := operationOptions{
+		acl:               r.cfg.DefaultACL,
+		encryption:        r.cfg.Encryption,
+		checksumAlgorithm: r.cfg.ChecksumAlgorithm,
+		concurrency:       defaultBatchConcurrency,
+	}
+	if o.acl == "" {
+		o.acl = types.ObjectCannedACLPublicRead // Сохраняем прежнее поведение для конфигов без DefaultACL
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return o
+}
+
+// Возвращает менеджер для вызова с опциями WithBucket и WithRootCatalog: копию с другим Config.Name или Config.RootCatalog,
+// которая использует тот же клиент, каталоги и пул задач. Если опции не меняют конфиг, возвращает сам менеджер.
+func (r *s3Manager) forCall(opts []Option) *s3Manager {
+	o := r.applyOptions(opts)
+	otherBucket := o.bucket != "" && o.bucket != r.cfg.Name
+	rootCatalog := r.cfg.RootCatalog
+	if o.rootCatalog != nil {
+		rootCatalog = normalizeCatalog(*o.rootCatalog)
+		if r.isTestServer {
+			rootCatalog += "test/"
+		}
+	}
+	if !otherBucket && rootCatalog == r.cfg.RootCatalog && !o.rawKey {
+		return r
+	}
+
+	cfg := *r.cfg
+	cfg.RootCatalog = rootCatalog
+	if otherBucket {
+		cfg.Name = o.bucket
+		cfg.CDN = ""
+		cfg.CDNSigner = nil
+		cfg.Invalidator = nil
+	}
+
+	scoped := *r
+	scoped.cfg = &cfg
+	scoped.rawKeys = scoped.rawKeys || o.rawKey
+
+	return &scoped
+}
@@ -0,0 +1,49 @@
+package s3_manager
+
+import "sync"
+
+// Группа параллельно выполняемых задач пакетной операции с ограничением количества одновременно выполняемых задач
+type batch struct {
+	wg     sync.WaitGroup
+	local  chan struct{} // Ограничение для этой операции (см. WithConcurrency)
+	shared chan struct{} // Ограничение для всех пакетных операций менеджера (см. Config.MaxConcurrency), nil — без ограничения
+}
+
+// Создаёт группу задач, ограниченную concurrency задачами этой операции и общим ограничением shared
+func newBatch(concurrency int, shared chan struct{}) *batch {
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+	return &batch{local: make(chan struct{}, concurrency), shared: shared}
+}
+
+// Создаёт группу задач пакетной операции менеджера
+func (r *s3Manager) newBatch(concurrency int) *batch {
+	return newBatch(concurrency, r.workers)
+}
+
+// Запускает задачу в отдельной горутине. Если свободных мест нет, ждёт завершения одной из ранее запущенных задач.
+func (b *batch) Go(task func()) {
+	b.local <- struct{}{}
+	if b.shared != nil {
+		b.shared <- struct{}{}
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer func() {
+			if b.shared != nil {
+				<-b.shared
+			}
+			<-b.local
+		}()
+
+		task()
+	}()
+}
+
+// Ждёт завершения всех запущенных задач
+func (b *batch) Wait() {
+	b.wg.Wait()
+}
@@ -0,0 +1,99 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const (
+	maxPresignExpireTime = 7 * 24 * time.Hour // Максимальное время жизни подписанной ссылки, допустимое в S3 (SigV4)
+	presignCacheShare    = 0.8                // Доля времени жизни подписанной ссылки, в течение которой она отдаётся из кеша
+)
+
+// Определяет время жизни подписанной ссылки: переданное значение или Config.PresignedURLExpireTime, если оно не передано.
+// Значение ограничивается Config.MaxPresignedURLExpireTime и лимитом S3 в 7 дней.
+func (r *s3Manager) presignExpireTime(expireTime time.Duration) (time.Duration, error) {
+	if expireTime == 0 {
+		expireTime = r.cfg.PresignedURLExpireTime
+	}
+	if expireTime == 0 {
+		return 0, fmt.Errorf("%w: presigned URL expire time is not set and Config.PresignedURLExpireTime is empty", ErrInvalidInput)
+	}
+	if expireTime < 0 {
+		return 0, fmt.Errorf("%w: presigned URL expire time %s is negative", ErrInvalidInput, expireTime)
+	}
+
+	maxExpireTime := maxPresignExpireTime
+	if r.cfg.MaxPresignedURLExpireTime > 0 && r.cfg.MaxPresignedURLExpireTime < maxExpireTime {
+		maxExpireTime = r.cfg.MaxPresignedURLExpireTime
+	}
+
+	return min(expireTime, maxExpireTime), nil
+}
+
+// Учётные данные, которыми клиент подпишет ссылку. Нужны только для кеша ссылок: ссылка, подписанная временными учётными данными
+// (STS, Config.Credentials), перестаёт действовать вместе с ними, даже если её собственный срок не истёк.
+func (r *s3Manager) presignCredentials(ctx context.Context, client *s3.Client) (aws.Credentials, error) {
+	provider := client.Options().Credentials
+	if r.presignCache == nil || provider == nil {
+		return aws.Credentials{}, nil
+	}
+
+	credentials, err := provider.Retrieve(ctx)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("presignCredentials/Retrieve: %w", err)
+	}
+
+	return credentials, nil
+}
+
+// Ключ кеша подписанной ссылки на скачивание: все параметры, которые попадают в ссылку, включая адрес хранилища и ключ доступа,
+// которым она подписана (ключ SSE-C хешируется вместе с остальными параметрами). Возвращает пустую строку, если ссылки не кешируются.
+func (r *s3Manager) presignCacheKey(input *s3.GetObjectInput, expireTime time.Duration, credentials aws.Credentials) string {
+	if r.presignCache == nil {
+		return ""
+	}
+
+	return cacheKeyPrefix + "presign:" + hashCacheKey(fmt.Sprintf("%q %q %q %q %q %q %d %q",
+		r.endpoint(), aws.ToString(input.Bucket), aws.ToString(input.Key), aws.ToString(input.VersionId),
+		aws.ToString(input.ResponseContentDisposition), aws.ToString(input.SSECustomerKey), expireTime, credentials.AccessKeyID,
+	))
+}
+
+// Возвращает подписанную ссылку из кеша. Ошибка кеша только логируется: ссылка подписывается заново.
+func (r *s3Manager) cachedPresignedURL(ctx context.Context, cacheKey string) (string, bool) {
+	if cacheKey == "" {
+		return "", false
+	}
+
+	presignedURL, ok, err := r.presignCache.Get(ctx, cacheKey)
+	if err != nil && r.cfg.Logger != nil {
+		r.cfg.Logger.WarnContext(ctx, "presigned url cache get failed", "error", err)
+	}
+
+	return string(presignedURL), ok && err == nil
+}
+
+// Сохраняет подписанную ссылку в кеш на 80% её времени жизни, чтобы ссылка из кеша не истекала сразу после выдачи.
+// Для временных учётных данных время в кеше ограничивается тем же запасом от срока их действия.
+func (r *s3Manager) storePresignedURL(ctx context.Context, cacheKey, presignedURL string, expireTime time.Duration, credentials aws.Credentials) {
+	if cacheKey == "" {
+		return
+	}
+
+	ttl := time.Duration(float64(expireTime) * presignCacheShare)
+	if credentials.CanExpire {
+		ttl = min(ttl, time.Duration(float64(time.Until(credentials.Expires))*presignCacheShare))
+	}
+	if ttl <= 0 {
+		return
+	}
+	err := r.presignCache.Set(ctx, cacheKey, []byte(presignedURL), ttl)
+	if err != nil && r.cfg.Logger != nil {
+		r.cfg.Logger.WarnContext(ctx, "presigned url cache set failed", "error", err)
+	}
+}
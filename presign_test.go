@@ -0,0 +1,88 @@
+package s3_manager
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Кеш в памяти, который запоминает время жизни сохранённых записей
+type ttlRecordingCache struct {
+	*MemoryCache
+	mu   sync.Mutex
+	ttls []time.Duration
+}
+
+func (c *ttlRecordingCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	c.ttls = append(c.ttls, ttl)
+	c.mu.Unlock()
+	return c.MemoryCache.Set(ctx, key, value, ttl)
+}
+
+func newPresignTestManager(t *testing.T, cache Cache, accessKeyID string, expires time.Time) S3Manager {
+	t.Helper()
+
+	manager, err := NewS3Manager(context.Background(), &Config{
+		Endpoint:               "http://s3.test",
+		Name:                   "b",
+		Region:                 "us-east-1",
+		PresignedURLExpireTime: time.Hour,
+		Cache:                  cache,
+		CachePresignedURLs:     true,
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: accessKeyID, SecretAccessKey: "secret", SessionToken: "token", CanExpire: true, Expires: expires}, nil
+		}),
+	}, false)
+	if err != nil {
+		t.Fatalf("NewS3Manager: %v", err)
+	}
+
+	return manager
+}
+
+func TestPresignCacheRespectsCredentialExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := &ttlRecordingCache{MemoryCache: NewMemoryCache(100)}
+	manager := newPresignTestManager(t, cache, "AKIA1", time.Now().Add(10*time.Minute))
+
+	if _, err := manager.GetDownloadPresignedURL(ctx, StoragePath{}, "a.txt", 0); err != nil {
+		t.Fatalf("GetDownloadPresignedURL: %v", err)
+	}
+
+	if len(cache.ttls) != 1 {
+		t.Fatalf("cache sets = %d, want 1", len(cache.ttls))
+	}
+	// Срок ссылки — час, но учётные данные истекают через 10 минут: ссылка не должна храниться в кеше дольше 80% от них
+	if ttl := cache.ttls[0]; ttl <= 0 || ttl > 8*time.Minute {
+		t.Errorf("cache TTL = %s, want at most %s", ttl, 8*time.Minute)
+	}
+}
+
+func TestPresignCacheKeyIncludesAccessKey(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(100)
+	expires := time.Now().Add(time.Hour)
+
+	var credentials []string
+	for _, accessKeyID := range []string{"AKIA1", "AKIA2"} {
+		manager := newPresignTestManager(t, cache, accessKeyID, expires)
+		presignedURL, err := manager.GetDownloadPresignedURL(ctx, StoragePath{}, "a.txt", 0)
+		if err != nil {
+			t.Fatalf("GetDownloadPresignedURL: %v", err)
+		}
+		parsed, err := url.Parse(presignedURL)
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		credentials = append(credentials, parsed.Query().Get("X-Amz-Credential"))
+	}
+
+	if credentials[0] == credentials[1] {
+		t.Errorf("URL signed with rotated key was served from cache: %q", credentials[1])
+	}
+}
@@ -0,0 +1,199 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Multipart-загрузка, части которой клиент (например, браузер) загружает напрямую в бакет по подписанным ссылкам
+type PresignedMultipartUpload struct {
+	UploadID string   // Идентификатор загрузки (передаётся в CompleteMultipart и AbortMultipart)
+	Key      string   // Полный ключ объекта в бакете
+	PartSize int64    // Размер каждой части в байтах (последняя часть может быть меньше)
+	PartURLs []string // Подписанные ссылки для загрузки частей методом PUT: PartURLs[0] — часть 1, PartURLs[1] — часть 2 и т.д.
+}
+
+// Загруженная часть multipart-загрузки
+type UploadedPart struct {
+	PartNumber int32  // Номер части (начиная с 1)
+	ETag       string // Значение заголовка ETag из ответа на загрузку части (для чтения в браузере нужен ExposeHeaders: ETag в CORS бакета)
+}
+
+// Метод для начала multipart-загрузки файла размером size байт с подписанными ссылками на загрузку каждой части.
+// Клиент загружает части по ссылкам (в любом порядке и параллельно), а затем сервис вызывает CompleteMultipart с полученными ETag частей.
+// ACL, MIME-тип, метаданные и шифрование задаются опциями так же, как в PutFile.
+// Если для каталога заданы правила (см. AddCatalogWithRules), имя, MIME-тип (WithContentType) и размер файла проверяются по ним.
+func (r *s3Manager) CreatePresignedMultipart(ctx context.Context, storagePath StoragePath, fileName string, size int64, expireTime time.Duration, opts ...Option) (*PresignedMultipartUpload, error) {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return nil, fmt.Errorf("CreatePresignedMultipart: %w", err)
+	}
+
+	if fileName == "" {
+		return nil, fmt.Errorf("CreatePresignedMultipart: %w: file name is empty", ErrInvalidInput)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("CreatePresignedMultipart: %w: file size must be positive", ErrInvalidInput)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return nil, fmt.Errorf("CreatePresignedMultipart/s3Client: %w", err)
+	}
+	expireTime, err = r.presignExpireTime(expireTime)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePresignedMultipart/presignExpireTime: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePresignedMultipart/objectKey: %w", err)
+	}
+
+	err = r.uploadRules(storagePath).checkPresigned(fileName, o.contentType, size)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePresignedMultipart/checkPresigned: %w", err)
+	}
+
+	createInput := &s3.CreateMultipartUploadInput{
+		Bucket: &r.cfg.Name,
+		Key:    &fullPath,
+	}
+	if o.acl != NoACL {
+		createInput.ACL = o.acl
+	}
+	o.encryption.applyToCreateMultipart(createInput)
+	contentType := o.contentType
+	if contentType == "" {
+		contentType = contentTypeByExtension(fileName)
+	}
+	if contentType != "" {
+		createInput.ContentType = &contentType
+	}
+	if len(o.metadata) > 0 {
+		createInput.Metadata = encodeMetadata(o.metadata)
+	}
+
+	createOutput, err := client.CreateMultipartUpload(ctx, createInput)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePresignedMultipart/CreateMultipartUpload: %w", classifyError(err))
+	}
+
+	partSize := r.multipartPartSize(size)
+	partCount := (size + partSize - 1) / partSize
+	presignClient := s3.NewPresignClient(client)
+
+	upload := &PresignedMultipartUpload{
+		UploadID: aws.ToString(createOutput.UploadId),
+		Key:      fullPath,
+		PartSize: partSize,
+		PartURLs: make([]string, 0, partCount),
+	}
+	for partNumber := int32(1); int64(partNumber) <= partCount; partNumber++ {
+		partInput := &s3.UploadPartInput{
+			Bucket:     &r.cfg.Name,
+			Key:        &fullPath,
+			UploadId:   createOutput.UploadId,
+			PartNumber: aws.Int32(partNumber),
+		}
+		o.encryption.applyToUploadPart(partInput) // Для SSE-C клиент должен передать ключ в заголовках при загрузке каждой части
+
+		presignedRequest, err := presignClient.PresignUploadPart(ctx, partInput, s3.WithPresignExpires(expireTime))
+		if err != nil {
+			r.abortMultipart(ctx, &r.cfg.Name, &fullPath, createOutput.UploadId)
+			return nil, fmt.Errorf("CreatePresignedMultipart/PresignUploadPart: part %d: %w", partNumber, classifyError(err))
+		}
+		upload.PartURLs = append(upload.PartURLs, presignedRequest.URL)
+	}
+
+	return upload, nil
+}
+
+// Метод для завершения multipart-загрузки, начатой через CreatePresignedMultipart. Возвращает ссылку на загруженный файл.
+func (r *s3Manager) CompleteMultipart(ctx context.Context, storagePath StoragePath, fileName, uploadID string, parts []UploadedPart, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" || uploadID == "" {
+		return "", fmt.Errorf("CompleteMultipart: %w: file name or upload ID is empty", ErrInvalidInput)
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("CompleteMultipart: %w: no parts uploaded", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return "", fmt.Errorf("CompleteMultipart/objectKey: %w", err)
+	}
+
+	completedParts := make([]types.CompletedPart, 0, len(parts))
+	for _, part := range parts {
+		completedParts = append(completedParts, types.CompletedPart{
+			PartNumber: aws.Int32(part.PartNumber),
+			ETag:       aws.String(part.ETag),
+		})
+	}
+	// S3 требует, чтобы части были перечислены по возрастанию номеров
+	sort.Slice(completedParts, func(i, j int) bool {
+		return aws.ToInt32(completedParts[i].PartNumber) < aws.ToInt32(completedParts[j].PartNumber)
+	})
+
+	_, err = r.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &r.cfg.Name,
+		Key:             &fullPath,
+		UploadId:        &uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completedParts},
+	})
+	if err != nil {
+		return "", fmt.Errorf("CompleteMultipart/CompleteMultipartUpload: %w", classifyError(err))
+	}
+	r.invalidate(ctx, fullPath)
+
+	fileURL, err := r.GetObjectURL(storagePath, fileName, opts...)
+	if err != nil {
+		return "", fmt.Errorf("CompleteMultipart/GetObjectURL: %w", err)
+	}
+
+	return fileURL, nil
+}
+
+// Метод для отмены multipart-загрузки, начатой через CreatePresignedMultipart. Уже загруженные части удаляются.
+func (r *s3Manager) AbortMultipart(ctx context.Context, storagePath StoragePath, fileName, uploadID string, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if fileName == "" || uploadID == "" {
+		return fmt.Errorf("AbortMultipart: %w: file name or upload ID is empty", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return fmt.Errorf("AbortMultipart/objectKey: %w", err)
+	}
+
+	_, err = r.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   &r.cfg.Name,
+		Key:      &fullPath,
+		UploadId: &uploadID,
+	})
+	if err != nil {
+		return fmt.Errorf("AbortMultipart/AbortMultipartUpload: %w", classifyError(err))
+	}
+
+	return nil
+}
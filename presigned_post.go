@@ -0,0 +1,132 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+const maxObjectSize int64 = 5 << 40 // Максимальный размер объекта в S3 (5 ТиБ)
+
+// Ограничения подписанной формы загрузки (POST policy). S3 отклоняет загрузку, если файл им не соответствует.
+type PostPolicy struct {
+	FileName          string        // Имя файла. Если не указано, клиент может загрузить файл под любым именем в каталог (подставляется имя выбранного файла).
+	KeyPrefix         string        // Подкаталог внутри каталога StoragePath, в который разрешена загрузка (например, "uploads/")
+	MinSize           int64         // Минимальный размер файла в байтах
+	MaxSize           int64         // Максимальный размер файла в байтах (0 - без ограничений)
+	ContentType       string        // Точный MIME-тип файла (например, "image/png"). Передаётся в полях формы.
+	ContentTypePrefix string        // Префикс MIME-типа файла (например, "image/"). Клиент должен сам передать поле Content-Type в форме.
+	ExpireTime        time.Duration // Время жизни формы. По умолчанию Config.PresignedURLExpireTime.
+}
+
+// Подписанная форма загрузки: клиент отправляет POST-запрос multipart/form-data на URL со всеми полями Fields и полем file (последним)
+type PresignedPOST struct {
+	URL    string            // Адрес для отправки формы
+	Fields map[string]string // Поля формы (key, policy, подпись и т.д.)
+}
+
+// Метод для получения подписанной формы загрузки файла в бакет. В отличие от GetUploadPresignedURL, позволяет ограничить размер,
+// MIME-тип и путь загружаемого файла. ACL, метаданные и шифрование (SSE-S3, SSE-KMS) задаются опциями так же, как в PutFile.
+// Если для каталога заданы правила (см. AddCatalogWithRules), политика проверяется по ним, а размер без MaxSize ограничивается UploadRules.MaxSize
+// или Config.MaxUploadSize.
+func (r *s3Manager) GetUploadPresignedPOST(ctx context.Context, storagePath StoragePath, policy PostPolicy, opts ...Option) (*PresignedPOST, error) {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return nil, fmt.Errorf("GetUploadPresignedPOST: %w", err)
+	}
+
+	if policy.MaxSize < 0 || policy.MinSize < 0 || (policy.MaxSize > 0 && policy.MinSize > policy.MaxSize) {
+		return nil, fmt.Errorf("GetUploadPresignedPOST: %w: invalid size range %d-%d", ErrInvalidInput, policy.MinSize, policy.MaxSize)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return nil, fmt.Errorf("GetUploadPresignedPOST/s3Client: %w", err)
+	}
+
+	o := r.applyOptions(opts)
+	if len(o.encryption.CustomerKey) > 0 {
+		return nil, fmt.Errorf("GetUploadPresignedPOST: %w: SSE-C is not supported for presigned forms", ErrInvalidInput)
+	}
+
+	rules := r.uploadRules(storagePath)
+	rules.MaxSize = r.maxUploadSize(rules, o)
+	policy, err = rules.applyToPolicy(policy)
+	if err != nil {
+		return nil, fmt.Errorf("GetUploadPresignedPOST/applyToPolicy: %w", err)
+	}
+
+	presignClient := s3.NewPresignClient(client)
+	keyPrefix, err := r.objectKey(storagePath, policy.KeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("GetUploadPresignedPOST/objectKey: %w", err)
+	}
+
+	fields := make(map[string]string)
+	var conditions []interface{}
+	addField := func(name, value string) {
+		fields[name] = value
+		conditions = append(conditions, map[string]string{name: value})
+	}
+
+	key := keyPrefix + policy.FileName
+	if policy.FileName == "" {
+		key = keyPrefix + "${filename}" // S3 подставляет имя файла из формы
+		conditions = append(conditions, []interface{}{"starts-with", "$key", keyPrefix})
+	}
+
+	if policy.MaxSize > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", policy.MinSize, policy.MaxSize})
+	} else if policy.MinSize > 0 {
+		conditions = append(conditions, []interface{}{"content-length-range", policy.MinSize, maxObjectSize})
+	}
+
+	switch {
+	case policy.ContentType != "":
+		addField("Content-Type", policy.ContentType)
+	case policy.ContentTypePrefix != "":
+		conditions = append(conditions, []interface{}{"starts-with", "$Content-Type", policy.ContentTypePrefix})
+	}
+
+	if o.acl != NoACL {
+		addField("acl", string(o.acl))
+	}
+	for name, value := range encodeMetadata(o.metadata) {
+		addField("x-amz-meta-"+name, value)
+	}
+	if o.encryption.Type != "" {
+		addField("x-amz-server-side-encryption", string(o.encryption.Type))
+	}
+	if o.encryption.KMSKeyID != "" {
+		addField("x-amz-server-side-encryption-aws-kms-key-id", o.encryption.KMSKeyID)
+	}
+
+	expireTime, err := r.presignExpireTime(policy.ExpireTime)
+	if err != nil {
+		return nil, fmt.Errorf("GetUploadPresignedPOST/presignExpireTime: %w", err)
+	}
+
+	putInput := &s3.PutObjectInput{
+		Bucket: &r.cfg.Name,
+		Key:    &key,
+	}
+	presignedRequest, err := presignClient.PresignPostObject(ctx, putInput, func(po *s3.PresignPostOptions) {
+		po.Expires = expireTime
+		po.Conditions = conditions
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetUploadPresignedPOST/PresignPostObject: failed to create presigned request: %w", classifyError(err))
+	}
+
+	for name, value := range presignedRequest.Values {
+		fields[name] = value
+	}
+
+	return &PresignedPOST{
+		URL:    presignedRequest.URL,
+		Fields: fields,
+	}, nil
+}
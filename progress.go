@@ -0,0 +1,65 @@
+package s3_manager
+
+import "io"
+
+// Функция для отслеживания прогресса передачи файла: transferred — количество переданных байт, total — размер файла (-1, если неизвестен)
+type ProgressFunc func(transferred, total int64)
+
+// Поток, который сообщает о количестве прочитанных байт. Если поток перематывается назад (например, SDK повторно читает тело запроса
+// для вычисления подписи или при повторной попытке), прогресс не уменьшается, а продолжается после достижения прежней позиции.
+type progressReader struct {
+	reader   io.Reader
+	progress ProgressFunc
+	total    int64
+	position int64
+	reported int64
+}
+
+func (p *progressReader) Read(buf []byte) (int, error) {
+	n, err := p.reader.Read(buf)
+	p.position += int64(n)
+	if p.position > p.reported {
+		p.reported = p.position
+		p.progress(p.reported, p.total)
+	}
+	return n, err
+}
+
+// Поток с поддержкой Seek, который сообщает о количестве прочитанных байт
+type progressReadSeeker struct {
+	*progressReader
+	seeker io.Seeker
+	start  int64 // Позиция потока на момент начала передачи: прогресс считается от неё
+}
+
+func (p *progressReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	position, err := p.seeker.Seek(offset, whence)
+	if err == nil {
+		p.position = position - p.start
+	}
+	return position, err
+}
+
+// Поток с поддержкой Close, который сообщает о количестве прочитанных байт
+type progressReadCloser struct {
+	*progressReader
+	io.Closer
+}
+
+// Оборачивает тело загружаемого объекта. Поддержка Seek сохраняется, так как от неё зависит способ загрузки (см. putObject).
+func newProgressReader(reader io.Reader, total int64, progress ProgressFunc) io.Reader {
+	p := &progressReader{reader: reader, progress: progress, total: total}
+	if seeker, ok := reader.(io.Seeker); ok {
+		start, _ := seeker.Seek(0, io.SeekCurrent)
+		return &progressReadSeeker{progressReader: p, seeker: seeker, start: start}
+	}
+	return p
+}
+
+// Оборачивает тело скачиваемого объекта
+func newProgressReadCloser(body io.ReadCloser, total int64, progress ProgressFunc) io.ReadCloser {
+	return &progressReadCloser{
+		progressReader: &progressReader{reader: body, progress: progress, total: total},
+		Closer:         body,
+	}
+}
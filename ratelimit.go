@@ -0,0 +1,203 @@
+package s3_manager
+
+import (
+	"context"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/time/rate"
+)
+
+const minBandwidthBurst = 32 << 10 // Минимальный объём данных, который можно передать без ожидания (32 КиБ): размер одного чтения из потока
+
+// Ограничение нагрузки на хранилище со стороны одного менеджера. Нужно, например, чтобы фоновая синхронизация не мешала запросам пользователей.
+type RateLimit struct {
+	RequestsPerSecond float64 // Максимальное количество запросов к хранилищу в секунду. По умолчанию не ограничено.
+	Burst             int     // Количество запросов, которые можно выполнить подряд без ожидания. По умолчанию равно RequestsPerSecond (но не меньше 1).
+	BytesPerSecond    int64   // Максимальная скорость загрузки и скачивания файлов вместе, в байтах в секунду. По умолчанию не ограничена.
+}
+
+// Хранилище, которое ограничивает частоту запросов и скорость передачи данных. Подключается автоматически, если в конфиге указан RateLimit.
+// Подписанные ссылки и операции с бакетом (например, EnsureBucket) не ограничиваются.
+type rateLimitedBackend struct {
+	next      StorageBackend
+	requests  *rate.Limiter // nil — частота запросов не ограничена
+	bandwidth *rate.Limiter // nil — скорость передачи данных не ограничена
+}
+
+var _ StorageBackend = (*rateLimitedBackend)(nil)
+
+// Создаёт обёртку над хранилищем с ограничениями из limit. Если ограничения не заданы, возвращает хранилище без изменений.
+func newRateLimitedBackend(next StorageBackend, limit RateLimit) StorageBackend {
+	if limit.RequestsPerSecond <= 0 && limit.BytesPerSecond <= 0 {
+		return next
+	}
+
+	b := &rateLimitedBackend{next: next}
+	if limit.RequestsPerSecond > 0 {
+		burst := limit.Burst
+		if burst <= 0 {
+			burst = max(int(limit.RequestsPerSecond), 1)
+		}
+		b.requests = rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), burst)
+	}
+	if limit.BytesPerSecond > 0 {
+		b.bandwidth = rate.NewLimiter(rate.Limit(limit.BytesPerSecond), int(max(limit.BytesPerSecond, minBandwidthBurst)))
+	}
+
+	return b
+}
+
+// Возвращает хранилище, вокруг которого построена обёртка
+func (b *rateLimitedBackend) Unwrap() StorageBackend {
+	return b.next
+}
+
+func (b *rateLimitedBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	input := *params
+	input.Body = b.throttle(ctx, params.Body)
+	return b.next.PutObject(ctx, &input, optFns...)
+}
+
+func (b *rateLimitedBackend) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	output, err := b.next.GetObject(ctx, params, optFns...)
+	if err != nil {
+		return nil, err
+	}
+	if b.bandwidth != nil && output.Body != nil {
+		output.Body = &throttledReadCloser{throttledReader: &throttledReader{ctx: ctx, reader: output.Body, limiter: b.bandwidth}, Closer: output.Body}
+	}
+	return output, nil
+}
+
+func (b *rateLimitedBackend) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.HeadObject(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.ListObjectsV2(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.DeleteObject(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.DeleteObjects(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.CopyObject(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.CreateMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	input := *params
+	input.Body = b.throttle(ctx, params.Body)
+	return b.next.UploadPart(ctx, &input, optFns...)
+}
+
+func (b *rateLimitedBackend) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.UploadPartCopy(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.CompleteMultipartUpload(ctx, params, optFns...)
+}
+
+func (b *rateLimitedBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	if err := b.wait(ctx); err != nil {
+		return nil, err
+	}
+	return b.next.AbortMultipartUpload(ctx, params, optFns...)
+}
+
+// Ждёт, пока частота запросов позволит выполнить следующий запрос
+func (b *rateLimitedBackend) wait(ctx context.Context) error {
+	if b.requests == nil {
+		return nil
+	}
+	return b.requests.Wait(ctx)
+}
+
+// Ограничивает скорость чтения тела загружаемого объекта. Поддержка Seek сохраняется (SDK перематывает тело при повторных попытках).
+func (b *rateLimitedBackend) throttle(ctx context.Context, body io.Reader) io.Reader {
+	if b.bandwidth == nil || body == nil {
+		return body
+	}
+
+	reader := &throttledReader{ctx: ctx, reader: body, limiter: b.bandwidth}
+	if seeker, ok := body.(io.ReadSeeker); ok {
+		return &throttledReadSeeker{throttledReader: reader, Seeker: seeker}
+	}
+	return reader
+}
+
+// Поток, скорость чтения которого ограничена
+type throttledReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *rate.Limiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > t.limiter.Burst() {
+		p = p[:t.limiter.Burst()] // WaitN не допускает ожидания больше burst байт за раз
+	}
+
+	n, err := t.reader.Read(p)
+	if n > 0 {
+		if waitErr := t.limiter.WaitN(t.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// Поток с поддержкой Seek, скорость чтения которого ограничена
+type throttledReadSeeker struct {
+	*throttledReader
+	io.Seeker
+}
+
+// Поток с поддержкой Close, скорость чтения которого ограничена
+type throttledReadCloser struct {
+	*throttledReader
+	io.Closer
+}
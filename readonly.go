@@ -0,0 +1,79 @@
+package s3_manager
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Хранилище, которое отклоняет изменяющие запросы с ошибкой ErrReadOnly (запросы на чтение передаются без изменений).
+// Подключается автоматически, если в конфиге включён ReadOnly. Запрос отклоняется до обращения к хранилищу,
+// поэтому даже ошибка в коде сервиса не может изменить бакет.
+type readOnlyBackend struct {
+	next StorageBackend
+}
+
+var _ StorageBackend = (*readOnlyBackend)(nil)
+
+// Возвращает хранилище, вокруг которого построена обёртка
+func (b *readOnlyBackend) Unwrap() StorageBackend {
+	return b.next
+}
+
+func (b *readOnlyBackend) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	return b.next.GetObject(ctx, params, optFns...)
+}
+
+func (b *readOnlyBackend) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	return b.next.HeadObject(ctx, params, optFns...)
+}
+
+func (b *readOnlyBackend) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	return b.next.ListObjectsV2(ctx, params, optFns...)
+}
+
+func (b *readOnlyBackend) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	return nil, ErrReadOnly
+}
+
+func (b *readOnlyBackend) AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	return nil, ErrReadOnly
+}
+
+// Возвращает ErrReadOnly, если менеджер работает только на чтение. Проверяется в методах, которые изменяют бакет в обход StorageBackend
+// (настройки бакета, блокировки объектов) или выдают клиенту право на загрузку (подписанные ссылки и формы).
+func (r *s3Manager) checkWritable() error {
+	if r.cfg.ReadOnly {
+		return ErrReadOnly
+	}
+
+	return nil
+}
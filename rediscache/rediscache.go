@@ -0,0 +1,68 @@
+// Пакет с реализацией кеша менеджера (s3_manager.Cache) в Redis. Вынесен из основного пакета, чтобы go-redis подключался
+// только в сервисах, которые используют этот кеш.
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	s3_manager "s3-manager"
+)
+
+// Кеш в Redis: общий для нескольких экземпляров сервиса, поэтому изменение файла через один экземпляр сбрасывает кеш и у остальных.
+// Подходит любой клиент go-redis: *redis.Client, *redis.ClusterClient или клиент с Sentinel.
+type Cache struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ s3_manager.Cache = (*Cache)(nil)
+
+// Создаёт кеш поверх клиента Redis. Префикс добавляется к ключам (например, "catalog:"), чтобы отделить записи менеджера от других данных в той же базе.
+func New(client redis.UniversalClient, prefix string) *Cache {
+	return &Cache{client: client, prefix: prefix}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("Cache.Get: %w", err)
+	}
+
+	return value, true, nil
+}
+
+// Сохраняет значение на время ttl (0 — без ограничения времени)
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := c.client.Set(ctx, c.prefix+key, value, ttl).Err()
+	if err != nil {
+		return fmt.Errorf("Cache.Set: %w", err)
+	}
+
+	return nil
+}
+
+// Удаляет ключи по одному в конвейере: в Redis Cluster ключи могут находиться в разных слотах, и DEL с несколькими ключами вернул бы ошибку
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	pipe := c.client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, c.prefix+key)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("Cache.Delete: %w", err)
+	}
+
+	return nil
+}
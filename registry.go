@@ -0,0 +1,143 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// Набор именованных менеджеров для сервисов, которые работают с несколькими бакетами или хранилищами
+// (например, "public" для публичных файлов и "documents" для приватных документов). Менеджеры создаются при первом обращении (Get),
+// поэтому недоступное хранилище, которое сервису не понадобилось, не мешает его запуску. Менеджеры с Config.UseDefaultCredentials
+// используют общие учётные данные стандартной цепочки AWS SDK: роль или токен IRSA запрашиваются один раз для всех бакетов.
+// Методы можно вызывать из нескольких горутин одновременно.
+type ManagerRegistry struct {
+	mu                 sync.Mutex
+	entries            map[string]*registryEntry
+	defaultCredentials aws.CredentialsProvider // Общие учётные данные стандартной цепочки, загружаются при создании первого менеджера с UseDefaultCredentials
+}
+
+// Менеджер в наборе: готовый или параметры для его создания
+type registryEntry struct {
+	cfg          *Config
+	isTestServer bool
+	setup        func(S3Manager) // Настройка созданного менеджера (например, регистрация каталогов)
+	manager      S3Manager
+}
+
+// Создаёт пустой набор менеджеров
+func NewManagerRegistry() *ManagerRegistry {
+	return &ManagerRegistry{entries: make(map[string]*registryEntry)}
+}
+
+// Метод для регистрации менеджера с именем name. Менеджер создаётся NewS3Manager при первом вызове Get, после чего вызывается setup
+// (если не nil), например, для регистрации каталогов через AddCatalog. Конфиг копируется, поэтому его изменения после регистрации не учитываются.
+func (r *ManagerRegistry) Register(name string, cfg *Config, isTestServer bool, setup func(S3Manager)) error {
+	if name == "" || cfg == nil {
+		return fmt.Errorf("ManagerRegistry.Register: %w: name or config is empty", ErrInvalidInput)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[name]; ok {
+		return fmt.Errorf("ManagerRegistry.Register: %w: manager %q is already registered", ErrInvalidInput, name)
+	}
+
+	cfgCopy := *cfg
+	err := cfgCopy.validate(true) // Ошибки конфига обнаруживаются сразу, а не при первом обращении к менеджеру
+	if err != nil {
+		return fmt.Errorf("ManagerRegistry.Register/validate: %s: %w", name, err)
+	}
+	r.entries[name] = &registryEntry{cfg: &cfgCopy, isTestServer: isTestServer, setup: setup}
+
+	return nil
+}
+
+// Метод для добавления уже созданного менеджера (например, in-memory менеджера в тестах)
+func (r *ManagerRegistry) Add(name string, manager S3Manager) error {
+	if name == "" || manager == nil {
+		return fmt.Errorf("ManagerRegistry.Add: %w: name or manager is empty", ErrInvalidInput)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[name]; ok {
+		return fmt.Errorf("ManagerRegistry.Add: %w: manager %q is already registered", ErrInvalidInput, name)
+	}
+	r.entries[name] = &registryEntry{manager: manager}
+
+	return nil
+}
+
+// Метод для получения менеджера по имени. Зарегистрированный через Register менеджер создаётся при первом вызове;
+// если создать его не удалось, следующий вызов попробует снова.
+func (r *ManagerRegistry) Get(ctx context.Context, name string) (S3Manager, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[name]
+	if !ok {
+		return nil, fmt.Errorf("ManagerRegistry.Get: %w: unknown manager %q", ErrInvalidInput, name)
+	}
+	if entry.manager != nil {
+		return entry.manager, nil
+	}
+
+	cfg := *entry.cfg // Учётные данные подставляются в копию, конфиг записи остаётся исходным
+	if cfg.UseDefaultCredentials && cfg.Credentials == nil {
+		credentials, err := r.sharedDefaultCredentials(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ManagerRegistry.Get/sharedDefaultCredentials: %w", err)
+		}
+		cfg.Credentials = credentials
+	}
+
+	manager, err := NewS3Manager(ctx, &cfg, entry.isTestServer)
+	if err != nil {
+		return nil, fmt.Errorf("ManagerRegistry.Get/NewS3Manager: %s: %w", name, err)
+	}
+	if entry.setup != nil {
+		entry.setup(manager)
+	}
+	entry.manager = manager
+
+	return manager, nil
+}
+
+// Метод для получения имён всех зарегистрированных менеджеров, отсортированных по алфавиту
+func (r *ManagerRegistry) Names() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Возвращает общие учётные данные стандартной цепочки AWS SDK, загружая их при первом вызове. Вызывается под r.mu.
+func (r *ManagerRegistry) sharedDefaultCredentials(ctx context.Context) (aws.CredentialsProvider, error) {
+	if r.defaultCredentials != nil {
+		return r.defaultCredentials, nil
+	}
+
+	awsCfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sharedDefaultCredentials/LoadDefaultConfig: %w", err)
+	}
+	if awsCfg.Credentials == nil {
+		return nil, fmt.Errorf("sharedDefaultCredentials: %w: no credentials found in the default chain", ErrInvalidConfig)
+	}
+	r.defaultCredentials = awsCfg.Credentials // LoadDefaultConfig уже оборачивает цепочку в aws.CredentialsCache
+
+	return r.defaultCredentials, nil
+}
@@ -0,0 +1,148 @@
+package s3_manager
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Метод для разбора ключа объекта или ссылки на файл (ссылки из GetObjectURL, CDN или подписанной ссылки) обратно в StoragePath и имя файла,
+// например, чтобы удалить или скопировать файл, ссылка на который хранится в базе. Ключ сопоставляется с паттернами зарегистрированных
+// каталогов; если подходят несколько, выбирается самый длинный паттерн (например, "products/%d/certificates/", а не "products/%d/").
+// Паттерны с соседними параметрами (например, "reports/{year}{month}/") не сопоставляются: их значения нельзя однозначно разделить.
+// Значение %d возвращается в EntityID, а если это не число или число с ведущими нулями или знаком "+" — в EntityKey; именованные параметры — в Params.
+// Если ни один паттерн не подходит, возвращается PathCustomCatalog с каталогом файла в CustomPath (или пустой CatalogType для файлов в корне;
+// с Config.LegacyCatalogPaths имя файла в этом случае — полный ключ объекта).
+// Возвращает ErrInvalidInput, если ссылка ведёт в другой бакет или файл лежит вне корневого каталога сервиса (с Config.LegacyCatalogPaths
+// такой файл возвращается с пустым StoragePath и полным ключом в имени).
+func (r *s3Manager) ResolvePath(keyOrURL string) (StoragePath, string, error) {
+	key, err := r.keyFromURL(keyOrURL)
+	if err != nil {
+		return StoragePath{}, "", fmt.Errorf("ResolvePath/keyFromURL: %w", err)
+	}
+
+	relativeKey, ok := strings.CutPrefix(key, r.cfg.RootCatalog)
+	if !ok && r.cfg.LegacyCatalogPaths && key != "" && !strings.HasSuffix(key, "/") {
+		return StoragePath{}, key, nil // С прежней адресацией пустой StoragePath означает корень бакета, а не корневой каталог сервиса
+	}
+	if !ok || relativeKey == "" || strings.HasSuffix(relativeKey, "/") {
+		return StoragePath{}, "", fmt.Errorf("ResolvePath: %w: key %q is not a file in root catalog %q", ErrInvalidInput, key, r.cfg.RootCatalog)
+	}
+
+	if storagePath, fileName, ok := matchCatalogs(r.Catalogs(), relativeKey); ok {
+		return storagePath, fileName, nil
+	}
+
+	dir, fileName := path.Split(relativeKey)
+	if dir == "" {
+		if r.cfg.LegacyCatalogPaths {
+			return StoragePath{}, key, nil
+		}
+		return StoragePath{}, fileName, nil
+	}
+
+	return StoragePath{CatalogType: PathCustomCatalog, CustomPath: dir}, fileName, nil
+}
+
+// Сопоставляет путь относительно корневого каталога с паттернами каталогов. Если подходят несколько, выбирается самый длинный паттерн.
+func matchCatalogs(catalogs map[CatalogType]string, relativeKey string) (StoragePath, string, bool) {
+	var (
+		best        StoragePath
+		bestName    string
+		bestPattern string
+	)
+	for catalogType, pathPattern := range catalogs {
+		if catalogType == PathCustomCatalog {
+			continue
+		}
+		storagePath, fileName, ok := matchCatalogPattern(catalogType, pathPattern, relativeKey)
+		if !ok {
+			continue
+		}
+		// Самый длинный паттерн точнее; при равной длине выбор не должен зависеть от порядка обхода карты
+		if best.CatalogType == "" || len(pathPattern) > len(bestPattern) || len(pathPattern) == len(bestPattern) && catalogType < best.CatalogType {
+			best, bestName, bestPattern = storagePath, fileName, pathPattern
+		}
+	}
+
+	return best, bestName, best.CatalogType != ""
+}
+
+// Метод для приведения ссылки на файл к каноническому виду: ссылка, которую менеджер формирует для этого файла при текущем конфиге
+// (через CDN, если он задан, иначе по адресу хранилища со способом адресации из конфига), с единообразным экранированием пути
+// и без параметров запроса. Принимает ссылки по адресу хранилища в path-style и virtual-hosted-style и ссылки CDN независимо от текущих
+// настроек, поэтому подходит для сравнения ссылок, сохранённых в базе при разных конфигах, и их миграции. Строка без схемы считается ключом объекта.
+// Возвращает ErrInvalidInput, если ссылка ведёт в другой бакет или не указывает на файл.
+func (r *s3Manager) CanonicalURL(rawURL string) (string, error) {
+	key, err := r.keyFromURL(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("CanonicalURL/keyFromURL: %w", err)
+	}
+	if key == "" || strings.HasSuffix(key, "/") {
+		return "", fmt.Errorf("CanonicalURL: %w: %q does not point to a file", ErrInvalidInput, rawURL)
+	}
+
+	return r.objectURL(key), nil
+}
+
+// Получает ключ объекта из ссылки на файл в CDN или хранилище (без параметров запроса). Ссылки по адресу хранилища распознаются
+// в обоих способах адресации бакета; схема и регистр домена не учитываются. Строка без схемы считается ключом.
+func (r *s3Manager) keyFromURL(keyOrURL string) (string, error) {
+	_, rest, ok := strings.Cut(keyOrURL, "://")
+	if !ok {
+		return strings.TrimPrefix(keyOrURL, "/"), nil
+	}
+
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	host, urlPath, _ := strings.Cut(rest, "/")
+	urlPath = "/" + urlPath
+
+	matched := false
+	var escapedKey string
+	for _, base := range r.urlBases() {
+		if !strings.EqualFold(host, base.host) || !strings.HasPrefix(urlPath, base.path) {
+			continue
+		}
+		// Самый длинный путь точнее: например, CDN на домене хранилища с путём "/bucket/cdn/"
+		if candidate := urlPath[len(base.path):]; !matched || len(candidate) < len(escapedKey) {
+			escapedKey, matched = candidate, true
+		}
+	}
+	if !matched {
+		return "", fmt.Errorf("keyFromURL: %w: URL %q does not point to bucket %q", ErrInvalidInput, keyOrURL, r.cfg.Name)
+	}
+
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil {
+		return escapedKey, nil // Ключ со знаком "%", который не является экранированием
+	}
+
+	return key, nil
+}
+
+// Домен и путь, с которых начинаются ссылки на файлы бакета
+type urlBase struct {
+	host string
+	path string // Путь до ключа объекта, начинается и оканчивается на "/"
+}
+
+// Возвращает все варианты начала ссылки на файлы бакета: адрес хранилища в path-style и virtual-hosted-style и CDN
+func (r *s3Manager) urlBases() []urlBase {
+	var bases []urlBase
+	if endpoint, err := url.Parse(strings.TrimSuffix(r.endpoint(), "/")); err == nil && endpoint.Host != "" {
+		bases = append(bases,
+			urlBase{host: endpoint.Host, path: endpoint.Path + "/" + r.cfg.Name + "/"},
+			urlBase{host: r.cfg.Name + "." + endpoint.Host, path: endpoint.Path + "/"},
+		)
+	}
+	if cdn, err := url.Parse(strings.TrimSuffix(r.cfg.CDN, "/")); err == nil && cdn.Host != "" {
+		bases = append(bases, urlBase{host: cdn.Host, path: cdn.Path + "/"})
+		if strings.HasSuffix(r.cfg.CDN, "/") {
+			bases = append(bases, urlBase{host: cdn.Host, path: cdn.Path + "//"}) // Ссылки, которые прежние версии GetObjectURL формировали как CDN + "/" + ключ
+		}
+	}
+
+	return bases
+}
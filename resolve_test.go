@@ -0,0 +1,63 @@
+package s3_manager
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	manager, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b", RootCatalog: "svc", CDN: "https://cdn.test/static"}, false)
+	manager.AddCatalog("product", "products/%d/")
+	manager.AddCatalog("product_certificates", "products/%d/certificates/")
+	manager.AddCatalog("org_user", "orgs/{org_id}/users/{user_id}/")
+	manager.AddCatalog("report", "reports/{year}{month}/")
+
+	tests := []struct {
+		name         string
+		keyOrURL     string
+		wantPath     StoragePath
+		wantFileName string
+		wantErr      bool
+	}{
+		{"key", "svc/products/1/photo.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "photo.jpg", false},
+		{"key with leading slash", "/svc/products/1/photo.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "photo.jpg", false},
+		{"longest pattern", "svc/products/1/certificates/cert.pdf", StoragePath{CatalogType: "product_certificates", EntityID: 1}, "cert.pdf", false},
+		{"named parameters", "svc/orgs/2/users/u3/avatar.png", StoragePath{CatalogType: "org_user", Params: map[string]any{"org_id": "2", "user_id": "u3"}}, "avatar.png", false},
+		{"adjacent placeholders", "svc/reports/202401/sales.csv", StoragePath{CatalogType: PathCustomCatalog, CustomPath: "reports/202401/"}, "sales.csv", false},
+		{"unregistered catalog", "svc/misc/a/file.txt", StoragePath{CatalogType: PathCustomCatalog, CustomPath: "misc/a/"}, "file.txt", false},
+		{"root catalog file", "svc/file.txt", StoragePath{}, "file.txt", false},
+		{"path-style URL", "http://s3.test/b/svc/products/1/photo.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "photo.jpg", false},
+		{"virtual-hosted URL", "https://B.S3.TEST/svc/products/1/photo.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "photo.jpg", false},
+		{"CDN URL", "https://cdn.test/static/svc/products/1/photo.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "photo.jpg", false},
+		{"presigned URL", "http://s3.test/b/svc/products/1/photo.jpg?X-Amz-Signature=abc#top", StoragePath{CatalogType: "product", EntityID: 1}, "photo.jpg", false},
+		{"escaped space", "http://s3.test/b/svc/products/1/photo%20one.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "photo one.jpg", false},
+		{"escaped cyrillic", "http://s3.test/b/svc/products/1/%D1%84%D0%BE%D1%82%D0%BE.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "фото.jpg", false},
+		{"unescaped percent", "http://s3.test/b/svc/products/1/100%.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "100%.jpg", false},
+		{"escaped slash", "http://s3.test/b/svc/products/1/a%2Fb.jpg", StoragePath{CatalogType: "product", EntityID: 1}, "a/b.jpg", false},
+		{"other host", "http://evil.test/b/svc/products/1/photo.jpg", StoragePath{}, "", true},
+		{"other bucket path-style", "http://s3.test/other/svc/products/1/photo.jpg", StoragePath{}, "", true},
+		{"other bucket virtual-hosted", "http://other.s3.test/svc/products/1/photo.jpg", StoragePath{}, "", true},
+		{"CDN without its path", "https://cdn.test/svc/products/1/photo.jpg", StoragePath{}, "", true},
+		{"outside root catalog", "other/products/1/photo.jpg", StoragePath{}, "", true},
+		{"directory", "svc/products/1/", StoragePath{}, "", true},
+		{"empty", "", StoragePath{}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storagePath, fileName, err := manager.ResolvePath(tt.keyOrURL)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("ResolvePath error = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolvePath: %v", err)
+			}
+			if !reflect.DeepEqual(storagePath, tt.wantPath) || fileName != tt.wantFileName {
+				t.Errorf("ResolvePath = %+v, %q; want %+v, %q", storagePath, fileName, tt.wantPath, tt.wantFileName)
+			}
+		})
+	}
+}
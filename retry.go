@@ -0,0 +1,52 @@
+package s3_manager
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// Настройки повторных попыток запросов к S3. Незаполненные поля принимают значения по умолчанию из AWS SDK.
+type RetryConfig struct {
+	MaxAttempts          int           // Максимальное количество попыток запроса, включая первую (по умолчанию 3)
+	MaxBackoff           time.Duration // Максимальная задержка между попытками (по умолчанию 20 секунд). Задержка растёт экспоненциально со случайным разбросом.
+	RetryableCodes       []string      // Дополнительные коды ошибок S3, при которых запрос повторяется (например, "XMinioServerNotInitialized")
+	RetryableStatusCodes []int         // Дополнительные HTTP-статусы, при которых запрос повторяется (по умолчанию повторяются 500, 502, 503 и 504)
+	DisableRetryQuota    bool          // Отключает клиентскую квоту на повторные попытки, из-за которой при серии ошибок повторы прекращаются раньше MaxAttempts
+}
+
+// Создаёт функцию построения retryer для aws.Config с учётом настроек повторных попыток
+func newRetryer(cfg RetryConfig) func() aws.Retryer {
+	return func() aws.Retryer {
+		return retry.NewStandard(func(o *retry.StandardOptions) {
+			if cfg.MaxAttempts > 0 {
+				o.MaxAttempts = cfg.MaxAttempts
+			}
+			if cfg.MaxBackoff > 0 {
+				o.MaxBackoff = cfg.MaxBackoff
+				o.Backoff = retry.NewExponentialJitterBackoff(cfg.MaxBackoff)
+			}
+
+			if len(cfg.RetryableCodes) > 0 {
+				codes := make(map[string]struct{}, len(cfg.RetryableCodes))
+				for _, code := range cfg.RetryableCodes {
+					codes[code] = struct{}{}
+				}
+				o.Retryables = append(o.Retryables, retry.RetryableErrorCode{Codes: codes})
+			}
+			if len(cfg.RetryableStatusCodes) > 0 {
+				statusCodes := make(map[int]struct{}, len(cfg.RetryableStatusCodes))
+				for _, statusCode := range cfg.RetryableStatusCodes {
+					statusCodes[statusCode] = struct{}{}
+				}
+				o.Retryables = append(o.Retryables, retry.RetryableHTTPStatusCode{Codes: statusCodes})
+			}
+
+			if cfg.DisableRetryQuota {
+				o.RateLimiter = ratelimit.None
+			}
+		})
+	}
+}
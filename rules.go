@@ -0,0 +1,210 @@
+package s3_manager
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Правила проверки файлов, загружаемых в каталог (см. AddCatalogWithRules). Проверяются в PutFile и при создании подписанных ссылок и форм загрузки.
+type UploadRules struct {
+	AllowedExtensions   []string // Допустимые расширения файлов без учёта регистра (например, ".jpg", ".png"). Пустой список — любые расширения.
+	AllowedContentTypes []string // Допустимые MIME-типы (например, "image/png" или "image/*"). В PutFile тип определяется по содержимому файла, в подписанных ссылках — по WithContentType.
+	MaxSize             int64    // Максимальный размер файла в байтах. Переопределяет Config.MaxUploadSize для каталога (0 - ограничение из конфига).
+}
+
+// Метод для добавления нового типа каталога с паттерном пути в бакете и правилами проверки загружаемых в него файлов
+func (r *s3Manager) AddCatalogWithRules(catalogType CatalogType, pathPattern string, rules UploadRules) {
+	r.AddCatalog(catalogType, pathPattern)
+
+	r.catalogMu.Lock()
+	defer r.catalogMu.Unlock()
+
+	r.catalogRules[catalogType] = rules
+}
+
+// Возвращает правила проверки файлов для каталога (пустые, если правила не заданы)
+func (r *s3Manager) uploadRules(storagePath StoragePath) UploadRules {
+	r.catalogMu.RLock()
+	defer r.catalogMu.RUnlock()
+
+	return r.catalogRules[storagePath.CatalogType]
+}
+
+// Определяет максимальный размер загружаемого файла: лимит каталога (UploadRules.MaxSize) или Config.MaxUploadSize,
+// который можно только уменьшить опцией WithMaxFileSize. Возвращает 0, если размер не ограничен.
+func (r *s3Manager) maxUploadSize(rules UploadRules, o operationOptions) int64 {
+	limit := rules.MaxSize
+	if limit <= 0 {
+		limit = r.cfg.MaxUploadSize
+	}
+	if o.maxFileSize > 0 && (limit <= 0 || o.maxFileSize < limit) {
+		limit = o.maxFileSize
+	}
+
+	return limit
+}
+
+// Проверяет расширение имени файла
+func (u UploadRules) checkExtension(fileName string) error {
+	if len(u.AllowedExtensions) == 0 {
+		return nil
+	}
+
+	ext := path.Ext(fileName)
+	for _, allowed := range u.AllowedExtensions {
+		if strings.EqualFold(ext, allowed) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: extension %q is not allowed", ErrFileTypeNotAllowed, ext)
+}
+
+// Проверяет MIME-тип файла. Параметры типа (например, "; charset=utf-8") не учитываются.
+func (u UploadRules) checkContentType(contentType string) error {
+	if len(u.AllowedContentTypes) == 0 {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: content type %q is not allowed", ErrFileTypeNotAllowed, contentType)
+	}
+	for _, allowed := range u.AllowedContentTypes {
+		if contentTypeMatches(allowed, mediaType) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: content type %q is not allowed", ErrFileTypeNotAllowed, mediaType)
+}
+
+// Проверяет размер файла
+func (u UploadRules) checkSize(size int64) error {
+	if u.MaxSize > 0 && size > u.MaxSize {
+		return fmt.Errorf("%w: %d bytes exceeds %d bytes", ErrFileTooLarge, size, u.MaxSize)
+	}
+	return nil
+}
+
+// Проверяет загружаемый файл по правилам: расширение имени, MIME-тип по первым байтам содержимого и размер.
+// Возвращает поток, из которого нужно загружать файл. Размер потока с Seek проверяется до загрузки, а размер потока без Seek — по мере чтения:
+// файл больше одной части multipart upload к этому моменту может быть загружен частично, но такая загрузка отменяется.
+func (u UploadRules) check(fileName string, body io.Reader) (io.Reader, error) {
+	err := u.checkExtension(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.AllowedContentTypes) > 0 {
+		var contentType string
+		contentType, body, err = sniffContentType(body)
+		if err != nil {
+			return nil, fmt.Errorf("check/sniffContentType: %w", err)
+		}
+		err = u.checkContentType(contentType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if u.MaxSize > 0 {
+		size, err := readerSize(body)
+		if err != nil {
+			return nil, fmt.Errorf("check/readerSize: %w", err)
+		}
+		if size < 0 {
+			return &sizeLimitReader{reader: body, limit: u.MaxSize}, nil
+		}
+		err = u.checkSize(size)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// Проверяет параметры подписанной ссылки на загрузку: содержимое файла недоступно, поэтому MIME-тип и размер должны быть
+// заданы опциями WithContentType и WithContentLength — они входят в подпись, и клиент не сможет загрузить по ссылке другой файл
+func (u UploadRules) checkPresigned(fileName, contentType string, size int64) error {
+	err := u.checkExtension(fileName)
+	if err != nil {
+		return err
+	}
+	if len(u.AllowedContentTypes) > 0 {
+		if contentType == "" {
+			return fmt.Errorf("%w: content type must be set with WithContentType", ErrInvalidInput)
+		}
+		err = u.checkContentType(contentType)
+		if err != nil {
+			return err
+		}
+	}
+	if u.MaxSize > 0 {
+		if size <= 0 {
+			return fmt.Errorf("%w: file size must be set with WithContentLength", ErrInvalidInput)
+		}
+		return u.checkSize(size)
+	}
+
+	return nil
+}
+
+// Проверяет политику подписанной формы загрузки. Размер файла ограничивается правилами, если он не ограничен политикой.
+// Расширение можно проверить только для формы с фиксированным именем файла, а MIME-тип — только если он задан в политике.
+func (u UploadRules) applyToPolicy(policy PostPolicy) (PostPolicy, error) {
+	if len(u.AllowedExtensions) > 0 {
+		if policy.FileName == "" {
+			return policy, fmt.Errorf("%w: file name must be set when catalog restricts extensions", ErrInvalidInput)
+		}
+		err := u.checkExtension(policy.FileName)
+		if err != nil {
+			return policy, err
+		}
+	}
+
+	if len(u.AllowedContentTypes) > 0 {
+		switch {
+		case policy.ContentType != "":
+			err := u.checkContentType(policy.ContentType)
+			if err != nil {
+				return policy, err
+			}
+		case policy.ContentTypePrefix != "":
+			// Префикс допустим, только если все типы с ним разрешены (например, "image/" при разрешённом "image/*")
+			if !slices.ContainsFunc(u.AllowedContentTypes, func(allowed string) bool { return allowed == strings.TrimSuffix(policy.ContentTypePrefix, "/")+"/*" }) {
+				return policy, fmt.Errorf("%w: content type prefix %q is not allowed", ErrFileTypeNotAllowed, policy.ContentTypePrefix)
+			}
+		default:
+			return policy, fmt.Errorf("%w: content type must be set when catalog restricts content types", ErrInvalidInput)
+		}
+	}
+
+	if u.MaxSize > 0 {
+		if policy.MaxSize == 0 {
+			policy.MaxSize = u.MaxSize
+		}
+		err := u.checkSize(policy.MaxSize)
+		if err != nil {
+			return policy, err
+		}
+		if policy.MinSize > policy.MaxSize {
+			return policy, fmt.Errorf("%w: min size %d exceeds %d bytes", ErrFileTooLarge, policy.MinSize, policy.MaxSize)
+		}
+	}
+
+	return policy, nil
+}
+
+// Проверяет, подходит ли MIME-тип под шаблон вида "image/png" или "image/*"
+func contentTypeMatches(pattern, mediaType string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return strings.EqualFold(pattern, mediaType)
+}
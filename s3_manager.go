@@ -3,7 +3,14 @@ package s3_manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -13,91 +20,488 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
+// Менеджер файлов в бакете. Состоит из узких интерфейсов, чтобы сервисы и моки в тестах могли зависеть только от нужной части
+// (например, FileReader для сервиса, который только отдаёт файлы).
 type S3Manager interface {
-	GetFiles(ctx context.Context, prefix string) ([]string, error)
-	PutFile(ctx context.Context, storagePath StoragePath, data *BucketFile) (string, error)
-	DeleteFiles(ctx context.Context, storagePath StoragePath, fileName string) error
-	GetUploadPresignedURL(ctx context.Context, storagePath StoragePath, fileName string, expireTime time.Duration) (string, error)
-	GetCatalogPattern(storagePath StoragePath) string
+	FileReader
+	FileWriter
+	PresignService
+	CatalogRegistry
+	BucketAdmin
+}
+
+// Чтение файлов из бакета: списки, скачивание, метаданные и статистика каталогов. Достаточно сервисам, которые только отдают файлы.
+type FileReader interface {
+	GetFiles(ctx context.Context, prefix string, opts ...Option) ([]string, error)
+	ListObjects(ctx context.Context, prefix string, opts ...Option) ([]ObjectInfo, error)
+	GetFilesByPath(ctx context.Context, storagePath StoragePath, opts ...Option) ([]ObjectInfo, error)
+	GetFile(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (io.ReadCloser, *FileInfo, error)
+	GetFileIfChanged(ctx context.Context, storagePath StoragePath, fileName, etag string, opts ...Option) (io.ReadCloser, *FileInfo, error)
+	DownloadToWriter(ctx context.Context, storagePath StoragePath, fileName string, w io.Writer, opts ...Option) (int64, error)
+	DownloadRange(ctx context.Context, storagePath StoragePath, fileName string, offset, length int64, opts ...Option) (io.ReadCloser, *FileInfo, error)
+	DownloadCatalogAsZip(ctx context.Context, storagePath StoragePath, w io.Writer, opts ...Option) error
+	DownloadCatalogAsTarGz(ctx context.Context, storagePath StoragePath, w io.Writer, opts ...Option) error
+	QueryObject(ctx context.Context, storagePath StoragePath, fileName, sqlExpr string, format SelectFormat, opts ...Option) (io.ReadCloser, error)
+	DownloadToFile(ctx context.Context, storagePath StoragePath, fileName, localPath string, opts ...Option) (int64, error)
+	DownloadToWriterAt(ctx context.Context, storagePath StoragePath, fileName string, w io.WriterAt, opts ...Option) (int64, error)
+	OpenObject(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (*ObjectReader, error)
+	FS(ctx context.Context, storagePath StoragePath, opts ...Option) *CatalogFS
+	SyncDown(ctx context.Context, storagePath StoragePath, localDir string, syncOpts SyncOptions, opts ...Option) (*SyncResult, error)
+	StatFile(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (*FileInfo, error)
+	FileExists(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (bool, error)
+	GetCatalogStats(ctx context.Context, storagePath StoragePath, opts ...Option) (int64, int64, error)
+	GetCatalogTypeStats(ctx context.Context, catalogType CatalogType, opts ...Option) (int64, int64, error)
+	ListFileVersions(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) ([]FileVersion, error)
+	GetFileRetention(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (*Retention, error)
+	GetFileLegalHold(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (bool, error)
+}
+
+// Изменение файлов в бакете: загрузка, копирование, перемещение, удаление, восстановление и блокировки объектов
+type FileWriter interface {
+	PutFile(ctx context.Context, storagePath StoragePath, data *BucketFile, opts ...Option) (string, error)
+	PutFileIfAbsent(ctx context.Context, storagePath StoragePath, data *BucketFile, opts ...Option) (string, error)
+	PutRedirect(ctx context.Context, storagePath StoragePath, fileName, location string, opts ...Option) (string, error)
+	PutFiles(ctx context.Context, data BucketFilesData, opts ...Option) ([]string, error)
+	PutArchive(ctx context.Context, storagePath StoragePath, archive io.Reader, format ArchiveFormat, opts ...Option) ([]string, error)
+	PutHLSAsset(ctx context.Context, storagePath StoragePath, masterPlaylist BucketFile, segments []BucketFile, opts ...Option) (string, error)
+	PutImage(ctx context.Context, storagePath StoragePath, data *BucketFile, opts ...Option) (map[string]string, error)
+	PutFromMultipartForm(ctx context.Context, storagePath StoragePath, req *http.Request, fieldName string, opts ...Option) ([]UploadedFile, error)
+	DeleteFiles(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (int, error)
+	DeleteFile(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) error
+	DeleteEntity(ctx context.Context, catalogType CatalogType, entityID int64, opts ...Option) (int, error)
+	DeleteEntityCatalogs(ctx context.Context, entityID int64, catalogTypes []CatalogType, opts ...Option) (int, error)
+	CopyFile(ctx context.Context, srcPath StoragePath, srcName string, dstPath StoragePath, dstName string, opts ...Option) (string, error)
+	MoveFile(ctx context.Context, srcPath StoragePath, srcName string, dstPath StoragePath, dstName string, opts ...Option) (string, error)
+	RenameFile(ctx context.Context, storagePath StoragePath, oldName, newName string, opts ...Option) (string, error)
+	TransitionStorageClass(ctx context.Context, storagePath StoragePath, fileName string, storageClass types.StorageClass, opts ...Option) error
+	RestoreFileVersion(ctx context.Context, storagePath StoragePath, fileName, versionID string, opts ...Option) (string, error)
+	RestoreFromTrash(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (string, error)
+	PurgeTrash(ctx context.Context, olderThan time.Duration, opts ...Option) (int, error)
+	CompleteMultipart(ctx context.Context, storagePath StoragePath, fileName, uploadID string, parts []UploadedPart, opts ...Option) (string, error)
+	AbortMultipart(ctx context.Context, storagePath StoragePath, fileName, uploadID string, opts ...Option) error
+	SyncUp(ctx context.Context, localDir string, storagePath StoragePath, syncOpts SyncOptions, opts ...Option) (*SyncResult, error)
+	SetFileRetention(ctx context.Context, storagePath StoragePath, fileName string, retention Retention, opts ...Option) error
+	SetFileLegalHold(ctx context.Context, storagePath StoragePath, fileName string, enabled bool, opts ...Option) error
+}
+
+// Ссылки на файлы: публичные, CDN и подписанные ссылки для загрузки и скачивания файлов клиентом напрямую из хранилища
+type PresignService interface {
+	GetObjectURL(storagePath StoragePath, fileName string, opts ...Option) (string, error)
+	GetObjectURLs(storagePath StoragePath, fileNames []string, opts ...Option) ([]string, error)
+	GetObjectURLMap(storagePath StoragePath, fileNames []string, opts ...Option) (map[string]string, error)
+	GetSignedCDNURL(storagePath StoragePath, fileName string, expireTime time.Duration, opts ...Option) (string, error)
+	CanonicalURL(rawURL string) (string, error)
+	GetUploadPresignedURL(ctx context.Context, storagePath StoragePath, fileName string, expireTime time.Duration, opts ...Option) (string, error)
+	GetDownloadPresignedURL(ctx context.Context, storagePath StoragePath, fileName string, expireTime time.Duration, opts ...Option) (string, error)
+	GetUploadPresignedPOST(ctx context.Context, storagePath StoragePath, policy PostPolicy, opts ...Option) (*PresignedPOST, error)
+	CreatePresignedMultipart(ctx context.Context, storagePath StoragePath, fileName string, size int64, expireTime time.Duration, opts ...Option) (*PresignedMultipartUpload, error)
+}
+
+// Каталоги сервиса: регистрация паттернов путей и преобразование StoragePath в ключи объектов и обратно
+type CatalogRegistry interface {
 	AddCatalog(catalogType CatalogType, pathPattern string)
-	GetObjectURL(storagePath StoragePath, fileName string) (string, error)
+	AddCatalogWithRules(catalogType CatalogType, pathPattern string, rules UploadRules)
+	AddCatalogWithCompression(catalogType CatalogType, pathPattern string, compression Compression)
+	AddCatalogWithStorageClass(catalogType CatalogType, pathPattern string, storageClass types.StorageClass)
+	AddCatalogWithHeaders(catalogType CatalogType, pathPattern string, headers ObjectHeaders)
+	AddImageCatalog(catalogType CatalogType, pathPattern string, cfg ImageConfig)
+	Catalogs() map[CatalogType]string
+	GetCatalogPattern(storagePath StoragePath) string
+	ResolvePath(keyOrURL string) (StoragePath, string, error)
+}
+
+// Администрирование бакета: создание, версионирование, жизненный цикл, блокировка объектов, инвентаризация и доступ к клиенту S3
+type BucketAdmin interface {
+	EnsureBucket(ctx context.Context, opts ...Option) error
+	EnableBucketVersioning(ctx context.Context, opts ...Option) error
+	GetLifecycleRules(ctx context.Context, opts ...Option) ([]LifecycleRule, error)
+	PutLifecycleRules(ctx context.Context, rules []LifecycleRule, opts ...Option) error
+	GetBucketWebsite(ctx context.Context, opts ...Option) (*WebsiteConfig, error)
+	PutBucketWebsite(ctx context.Context, website WebsiteConfig, opts ...Option) error
+	DeleteBucketWebsite(ctx context.Context, opts ...Option) error
+	GetObjectLockConfig(ctx context.Context, opts ...Option) (*ObjectLockConfig, error)
+	PutObjectLockConfig(ctx context.Context, lockConfig ObjectLockConfig, opts ...Option) error
+	FindLatestInventoryManifest(ctx context.Context, prefix string, opts ...Option) (string, error)
+	GetInventoryManifest(ctx context.Context, manifestKey string, opts ...Option) (*InventoryManifest, error)
+	ReadInventory(ctx context.Context, manifestKey string, fn func(InventoryRecord) error, opts ...Option) error
+	Ping(ctx context.Context) error
+	Client() *s3.Client
+	PresignClient() *s3.PresignClient
 }
 
 func NewS3Manager(ctx context.Context, cfg *Config, isTestServer bool) (S3Manager, error) {
-	// Добавляем "test" к пути каталога, если сервер работает в тестовом режиме, чтобы отделить тестовые файлы от продовских
-	if isTestServer {
-		cfg.RootCatalog += "test/"
+	cfgCopy := *cfg // Конфиг вызывающего кода не изменяем: validate нормализует RootCatalog
+	cfg = &cfgCopy
+
+	err := cfg.validate(true)
+	if err != nil {
+		return nil, fmt.Errorf("NewS3Manager/validate: %w", err)
 	}
 
-	bucketCfg, err := config.LoadDefaultConfig(ctx,
+	loadOptions := []func(*config.LoadOptions) error{
 		config.WithBaseEndpoint(cfg.Endpoint),
 		config.WithRegion(cfg.Region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+		config.WithRetryer(newRetryer(cfg.Retry)),
+	}
+	httpClient, err := cfg.httpClient()
+	if err != nil {
+		return nil, fmt.Errorf("NewS3Manager/httpClient: %w", err)
+	}
+	if httpClient != nil {
+		loadOptions = append(loadOptions, config.WithHTTPClient(httpClient))
+	}
+	// Без провайдера и статических ключей учётные данные ищутся стандартной цепочкой AWS SDK: переменные окружения, ~/.aws/config,
+	// web identity (IRSA в EKS), роль задачи ECS или инстанса EC2
+	switch {
+	case cfg.Credentials != nil:
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(cachedCredentials(cfg.Credentials)))
+	case !cfg.UseDefaultCredentials:
+		loadOptions = append(loadOptions, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
 			cfg.AccessKey,
 			cfg.SecretKey,
 			"",
-		)),
-	)
+		)))
+	}
+
+	bucketCfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("NewS3Manager/LoadDefaultConfig: %w", err)
 	}
 
+	s3Manager, err := newS3Manager(s3.NewFromConfig(bucketCfg, cfg.applyAddressingStyle), cfg, isTestServer)
+	if err != nil {
+		return nil, fmt.Errorf("NewS3Manager/newS3Manager: %w", err)
+	}
+
+	return s3Manager, nil
+}
+
+// Создаёт S3Manager поверх готового клиента S3, например, созданного с ролью IAM, SSO, web identity или собственным HTTP-клиентом.
+// Учётные данные, адрес, регион и повторные попытки берутся из клиента, поля Config.AccessKey, Config.SecretKey и Config.Retry не используются;
+// Config.Endpoint и Config.Region по-прежнему используются для формирования ссылок на файлы (если регион не задан, берётся регион клиента).
+func NewS3ManagerFromClient(client *s3.Client, cfg *Config) (S3Manager, error) {
+	if client == nil {
+		return nil, fmt.Errorf("NewS3ManagerFromClient: %w: client is nil", ErrInvalidInput)
+	}
+	if cfg.Region == "" {
+		withRegion := *cfg // Конфиг вызывающего кода не изменяем
+		withRegion.Region = client.Options().Region
+		cfg = &withRegion
+	}
+
+	s3Manager, err := newS3Manager(client, cfg, false)
+	if err != nil {
+		return nil, fmt.Errorf("NewS3ManagerFromClient/newS3Manager: %w", err)
+	}
+
+	return s3Manager, nil
+}
+
+// Создаёт S3Manager с клиентом S3 из готовой конфигурации AWS SDK (например, полученной config.LoadDefaultConfig с нужными провайдерами
+// учётных данных). Config.Endpoint и Config.AddressingStyle, если заполнены, применяются к клиенту; учётные данные, регион
+// и повторные попытки берутся из awsCfg.
+func NewS3ManagerFromConfig(awsCfg aws.Config, cfg *Config) (S3Manager, error) {
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+		cfg.applyAddressingStyle(o)
+	})
+
+	s3Manager, err := NewS3ManagerFromClient(client, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("NewS3ManagerFromConfig/NewS3ManagerFromClient: %w", err)
+	}
+
+	return s3Manager, nil
+}
+
+func newS3Manager(client StorageBackend, cfg *Config, isTestServer bool) (*s3Manager, error) {
+	cfgCopy := *cfg // Менеджер хранит свою копию конфига, чтобы изменения конфига вызывающим кодом не влияли на него, и наоборот
+	cfg = &cfgCopy
+
+	err := cfg.validate(false)
+	if err != nil {
+		return nil, fmt.Errorf("newS3Manager/validate: %w", err)
+	}
+
+	// Добавляем "test" к пути каталога, если сервер работает в тестовом режиме, чтобы отделить тестовые файлы от продовских
+	if isTestServer {
+		cfg.RootCatalog += "test/"
+	}
+
+	if cfg.Logger != nil {
+		client = &loggingBackend{next: client, logger: cfg.Logger}
+	}
+	if cfg.AuditSink != nil {
+		client = &auditBackend{next: client, sink: cfg.AuditSink}
+	}
+	client = newRateLimitedBackend(client, cfg.RateLimit)
+	if cfg.ReadOnly {
+		client = &readOnlyBackend{next: client}
+	}
+	cache := cfg.Cache
+	if cache == nil && (cfg.CacheTTL > 0 || cfg.CachePresignedURLs) {
+		cache = NewMemoryCache(0)
+	}
+	if cfg.Cache != nil || cfg.CacheTTL > 0 {
+		ttl := cfg.CacheTTL
+		if ttl <= 0 {
+			ttl = defaultCacheTTL
+		}
+		client = &cachingBackend{next: client, cache: cache, ttl: ttl, logger: cfg.Logger}
+	}
+	if !cfg.DisableCoalescing {
+		client = &coalescingBackend{next: client}
+	}
+
 	s3Manager := s3Manager{
-		client: s3.NewFromConfig(bucketCfg),
-		cfg:    cfg,
+		client:              client,
+		cfg:                 cfg,
+		isTestServer:        isTestServer,
+		catalogMu:           &sync.RWMutex{},
+		imageCatalogs:       make(map[CatalogType]ImageConfig),
+		catalogCompression:  make(map[CatalogType]Compression),
+		catalogRules:        make(map[CatalogType]UploadRules),
+		catalogStorageClass: make(map[CatalogType]types.StorageClass),
+		catalogHeaders:      make(map[CatalogType]ObjectHeaders),
+		storagePaths:        make(map[CatalogType]string),
+	}
+	if cfg.MaxConcurrency > 0 {
+		s3Manager.workers = make(chan struct{}, cfg.MaxConcurrency)
+	}
+	if cfg.CachePresignedURLs {
+		s3Manager.presignCache = cache
+	}
+	if cfg.DiskCacheDir != "" {
+		s3Manager.diskCache, err = newDiskCache(cfg.DiskCacheDir, cfg.DiskCacheSize)
+		if err != nil {
+			return nil, fmt.Errorf("newS3Manager/newDiskCache: %w", err)
+		}
 	}
 	s3Manager.AddCatalog(PathCustomCatalog, "%s") // Путь для кастомного каталога
 
 	return &s3Manager, nil
 }
 
-// Метод для получения ссылок на файлы в бакете по указанному пути (префиксу)
-func (r *s3Manager) GetFiles(ctx context.Context, prefix string) ([]string, error) {
-	getInput := &s3.ListObjectsV2Input{
-		Bucket: &r.cfg.Name,
-		Prefix: &prefix,
+// Метод для получения ссылок на файлы в бакете по указанному пути (префиксу). Ссылки формируются так же, как в GetObjectURL (с учётом CDN).
+// Постранично обходит весь список объектов; ограничить количество результатов можно опцией WithMaxResults.
+func (r *s3Manager) GetFiles(ctx context.Context, prefix string, opts ...Option) ([]string, error) {
+	objects, err := r.ListObjects(ctx, prefix, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetFiles/ListObjects: %w", err)
+	}
+
+	var fileURLs []string
+	for _, obj := range objects {
+		fileURLs = append(fileURLs, obj.URL)
+	}
+
+	return fileURLs, nil
+}
+
+// Метод для получения списка файлов каталога storagePath (например, всех файлов товара) вместе с их метаданными. Префикс формируется
+// из паттерна каталога и корневого каталога сервиса так же, как при загрузке файлов, поэтому его не нужно собирать вручную.
+// Включает файлы во вложенных каталогах (например, "products/5/certificates/" для каталога "products/%d/").
+func (r *s3Manager) GetFilesByPath(ctx context.Context, storagePath StoragePath, opts ...Option) ([]ObjectInfo, error) {
+	r = r.forCall(opts)
+
+	prefix, err := r.objectKey(storagePath, "")
+	if err != nil {
+		return nil, fmt.Errorf("GetFilesByPath/objectKey: %w", err)
 	}
 
-	output, err := r.client.ListObjectsV2(ctx, getInput)
+	objects, err := r.ListObjects(ctx, prefix, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("GetFiles/ListObjectsV2: %w", err)
+		return nil, fmt.Errorf("GetFilesByPath/ListObjects: %w", err)
 	}
 
-	var fileURLs []string
-	for _, obj := range output.Contents {
-		if obj.Key == nil {
-			continue
+	return objects, nil
+}
+
+// Метод для получения списка объектов в бакете по указанному пути (префиксу) вместе с их метаданными (размер, ETag, время изменения и т.д.).
+// Постранично обходит весь список объектов; ограничить количество результатов можно опцией WithMaxResults.
+func (r *s3Manager) ListObjects(ctx context.Context, prefix string, opts ...Option) ([]ObjectInfo, error) {
+	r = r.forCall(opts)
+
+	ctx, cancel := withTimeout(ctx, r.cfg.ListTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+
+	getInput := &s3.ListObjectsV2Input{
+		Bucket: &r.cfg.Name,
+		Prefix: &prefix,
+	}
+
+	var objects []ObjectInfo
+	paginator := s3.NewListObjectsV2Paginator(r.client, getInput)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("ListObjects/ListObjectsV2: %w", classifyError(err))
 		}
 
-		url := fmt.Sprintf("%s/%s/%s", r.cfg.Endpoint, r.cfg.Name, *obj.Key)
-		fileURLs = append(fileURLs, url)
+		for _, obj := range output.Contents {
+			if obj.Key == nil {
+				continue
+			}
+
+			objects = append(objects, ObjectInfo{
+				Key:          *obj.Key,
+				URL:          r.objectURL(*obj.Key),
+				Size:         aws.ToInt64(obj.Size),
+				ETag:         aws.ToString(obj.ETag),
+				LastModified: aws.ToTime(obj.LastModified),
+				StorageClass: string(obj.StorageClass),
+			})
+
+			if o.maxResults > 0 && len(objects) >= o.maxResults {
+				return objects, nil
+			}
+		}
 	}
 
-	return fileURLs, nil
+	return objects, nil
 }
 
-// Метод для загрузки файла в бакет по указанному пути
-func (r *s3Manager) PutFile(ctx context.Context, storagePath StoragePath, data *BucketFile) (string, error) {
+// Метод для загрузки файла в бакет по указанному пути.
+// Содержимое может быть потоком без Seek (например, r.Body в HTTP-обработчике): такой поток читается последовательно,
+// и если он длиннее одной части multipart upload, загружается по частям без сохранения на диск.
+// Имя файла очищается по Config.NameSanitization, а с опцией WithUniqueName заменяется сгенерированным уникальным именем;
+// итоговое имя входит в возвращаемую ссылку и может быть получено опцией WithStoredName.
+// С опцией WithDeduplication файл, уже загруженный в каталог, повторно не загружается.
+func (r *s3Manager) PutFile(ctx context.Context, storagePath StoragePath, data *BucketFile, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
 	if data == nil || data.File == nil || data.Name == "" {
-		return "", fmt.Errorf("PutFile: invalid file data")
+		return "", fmt.Errorf("PutFile: %w: invalid file data", ErrInvalidInput)
 	}
 
-	storagePath.RootCatalog = r.cfg.RootCatalog
-	fullPath := r.GetCatalogPattern(storagePath) + data.Name
+	ctx, cancel := withTimeout(ctx, r.cfg.UploadTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	if o.retention != nil {
+		err := o.retention.validate()
+		if err != nil {
+			return "", fmt.Errorf("PutFile: %w", err)
+		}
+	}
+	fileName := data.Name
+	if !o.rawName {
+		name, err := r.cfg.NameSanitization.sanitize(fileName)
+		if err != nil {
+			return "", fmt.Errorf("PutFile/sanitize: %w", err)
+		}
+		fileName = name
+	}
+	rules := r.uploadRules(storagePath)
+	rules.MaxSize = r.maxUploadSize(rules, o)
+	body, err := rules.check(fileName, data.File)
+	if err != nil {
+		return "", fmt.Errorf("PutFile/check: %w", err)
+	}
+	if imageConfig, ok := r.imageCatalog(storagePath.CatalogType); ok && !o.imageValidated {
+		body, err = imageConfig.validate(body)
+		if err != nil {
+			return "", fmt.Errorf("PutFile/validate: %w", err)
+		}
+	}
+	deduplicate := o.deduplicate && !o.rawName
+	if o.uniqueName && !o.rawName || deduplicate {
+		strategy := r.cfg.NamingStrategy
+		if deduplicate {
+			strategy = NamingContentHash
+		}
+		name, err := uniqueFileName(strategy, fileName, body)
+		if err != nil {
+			return "", fmt.Errorf("PutFile/uniqueFileName: %w", err)
+		}
+		fileName = name
+	}
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return "", fmt.Errorf("PutFile/objectKey: %w", err)
+	}
+	if deduplicate {
+		_, err := r.statObject(ctx, fileName, fullPath, o)
+		if err == nil {
+			if o.storedName != nil {
+				*o.storedName = fileName
+			}
+			fileURL, err := r.GetObjectURL(storagePath, fileName, opts...)
+			if err != nil {
+				return "", fmt.Errorf("PutFile/GetObjectURL: %w", err)
+			}
+			return fileURL, nil
+		}
+		if !errors.Is(err, ErrObjectNotFound) {
+			return "", fmt.Errorf("PutFile/statObject: %w", err)
+		}
+	}
 
 	putInput := &s3.PutObjectInput{
 		Bucket: &r.cfg.Name,
 		Key:    &fullPath,
-		Body:   data.File,
-		ACL:    types.ObjectCannedACLPublicRead,
+	}
+	if o.acl != NoACL {
+		putInput.ACL = o.acl
+	}
+	o.encryption.applyToPut(putInput)
+	putInput.ChecksumAlgorithm = o.checksumAlgorithm
+	o.applyObjectLock(putInput)
+	putInput.StorageClass = r.uploadStorageClass(storagePath, o)
+	r.uploadHeaders(storagePath, o).applyToPut(putInput)
+	if o.ifNoneMatch {
+		putInput.IfNoneMatch = aws.String("*")
+	}
+	contentType := o.contentType
+	if contentType == "" {
+		detectedType, detectedBody, err := r.detectContentType(fileName, body)
+		if err != nil {
+			return "", fmt.Errorf("PutFile/detectContentType: %w", err)
+		}
+		contentType, body = detectedType, detectedBody
+	}
+	if contentType != "" {
+		putInput.ContentType = &contentType
+	}
+	if len(o.metadata) > 0 {
+		putInput.Metadata = encodeMetadata(o.metadata)
+	}
+
+	putInput.Body = body
+	if o.progress != nil {
+		size, err := readerSize(body)
+		if err != nil {
+			return "", fmt.Errorf("PutFile/readerSize: %w", err)
+		}
+		putInput.Body = newProgressReader(body, size, o.progress)
 	}
 
-	_, err := r.client.PutObject(ctx, putInput)
+	if compression := r.uploadCompression(storagePath, o); compression != "" {
+		compressed, err := compressReader(putInput.Body, compression)
+		if err != nil {
+			return "", fmt.Errorf("PutFile/compressReader: %w", err)
+		}
+		defer compressed.Close()
+		putInput.Body = compressed
+		putInput.ContentEncoding = aws.String(string(compression))
+	} else if o.contentEncoding != "" {
+		putInput.ContentEncoding = &o.contentEncoding
+	}
+
+	err = r.putObject(ctx, putInput)
 	if err != nil {
-		return "", fmt.Errorf("PutFile/PutObject: %w", err)
+		return "", fmt.Errorf("PutFile/putObject: %w", err)
+	}
+	if o.storedName != nil {
+		*o.storedName = fileName
 	}
+	r.invalidate(ctx, fullPath)
 
-	fileURL, err := r.GetObjectURL(storagePath, data.Name)
+	fileURL, err := r.GetObjectURL(storagePath, fileName, opts...)
 	if err != nil {
 		return "", fmt.Errorf("PutFile/GetObjectURL: %w", err)
 	}
@@ -105,109 +509,590 @@ func (r *s3Manager) PutFile(ctx context.Context, storagePath StoragePath, data *
 	return fileURL, nil
 }
 
-// Метод для удаления файлов в бакете. Если fileName не указан, удаляются все файлы по префиксу (весь каталог).
-func (r *s3Manager) DeleteFiles(ctx context.Context, storagePath StoragePath, fileName string) error {
-	storagePath.RootCatalog = r.cfg.RootCatalog
-	fullPath := r.GetCatalogPattern(storagePath) + fileName
+// Метод для загрузки файла, только если файла с таким именем в каталоге ещё нет (PutFile с опцией WithIfNoneMatch).
+// Если файл уже существует или его одновременно загружает другой запрос, возвращает ErrPreconditionFailed и не изменяет существующий файл.
+func (r *s3Manager) PutFileIfAbsent(ctx context.Context, storagePath StoragePath, data *BucketFile, opts ...Option) (string, error) {
+	fileURL, err := r.PutFile(ctx, storagePath, data, append(opts, WithIfNoneMatch())...)
+	if err != nil {
+		return "", fmt.Errorf("PutFileIfAbsent/PutFile: %w", err)
+	}
+
+	return fileURL, nil
+}
+
+// Метод для удаления файлов в бакете по префиксу: удаляются все объекты, ключ которых начинается с пути к каталогу + fileName
+// (например, для "photo.jpg" будет удалён и "photo.jpg.bak"). Если fileName не указан, удаляется весь каталог.
+// Список объектов обходится постранично, а удаление выполняется пачками, поэтому удаляются все объекты каталога независимо от их количества.
+// Возвращает количество удалённых объектов. Для удаления ровно одного файла используется DeleteFile.
+// Если задан Config.TrashCatalog, файлы перемещаются в корзину (см. RestoreFromTrash); WithPermanentDelete удаляет их окончательно.
+// Файлы в самой корзине не удаляются, даже если попадают под префикс (для их удаления используется PurgeTrash).
+func (r *s3Manager) DeleteFiles(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (int, error) {
+	r = r.forCall(opts)
 
-	// Получаем список объектов по заданному пути
+	ctx, cancel := withTimeout(ctx, r.cfg.DeleteTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return 0, fmt.Errorf("DeleteFiles/objectKey: %w", err)
+	}
+
+	// Постранично получаем список объектов по заданному пути и удаляем каждую страницу
 	getInput := &s3.ListObjectsV2Input{
 		Bucket: &r.cfg.Name,
 		Prefix: &fullPath,
 	}
-	objects, err := r.client.ListObjectsV2(ctx, getInput)
+
+	deleted := 0
+	paginator := s3.NewListObjectsV2Paginator(r.client, getInput)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("DeleteFiles/ListObjectsV2: %w", classifyError(err))
+		}
+
+		keys := make([]string, 0, len(page.Contents))
+		for _, item := range page.Contents {
+			if item.Key != nil && !r.inTrash(*item.Key) {
+				keys = append(keys, *item.Key)
+			}
+		}
+
+		n, err := r.removeObjects(ctx, keys, o)
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("DeleteFiles/removeObjects: %w", err)
+		}
+	}
+
+	if deleted > 0 {
+		r.invalidate(ctx, fullPath+"*")
+	}
+
+	return deleted, nil
+}
+
+// Метод для удаления ровно одного файла в бакете (без удаления других объектов с тем же префиксом). Отсутствие файла ошибкой не считается.
+// В бакете с версионированием удаление без WithVersionID оставляет маркер удаления, а с WithVersionID — удаляет указанную версию безвозвратно.
+// Если задан Config.TrashCatalog, файл перемещается в корзину (кроме удаления конкретной версии и удаления с WithPermanentDelete).
+func (r *s3Manager) DeleteFile(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return fmt.Errorf("DeleteFile: %w: file name is empty", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.DeleteTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
 	if err != nil {
-		return fmt.Errorf("DeleteFiles/ListObjectsV2: %w", err)
+		return fmt.Errorf("DeleteFile/objectKey: %w", err)
 	}
 
-	// Формируем список объектов для удаления
-	if len(objects.Contents) == 0 {
+	if r.cfg.TrashCatalog != "" && !o.permanentDelete && o.versionID == "" {
+		_, err := r.moveToTrash(ctx, []string{fullPath}, o)
+		if err != nil && !errors.Is(err, ErrObjectNotFound) {
+			return fmt.Errorf("DeleteFile/moveToTrash: %w", err)
+		}
+		r.invalidate(ctx, fullPath)
 		return nil
 	}
-	var objectIds = make([]types.ObjectIdentifier, 0, len(objects.Contents))
-	for _, item := range objects.Contents {
-		objectIds = append(objectIds, types.ObjectIdentifier{
-			Key: item.Key,
-		})
-	}
 
-	// Удаляем объекты папки
-	deleteInput := &s3.DeleteObjectsInput{
-		Bucket: &r.cfg.Name,
-		Delete: &types.Delete{
-			Objects: objectIds,
-			Quiet:   aws.Bool(true), // Подавляем вывод списка удалённых объектов
-		},
+	deleteInput := &s3.DeleteObjectInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &fullPath,
+		VersionId: nonEmpty(o.versionID),
+	}
+	if o.bypassGovernance {
+		deleteInput.BypassGovernanceRetention = aws.Bool(true)
 	}
 
-	_, err = r.client.DeleteObjects(ctx, deleteInput)
+	_, err = r.client.DeleteObject(ctx, deleteInput)
 	if err != nil {
-		return fmt.Errorf("DeleteFiles/DeleteObjects: %w", err)
+		return fmt.Errorf("DeleteFile/DeleteObject: %w", classifyError(err))
 	}
+	r.invalidate(ctx, fullPath)
 
 	return nil
 }
 
 // Метод для получения URL-адреса для загрузки файла в бакет. Используется для генерации подписанного URL-адреса для последующией загрузки файла.
-func (r *s3Manager) GetUploadPresignedURL(ctx context.Context, storagePath StoragePath, fileName string, expireTime time.Duration) (string, error) {
+// Опции WithContentType, WithContentLength и WithChecksum ограничивают, какой файл клиент сможет загрузить по ссылке.
+// Если для каталога заданы правила (см. AddCatalogWithRules), имя, MIME-тип и размер файла проверяются по ним.
+func (r *s3Manager) GetUploadPresignedURL(ctx context.Context, storagePath StoragePath, fileName string, expireTime time.Duration, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return "", fmt.Errorf("GetUploadPresignedURL: %w", err)
+	}
+
 	if fileName == "" {
-		return "", fmt.Errorf("GetUploadPresignedURL: file name is empty")
+		return "", fmt.Errorf("GetUploadPresignedURL: %w: file name is empty", ErrInvalidInput)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return "", fmt.Errorf("GetUploadPresignedURL/s3Client: %w", err)
 	}
 
-	presignClient := s3.NewPresignClient(r.client)
-	storagePath.RootCatalog = r.cfg.RootCatalog
-	fullPath := r.GetCatalogPattern(storagePath) + fileName
+	o := r.applyOptions(opts)
+	presignClient := s3.NewPresignClient(client)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return "", fmt.Errorf("GetUploadPresignedURL/objectKey: %w", err)
+	}
+
+	err = r.uploadRules(storagePath).checkPresigned(fileName, o.contentType, o.contentLength)
+	if err != nil {
+		return "", fmt.Errorf("GetUploadPresignedURL/checkPresigned: %w", err)
+	}
 
+	// Параметры шифрования входят в подпись: клиент должен передать те же заголовки x-amz-server-side-encryption-* при загрузке
 	putInput := &s3.PutObjectInput{
 		Bucket: &r.cfg.Name,
 		Key:    &fullPath,
 	}
+	o.encryption.applyToPut(putInput)
+	// MIME-тип, размер и контрольная сумма входят в подпись, поэтому клиент не сможет загрузить по ссылке другой файл
+	if o.contentType != "" {
+		putInput.ContentType = &o.contentType
+	}
+	if o.contentLength > 0 {
+		putInput.ContentLength = &o.contentLength
+	}
+	err = o.checksum.applyToPut(putInput)
+	if err != nil {
+		return "", fmt.Errorf("GetUploadPresignedURL/applyToPut: %w", err)
+	}
 
-	if expireTime == 0 {
-		expireTime = r.cfg.PresignedURLExpireTime
+	expireTime, err = r.presignExpireTime(expireTime)
+	if err != nil {
+		return "", fmt.Errorf("GetUploadPresignedURL/presignExpireTime: %w", err)
 	}
 
 	presignedRequest, err := presignClient.PresignPutObject(ctx, putInput, s3.WithPresignExpires(expireTime))
 	if err != nil {
-		return "", fmt.Errorf("GetUploadPresignedURL/PresignPutObject: failed to create presigned request: %w", err)
+		return "", fmt.Errorf("GetUploadPresignedURL/PresignPutObject: failed to create presigned request: %w", classifyError(err))
+	}
+
+	return presignedRequest.URL, nil
+}
+
+// Метод для получения подписанного URL-адреса для скачивания файла из бакета (например, для приватных объектов).
+// С опцией WithDownloadName в ссылку добавляется заголовок Content-Disposition, чтобы браузер скачал файл под указанным именем вместо его отображения.
+// Если включён Config.CachePresignedURLs, одинаковые запросы получают ссылку из кеша.
+func (r *s3Manager) GetDownloadPresignedURL(ctx context.Context, storagePath StoragePath, fileName string, expireTime time.Duration, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return "", fmt.Errorf("GetDownloadPresignedURL: %w: file name is empty", ErrInvalidInput)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return "", fmt.Errorf("GetDownloadPresignedURL/s3Client: %w", err)
+	}
+
+	o := r.applyOptions(opts)
+	presignClient := s3.NewPresignClient(client)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return "", fmt.Errorf("GetDownloadPresignedURL/objectKey: %w", err)
+	}
+
+	getInput := &s3.GetObjectInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &fullPath,
+		VersionId: nonEmpty(o.versionID),
+	}
+	o.encryption.applyToGet(getInput) // Для SSE-C клиент должен передать ключ в заголовках при скачивании
+	if o.downloadName != "" {
+		contentDisposition := mime.FormatMediaType("attachment", map[string]string{"filename": o.downloadName})
+		if contentDisposition == "" {
+			return "", fmt.Errorf("GetDownloadPresignedURL: %w: invalid download name %q", ErrInvalidInput, o.downloadName)
+		}
+		getInput.ResponseContentDisposition = &contentDisposition
+	}
+
+	expireTime, err = r.presignExpireTime(expireTime)
+	if err != nil {
+		return "", fmt.Errorf("GetDownloadPresignedURL/presignExpireTime: %w", err)
 	}
 
+	presignCreds, err := r.presignCredentials(ctx, client)
+	if err != nil {
+		return "", fmt.Errorf("GetDownloadPresignedURL/presignCredentials: %w", err)
+	}
+	cacheKey := r.presignCacheKey(getInput, expireTime, presignCreds)
+	if presignedURL, ok := r.cachedPresignedURL(ctx, cacheKey); ok {
+		return presignedURL, nil
+	}
+
+	presignedRequest, err := presignClient.PresignGetObject(ctx, getInput, s3.WithPresignExpires(expireTime))
+	if err != nil {
+		return "", fmt.Errorf("GetDownloadPresignedURL/PresignGetObject: failed to create presigned request: %w", classifyError(err))
+	}
+	r.storePresignedURL(ctx, cacheKey, presignedRequest.URL, expireTime, presignCreds)
+
 	return presignedRequest.URL, nil
 }
 
-// Метод для получения полного пути к каталогу файла в бакете (без имени файла)
+// Метод для получения полного пути к каталогу файла в бакете (без имени файла). В паттерн каталога подставляются StoragePath.EntityKey или EntityID вместо %d
+// и значения StoragePath.Params вместо именованных параметров (например, "orgs/{org_id}/users/{user_id}/"). Возвращает пустую строку,
+// если каталог не зарегистрирован или не задан один из параметров; методы работы с файлами в этом случае возвращают ошибку (см. Config.LegacyCatalogPaths).
 func (r *s3Manager) GetCatalogPattern(storagePath StoragePath) string {
-	pathPattern, ok := r.storagePaths[storagePath.CatalogType]
-	if !ok {
+	if storagePath.CatalogType == "" && r.cfg.LegacyCatalogPaths {
+		return ""
+	}
+
+	catalogPath, err := r.catalogPath(storagePath)
+	if err != nil {
 		return ""
 	}
 
-	return fmt.Sprintf(storagePath.RootCatalog+pathPattern, storagePath.EntityID)
+	return storagePath.RootCatalog + catalogPath
+}
+
+// Формирует путь к каталогу файла без корневого каталога. Пустой CatalogType означает корневой каталог сервиса.
+// Для незарегистрированного типа каталога возвращает ErrUnknownCatalog.
+func (r *s3Manager) catalogPath(storagePath StoragePath) (string, error) {
+	if storagePath.CatalogType == "" {
+		return "", nil
+	}
+
+	pathPattern, ok := r.catalogPattern(storagePath.CatalogType)
+	if !ok {
+		return "", fmt.Errorf("catalogPath: %w: %q", ErrUnknownCatalog, storagePath.CatalogType)
+	}
+
+	catalogPath, err := expandCatalogPattern(pathPattern, storagePath)
+	if err != nil {
+		return "", fmt.Errorf("catalogPath/expandCatalogPattern: %w", err)
+	}
+
+	return catalogPath, nil
 }
 
 // Метод для добавления нового типа каталога с паттерном пути в бакете
 func (r *s3Manager) AddCatalog(catalogType CatalogType, pathPattern string) {
-	if r.storagePaths == nil {
-		r.storagePaths = make(map[CatalogType]string)
-	}
+	r.catalogMu.Lock()
+	defer r.catalogMu.Unlock()
+
 	r.storagePaths[catalogType] = pathPattern
 }
 
+// Метод для получения зарегистрированных типов каталогов с паттернами путей (включая PathCustomCatalog).
+// Возвращает копию: её изменение не влияет на менеджер.
+func (r *s3Manager) Catalogs() map[CatalogType]string {
+	r.catalogMu.RLock()
+	defer r.catalogMu.RUnlock()
+
+	catalogs := make(map[CatalogType]string, len(r.storagePaths))
+	for catalogType, pathPattern := range r.storagePaths {
+		catalogs[catalogType] = pathPattern
+	}
+
+	return catalogs
+}
+
+// Возвращает паттерн пути каталога и признак того, что каталог зарегистрирован
+func (r *s3Manager) catalogPattern(catalogType CatalogType) (string, bool) {
+	r.catalogMu.RLock()
+	defer r.catalogMu.RUnlock()
+
+	pathPattern, ok := r.storagePaths[catalogType]
+
+	return pathPattern, ok
+}
+
 // Метод для генерации URL-адреса объекта в бакете. Как правило используется для получения URL-адреса объекта, который будет загружен позже.
-func (r *s3Manager) GetObjectURL(storagePath StoragePath, fileName string) (string, error) {
+// Ссылка формируется через Config.CDN, если он задан, иначе по адресу хранилища; каталоги и имя файла экранируются (например, пробел — "%20").
+func (r *s3Manager) GetObjectURL(storagePath StoragePath, fileName string, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
 	if fileName == "" {
-		return "", fmt.Errorf("GetObjectURL: file name is empty")
+		return "", fmt.Errorf("GetObjectURL: %w: file name is empty", ErrInvalidInput)
 	}
 
-	storagePath.RootCatalog = r.cfg.RootCatalog
-	fullPath := r.GetCatalogPattern(storagePath) + fileName
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return "", fmt.Errorf("GetObjectURL/objectKey: %w", err)
+	}
 
-	var fileURL string
-	if r.cfg.CDN != "" {
-		fileURL = fmt.Sprintf("%s/%s", r.cfg.CDN, fullPath)
-	} else {
-		fileURL = fmt.Sprintf("%s/%s/%s", r.cfg.Endpoint, r.cfg.Name, fullPath)
+	return r.objectURL(fullPath), nil
+}
+
+// Метод для генерации URL-адресов нескольких файлов одного каталога (например, вложений в ответе API) в порядке fileNames.
+// Путь каталога вычисляется один раз; ссылки формируются так же, как в GetObjectURL.
+func (r *s3Manager) GetObjectURLs(storagePath StoragePath, fileNames []string, opts ...Option) ([]string, error) {
+	r = r.forCall(opts)
+
+	catalogKey, err := r.objectKey(storagePath, "")
+	if err != nil {
+		return nil, fmt.Errorf("GetObjectURLs/objectKey: %w", err)
 	}
 
-	return fileURL, nil
+	fileURLs := make([]string, 0, len(fileNames))
+	for i, fileName := range fileNames {
+		if fileName == "" {
+			return nil, fmt.Errorf("GetObjectURLs: %w: file name %d is empty", ErrInvalidInput, i)
+		}
+		fileURLs = append(fileURLs, r.objectURL(catalogKey+fileName))
+	}
+
+	return fileURLs, nil
+}
+
+// Метод для генерации URL-адресов нескольких файлов одного каталога, как GetObjectURLs, но в виде карты "имя файла — ссылка"
+func (r *s3Manager) GetObjectURLMap(storagePath StoragePath, fileNames []string, opts ...Option) (map[string]string, error) {
+	fileURLs, err := r.GetObjectURLs(storagePath, fileNames, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("GetObjectURLMap/GetObjectURLs: %w", err)
+	}
+
+	urlsByName := make(map[string]string, len(fileNames))
+	for i, fileName := range fileNames {
+		urlsByName[fileName] = fileURLs[i]
+	}
+
+	return urlsByName, nil
+}
+
+// Метод для получения файла из бакета. Возвращает поток с содержимым файла (его необходимо закрыть после чтения) и информацию о файле.
+// Содержимое, сжатое gzip или zstd (см. WithCompression), распаковывается, если не передана опция WithoutDecompression.
+// Если задан Config.DiskCacheDir, неизменившийся файл читается из дискового кеша (кроме файлов с SSE-C, проверки контрольной суммы и условий WithIfModifiedSince).
+func (r *s3Manager) GetFile(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (io.ReadCloser, *FileInfo, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return nil, nil, fmt.Errorf("GetFile: %w: file name is empty", ErrInvalidInput)
+	}
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetFile/objectKey: %w", err)
+	}
+
+	getInput := &s3.GetObjectInput{
+		Bucket:      &r.cfg.Name,
+		Key:         &fullPath,
+		VersionId:   nonEmpty(o.versionID),
+		IfNoneMatch: nonEmpty(o.notMatchETag),
+	}
+	if !o.modifiedSince.IsZero() {
+		getInput.IfModifiedSince = &o.modifiedSince
+	}
+	o.encryption.applyToGet(getInput)
+
+	var optFns []func(*s3.Options)
+	if o.validateChecksum {
+		getInput.ChecksumMode = types.ChecksumModeEnabled
+		optFns = append(optFns, withoutSDKResponseValidation)
+	}
+
+	// Файл из кеша проверяется условным запросом: если он не изменился, хранилище отвечает ErrNotModified без содержимого
+	var (
+		cacheName  string
+		cachedFile *os.File
+		cachedInfo *FileInfo
+	)
+	if r.diskCache != nil && !o.validateChecksum && getInput.IfNoneMatch == nil && getInput.IfModifiedSince == nil && len(o.encryption.CustomerKey) == 0 {
+		cacheName = diskCacheName(r.cfg.Name, fullPath, o.versionID)
+		cachedFile, cachedInfo = r.diskCache.open(cacheName)
+		if cachedFile != nil {
+			getInput.IfNoneMatch = &cachedInfo.ETag
+		}
+	}
+
+	// Контекст с тайм-аутом отменяется при закрытии потока, так как тело ответа читается уже после выхода из метода
+	ctx, cancel := withTimeout(ctx, r.cfg.DownloadTimeout)
+	output, err := r.client.GetObject(ctx, getInput, optFns...)
+	if err != nil && cachedFile != nil && errors.Is(classifyError(err), ErrNotModified) {
+		cancel() // Содержимое читается с диска, запрос к хранилищу завершён
+		body, err := readCachedFile(cachedFile, cachedInfo, fileName, o)
+		if err != nil {
+			return nil, nil, fmt.Errorf("GetFile/readCachedFile: %w", err)
+		}
+		return body, cachedInfo, nil
+	}
+	if cachedFile != nil {
+		cachedFile.Close()
+	}
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("GetFile/GetObject: %w", classifyError(err))
+	}
+
+	fileInfo := &FileInfo{
+		Name:            fileName,
+		Key:             fullPath,
+		Size:            aws.ToInt64(output.ContentLength),
+		ContentType:     aws.ToString(output.ContentType),
+		ContentEncoding: aws.ToString(output.ContentEncoding),
+		LastModified:    aws.ToTime(output.LastModified),
+		ETag:            aws.ToString(output.ETag),
+		Metadata:        decodeMetadata(output.Metadata),
+		VersionID:       aws.ToString(output.VersionId),
+	}
+
+	body := output.Body
+	if o.validateChecksum {
+		body = newChecksumReader(body, output)
+	}
+	if cacheName != "" {
+		body = r.diskCache.tee(cacheName, body, fileInfo)
+	}
+	if o.progress != nil {
+		body = newProgressReadCloser(body, aws.ToInt64(output.ContentLength), o.progress)
+	}
+	if !o.rawContent {
+		decompressed, err := decompressReader(body, fileInfo.ContentEncoding)
+		if err != nil {
+			body.Close()
+			cancel()
+			return nil, nil, fmt.Errorf("GetFile/decompressReader: %w", err)
+		}
+		body = decompressed
+	}
+
+	return &cancelOnCloseReader{ReadCloser: body, cancel: cancel}, fileInfo, nil
+}
+
+// Метод для получения файла из бакета, только если он изменился: если ETag файла совпадает с etag (например, из заголовка If-None-Match
+// запроса клиента), возвращается ErrNotModified без передачи содержимого, и обработчик может ответить 304 Not Modified.
+// ETag принимается как в кавычках, так и без них; слабый ETag ("W/...") сравнивается как сильный. С опцией WithIfModifiedSince
+// файл также не скачивается, если не изменён после указанного времени. Пустой etag не проверяется.
+func (r *s3Manager) GetFileIfChanged(ctx context.Context, storagePath StoragePath, fileName, etag string, opts ...Option) (io.ReadCloser, *FileInfo, error) {
+	if etag = strings.TrimPrefix(etag, "W/"); etag != "" && !strings.HasPrefix(etag, `"`) {
+		etag = `"` + etag + `"`
+	}
+
+	body, fileInfo, err := r.GetFile(ctx, storagePath, fileName, append(opts, withIfNoneMatchETag(etag))...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GetFileIfChanged/GetFile: %w", err)
+	}
+
+	return body, fileInfo, nil
+}
+
+// Метод для скачивания файла из бакета напрямую в io.Writer (например, в http.ResponseWriter или локальный файл) без буферизации всего файла в памяти.
+// Возвращает количество записанных байт.
+func (r *s3Manager) DownloadToWriter(ctx context.Context, storagePath StoragePath, fileName string, w io.Writer, opts ...Option) (int64, error) {
+	r = r.forCall(opts)
+
+	if w == nil {
+		return 0, fmt.Errorf("DownloadToWriter: %w: writer is nil", ErrInvalidInput)
+	}
+
+	body, _, err := r.GetFile(ctx, storagePath, fileName, opts...)
+	if err != nil {
+		return 0, fmt.Errorf("DownloadToWriter/GetFile: %w", err)
+	}
+	defer body.Close()
+
+	written, err := io.Copy(w, body)
+	if err != nil {
+		return written, fmt.Errorf("DownloadToWriter/Copy: %w", err)
+	}
+
+	return written, nil
+}
+
+// Метод для получения информации о файле в бакете (размер, MIME-тип, время изменения) без скачивания его содержимого
+func (r *s3Manager) StatFile(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (*FileInfo, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return nil, fmt.Errorf("StatFile: %w: file name is empty", ErrInvalidInput)
+	}
+
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("StatFile/objectKey: %w", err)
+	}
+
+	fileInfo, err := r.statObject(ctx, fileName, fullPath, r.applyOptions(opts))
+	if err != nil {
+		return nil, fmt.Errorf("StatFile/statObject: %w", err)
+	}
+
+	return fileInfo, nil
+}
+
+// Получает информацию об объекте с ключом key
+func (r *s3Manager) statObject(ctx context.Context, fileName, key string, o operationOptions) (*FileInfo, error) {
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	headInput := &s3.HeadObjectInput{
+		Bucket:    &r.cfg.Name,
+		Key:       &key,
+		VersionId: nonEmpty(o.versionID),
+	}
+	o.encryption.applyToHead(headInput)
+
+	output, err := r.client.HeadObject(ctx, headInput)
+	if err != nil {
+		return nil, fmt.Errorf("statObject/HeadObject: %w", classifyError(err))
+	}
+
+	fileInfo := &FileInfo{
+		Name:            fileName,
+		Key:             key,
+		Size:            aws.ToInt64(output.ContentLength),
+		ContentType:     aws.ToString(output.ContentType),
+		ContentEncoding: aws.ToString(output.ContentEncoding),
+		LastModified:    aws.ToTime(output.LastModified),
+		ETag:            aws.ToString(output.ETag),
+		Metadata:        decodeMetadata(output.Metadata),
+		VersionID:       aws.ToString(output.VersionId),
+		LegalHold:       output.ObjectLockLegalHoldStatus == types.ObjectLockLegalHoldStatusOn,
+		StorageClass:    string(output.StorageClass),
+		Redirect:        aws.ToString(output.WebsiteRedirectLocation),
+	}
+	if output.ObjectLockMode != "" {
+		fileInfo.Retention = &Retention{
+			Mode:        types.ObjectLockRetentionMode(output.ObjectLockMode),
+			RetainUntil: aws.ToTime(output.ObjectLockRetainUntilDate),
+		}
+	}
+
+	return fileInfo, nil
+}
+
+// Метод для проверки существования файла в бакете
+func (r *s3Manager) FileExists(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (bool, error) {
+	_, err := r.StatFile(ctx, storagePath, fileName, opts...)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("FileExists/StatFile: %w", err)
+	}
+
+	return true, nil
+}
+
+// Формирует полный ключ объекта в бакете (путь к каталогу с учётом корневого каталога сервиса + имя файла).
+// Возвращает ошибку, если путь к каталогу сформировать нельзя (см. catalogPath), чтобы файл не попал в корень бакета.
+// С Config.LegacyCatalogPaths для пустого или незарегистрированного каталога возвращает имя файла без корневого каталога, как прежние версии.
+// В вызовах с withRawKey имя файла уже является ключом и возвращается без изменений.
+func (r *s3Manager) objectKey(storagePath StoragePath, fileName string) (string, error) {
+	if r.rawKeys {
+		return fileName, nil
+	}
+
+	catalogPath, err := r.catalogPath(storagePath)
+	if r.cfg.LegacyCatalogPaths && (storagePath.CatalogType == "" || err != nil) {
+		return fileName, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("objectKey/catalogPath: %w", err)
+	}
+
+	return r.cfg.RootCatalog + catalogPath + fileName, nil
 }
@@ -0,0 +1,76 @@
+// Пакет с in-memory реализацией S3Manager для модульных тестов сервисов, использующих библиотеку.
+// Файлы хранятся в памяти процесса, поэтому тесты не требуют S3-совместимого сервера и моков для каждого метода.
+package s3managertest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	s3_manager "s3-manager"
+)
+
+const (
+	DefaultEndpoint = "http://s3.test" // Адрес хранилища, используемый в ссылках на файлы, если он не указан в конфиге
+	DefaultBucket   = "test-bucket"    // Имя бакета, если оно не указано в конфиге
+)
+
+// In-memory реализация S3Manager. Все методы работают так же, как с настоящим хранилищем,
+// а подписанные ссылки формируются без обращения к S3 (подпись в них фиктивная).
+type Manager struct {
+	s3_manager.S3Manager
+	Backend s3_manager.StorageBackend // Хранилище объектов; можно использовать для проверки содержимого бакета в тестах
+
+	readOnly bool
+}
+
+var _ s3_manager.S3Manager = (*Manager)(nil)
+
+// Создаёт in-memory S3Manager. Если cfg равен nil или в нём не заполнены адрес и имя бакета, используются DefaultEndpoint и DefaultBucket.
+func New(cfg *s3_manager.Config) *Manager {
+	if cfg == nil {
+		cfg = &s3_manager.Config{}
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = DefaultEndpoint
+	}
+	if cfg.Name == "" {
+		cfg.Name = DefaultBucket
+	}
+
+	backend := s3_manager.NewMemoryBackend()
+	manager, err := s3_manager.NewS3ManagerWithBackend(backend, cfg, false)
+	if err != nil {
+		panic(fmt.Sprintf("s3managertest.New: %v", err)) // Возможно только при ошибке в самой библиотеке
+	}
+
+	return &Manager{
+		S3Manager: manager,
+		Backend:   backend,
+		readOnly:  cfg.ReadOnly,
+	}
+}
+
+// Возвращает фиктивную подписанную ссылку на загрузку файла
+func (m *Manager) GetUploadPresignedURL(ctx context.Context, storagePath s3_manager.StoragePath, fileName string, expireTime time.Duration, opts ...s3_manager.Option) (string, error) {
+	if m.readOnly {
+		return "", fmt.Errorf("GetUploadPresignedURL: %w", s3_manager.ErrReadOnly)
+	}
+
+	return m.presignedURL(storagePath, fileName, expireTime, opts)
+}
+
+// Возвращает фиктивную подписанную ссылку на скачивание файла
+func (m *Manager) GetDownloadPresignedURL(ctx context.Context, storagePath s3_manager.StoragePath, fileName string, expireTime time.Duration, opts ...s3_manager.Option) (string, error) {
+	return m.presignedURL(storagePath, fileName, expireTime, opts)
+}
+
+// Формирует ссылку на объект с параметрами, похожими на параметры подписанной ссылки S3
+func (m *Manager) presignedURL(storagePath s3_manager.StoragePath, fileName string, expireTime time.Duration, opts []s3_manager.Option) (string, error) {
+	objectURL, err := m.GetObjectURL(storagePath, fileName, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s?X-Amz-Expires=%d&X-Amz-Signature=test", objectURL, int64(expireTime.Seconds())), nil
+}
@@ -0,0 +1,79 @@
+package s3_manager
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Настройки очистки имён загружаемых файлов (BucketFile.Name) перед формированием ключа объекта.
+// Очистка не применяется к SyncUp и MirrorPrefix, которые сохраняют относительные пути файлов.
+type NameSanitization struct {
+	Enabled            bool // Включить очистку: из имени удаляются каталоги (всё до последнего "/" или "\"), управляющие символы и пробелы по краям
+	NormalizeUnicode   bool // Приводить имя к нормальной форме NFC, чтобы имена из macOS (NFD) и других систем давали одинаковые ключи
+	Transliterate      bool // Транслитерировать кириллицу в латиницу (например, "Фото.jpg" -> "Foto.jpg")
+	LowercaseExtension bool // Приводить расширение к нижнему регистру (например, "IMG.JPG" -> "IMG.jpg")
+}
+
+// Транслитерация русских и украинских букв (строчных; заглавные обрабатываются отдельно)
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i", 'й': "y",
+	'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+}
+
+// Очищает имя файла по настройкам. Возвращает ErrInvalidInput, если после очистки имя пустое или ссылается на каталог ("." или "..").
+func (s NameSanitization) sanitize(name string) (string, error) {
+	if !s.Enabled {
+		return name, nil
+	}
+
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+
+	if s.NormalizeUnicode {
+		name = norm.NFC.String(name)
+	}
+	if s.Transliterate {
+		name = transliterate(name)
+	}
+	if s.LowercaseExtension {
+		ext := path.Ext(name)
+		name = strings.TrimSuffix(name, ext) + strings.ToLower(ext)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("sanitize: %w: file name is empty after sanitization", ErrInvalidInput)
+	}
+
+	return name, nil
+}
+
+// Заменяет кириллические буквы латинскими, сохраняя регистр первой буквы
+func transliterate(name string) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(name) { // "й" и "ё" в NFD состоят из двух символов
+		latin, ok := cyrillicToLatin[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsUpper(r) && latin != "" {
+			latin = strings.ToUpper(latin[:1]) + latin[1:]
+		}
+		b.WriteString(latin)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Формат файла для запросов S3 Select (см. QueryObject)
+type SelectFormat string
+
+const (
+	SelectCSV          SelectFormat = "csv"           // CSV с заголовком: столбцы доступны по именам (s."name"). Результат в CSV.
+	SelectTSV          SelectFormat = "tsv"           // То же, что SelectCSV, с разделителем табуляцией. Результат в TSV.
+	SelectJSONLines    SelectFormat = "json_lines"    // JSON Lines: по одному объекту JSON в строке. Результат в JSON Lines.
+	SelectJSONDocument SelectFormat = "json_document" // Один документ JSON (обращение к массивам через s3object[*]). Результат в JSON Lines.
+	SelectParquet      SelectFormat = "parquet"       // Apache Parquet. Результат в JSON Lines.
+)
+
+// Метод для выполнения SQL-запроса S3 Select к файлу в бакете (например, SELECT s.id, s.total FROM s3object s WHERE s.status = 'paid'):
+// файл фильтруется на стороне хранилища, и по сети передаются только подходящие строки. Файлы, сжатые gzip или bzip2
+// (по Content-Encoding или расширению .gz/.bz2), распаковываются хранилищем. Возвращает поток с результатом, который необходимо закрыть после чтения.
+// Поддерживается только в Amazon S3 (для остальных хранилищ возвращается ErrNotSupported).
+func (r *s3Manager) QueryObject(ctx context.Context, storagePath StoragePath, fileName, sqlExpr string, format SelectFormat, opts ...Option) (io.ReadCloser, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" || sqlExpr == "" {
+		return nil, fmt.Errorf("QueryObject: %w: file name or expression is empty", ErrInvalidInput)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return nil, fmt.Errorf("QueryObject/s3Client: %w", err)
+	}
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return nil, fmt.Errorf("QueryObject/objectKey: %w", err)
+	}
+
+	// Сжатие нужно передать в запросе явно, поэтому сначала получаем Content-Encoding объекта
+	info, err := r.statObject(ctx, fileName, fullPath, o)
+	if err != nil {
+		return nil, fmt.Errorf("QueryObject/statObject: %w", err)
+	}
+
+	input, output, err := selectSerialization(format, selectCompression(fileName, info.ContentEncoding))
+	if err != nil {
+		return nil, fmt.Errorf("QueryObject/selectSerialization: %w", err)
+	}
+
+	selectInput := &s3.SelectObjectContentInput{
+		Bucket:              &r.cfg.Name,
+		Key:                 &fullPath,
+		Expression:          &sqlExpr,
+		ExpressionType:      types.ExpressionTypeSql,
+		InputSerialization:  input,
+		OutputSerialization: output,
+	}
+	o.encryption.applyToSelect(selectInput)
+
+	// Контекст с тайм-аутом отменяется при закрытии потока, так как результат читается уже после выхода из метода
+	ctx, cancel := withTimeout(ctx, r.cfg.DownloadTimeout)
+	selectOutput, err := client.SelectObjectContent(ctx, selectInput)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("QueryObject/SelectObjectContent: %w", classifyError(err))
+	}
+
+	return &selectReader{stream: selectOutput.GetStream(), cancel: cancel}, nil
+}
+
+// Определяет сжатие файла для S3 Select по Content-Encoding или расширению
+func selectCompression(fileName, contentEncoding string) types.CompressionType {
+	switch {
+	case contentEncoding == string(CompressionGzip) || strings.HasSuffix(fileName, ".gz"):
+		return types.CompressionTypeGzip
+	case strings.HasSuffix(fileName, ".bz2"):
+		return types.CompressionTypeBzip2
+	default:
+		return types.CompressionTypeNone
+	}
+}
+
+// Формирует описание формата файла и результата запроса S3 Select
+func selectSerialization(format SelectFormat, compression types.CompressionType) (*types.InputSerialization, *types.OutputSerialization, error) {
+	input := &types.InputSerialization{CompressionType: compression}
+	jsonOutput := &types.OutputSerialization{JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")}}
+
+	switch format {
+	case SelectCSV, SelectTSV:
+		delimiter := ","
+		if format == SelectTSV {
+			delimiter = "\t"
+		}
+		input.CSV = &types.CSVInput{FileHeaderInfo: types.FileHeaderInfoUse, FieldDelimiter: &delimiter}
+		return input, &types.OutputSerialization{CSV: &types.CSVOutput{FieldDelimiter: &delimiter}}, nil
+	case SelectJSONLines:
+		input.JSON = &types.JSONInput{Type: types.JSONTypeLines}
+		return input, jsonOutput, nil
+	case SelectJSONDocument:
+		input.JSON = &types.JSONInput{Type: types.JSONTypeDocument}
+		return input, jsonOutput, nil
+	case SelectParquet:
+		input.Parquet = &types.ParquetInput{}
+		input.CompressionType = types.CompressionTypeNone // Parquet сжимается внутри файла
+		return input, jsonOutput, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown select format %q", ErrInvalidInput, format)
+	}
+}
+
+// Поток с результатом запроса S3 Select, собранный из событий Records
+type selectReader struct {
+	stream *s3.SelectObjectContentEventStream
+	cancel context.CancelFunc
+	buf    []byte // Непрочитанная часть последнего события Records
+	ended  bool   // Получено событие End: результат передан полностью
+}
+
+func (s *selectReader) Read(p []byte) (int, error) {
+	for len(s.buf) == 0 {
+		event, ok := <-s.stream.Events()
+		if !ok {
+			if err := s.stream.Err(); err != nil {
+				return 0, fmt.Errorf("selectReader: %w", classifyError(err))
+			}
+			if !s.ended {
+				return 0, io.ErrUnexpectedEOF // Поток событий оборвался до события End
+			}
+			return 0, io.EOF
+		}
+
+		switch event := event.(type) {
+		case *types.SelectObjectContentEventStreamMemberRecords:
+			s.buf = event.Value.Payload
+		case *types.SelectObjectContentEventStreamMemberEnd:
+			s.ended = true
+		}
+	}
+
+	n := copy(p, s.buf)
+	s.buf = s.buf[n:]
+
+	return n, nil
+}
+
+func (s *selectReader) Close() error {
+	defer s.cancel()
+	return s.stream.Close()
+}
@@ -0,0 +1,133 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Метод для подсчёта количества файлов и их общего размера в байтах в каталоге storagePath (например, объём файлов пользователя).
+// Список объектов обходится постранично; с опцией WithPrefixFanout подкаталоги обходятся параллельно (см. WithConcurrency).
+func (r *s3Manager) GetCatalogStats(ctx context.Context, storagePath StoragePath, opts ...Option) (int64, int64, error) {
+	r = r.forCall(opts)
+
+	prefix, err := r.objectKey(storagePath, "")
+	if err != nil {
+		return 0, 0, fmt.Errorf("GetCatalogStats/objectKey: %w", err)
+	}
+
+	count, size, err := r.prefixStats(ctx, prefix, nil, r.applyOptions(opts))
+	if err != nil {
+		return 0, 0, fmt.Errorf("GetCatalogStats/prefixStats: %w", err)
+	}
+
+	return count, size, nil
+}
+
+// Метод для подсчёта количества файлов и их общего размера в байтах во всех каталогах типа catalogType (например, объём всех
+// сертификатов товаров). Обходятся файлы с общей частью пути каталога до первого параметра (например, "products/" для "products/%d/certificates/"),
+// а учитываются только файлы, которые ResolvePath относит к этому типу: файлы вложенных каталогов других типов (например, "products/%d/certificates/"
+// для "products/%d/") не учитываются. Для незарегистрированного типа возвращает ErrUnknownCatalog.
+// С опцией WithPrefixFanout каталоги сущностей обходятся параллельно.
+func (r *s3Manager) GetCatalogTypeStats(ctx context.Context, catalogType CatalogType, opts ...Option) (int64, int64, error) {
+	r = r.forCall(opts)
+
+	pathPattern, ok := r.catalogPattern(catalogType)
+	if !ok {
+		return 0, 0, fmt.Errorf("GetCatalogTypeStats: %w: %q", ErrUnknownCatalog, catalogType)
+	}
+
+	catalogs := r.Catalogs()
+	inCatalogType := func(key string) bool {
+		storagePath, _, ok := matchCatalogs(catalogs, strings.TrimPrefix(key, r.cfg.RootCatalog))
+		return ok && storagePath.CatalogType == catalogType
+	}
+	count, size, err := r.prefixStats(ctx, r.cfg.RootCatalog+patternPrefix(pathPattern), inCatalogType, r.applyOptions(opts))
+	if err != nil {
+		return 0, 0, fmt.Errorf("GetCatalogTypeStats/prefixStats: %w", err)
+	}
+
+	return count, size, nil
+}
+
+// Подсчитывает количество объектов и их общий размер по префиксу (если filter не nil — только объектов, для ключей которых он возвращает true).
+// При o.prefixFanout сначала получает подкаталоги первого уровня и обходит их параллельно, иначе обходит весь список объектов последовательно.
+func (r *s3Manager) prefixStats(ctx context.Context, prefix string, filter func(string) bool, o operationOptions) (int64, int64, error) {
+	ctx, cancel := withTimeout(ctx, r.cfg.ListTimeout)
+	defer cancel()
+
+	if !o.prefixFanout {
+		return r.listStats(ctx, prefix, "", filter, nil)
+	}
+
+	var subPrefixes []string
+	count, size, err := r.listStats(ctx, prefix, "/", filter, func(commonPrefix string) {
+		subPrefixes = append(subPrefixes, commonPrefix)
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("prefixStats/listStats: %w", err)
+	}
+
+	var (
+		mu     sync.Mutex
+		failed = make(map[string]error)
+	)
+
+	tasks := r.newBatch(o.concurrency)
+	for _, subPrefix := range subPrefixes {
+		tasks.Go(func() {
+			subCount, subSize, err := r.listStats(ctx, subPrefix, "", filter, nil)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[subPrefix] = err
+				return
+			}
+			count += subCount
+			size += subSize
+		})
+	}
+	tasks.Wait()
+
+	if len(failed) > 0 {
+		return 0, 0, fmt.Errorf("prefixStats: %w", &BatchError{Errors: failed})
+	}
+
+	return count, size, nil
+}
+
+// Постранично обходит объекты по префиксу и подсчитывает их количество и общий размер.
+// Если delimiter не пуст, объекты в подкаталогах (общие префиксы) не подсчитываются, а префиксы подкаталогов передаются в onPrefix.
+// Если filter не nil, подсчитываются только объекты, для ключей которых он возвращает true.
+func (r *s3Manager) listStats(ctx context.Context, prefix, delimiter string, filter func(string) bool, onPrefix func(string)) (int64, int64, error) {
+	var count, size int64
+	paginator := s3.NewListObjectsV2Paginator(r.client, &s3.ListObjectsV2Input{
+		Bucket:    &r.cfg.Name,
+		Prefix:    &prefix,
+		Delimiter: nonEmpty(delimiter),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("listStats/ListObjectsV2: %w", classifyError(err))
+		}
+
+		for _, object := range page.Contents {
+			if filter != nil && !filter(aws.ToString(object.Key)) {
+				continue
+			}
+			count++
+			size += aws.ToInt64(object.Size)
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			onPrefix(aws.ToString(commonPrefix.Prefix))
+		}
+	}
+
+	return count, size, nil
+}
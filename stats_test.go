@@ -0,0 +1,62 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGetCatalogTypeStatsExcludesNestedCatalogs(t *testing.T) {
+	ctx := context.Background()
+	manager, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+	manager.AddCatalog("user", "user/%d/")
+	manager.AddCatalog("user_avatars", "user/%d/avatars/")
+
+	files := []struct {
+		catalogType CatalogType
+		entityID    int64
+		name        string
+		content     string
+	}{
+		{"user", 1, "profile.json", "12345"},
+		{"user", 2, "profile.json", "123"},
+		{"user_avatars", 1, "avatar.png", "1234567890"},
+		{"user_avatars", 2, "avatar.png", "1234567"},
+	}
+	for _, file := range files {
+		storagePath := StoragePath{CatalogType: file.catalogType, EntityID: file.entityID}
+		_, err := manager.PutFile(ctx, storagePath, &BucketFile{Name: file.name, File: bytes.NewReader([]byte(file.content))}, withRawName())
+		if err != nil {
+			t.Fatalf("PutFile(%q): %v", file.name, err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		catalogType CatalogType
+		opts        []Option
+		wantCount   int64
+		wantSize    int64
+	}{
+		{"parent", "user", nil, 2, 8},
+		{"parent with fanout", "user", []Option{WithPrefixFanout()}, 2, 8},
+		{"nested", "user_avatars", nil, 2, 17},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, size, err := manager.GetCatalogTypeStats(ctx, tt.catalogType, tt.opts...)
+			if err != nil {
+				t.Fatalf("GetCatalogTypeStats: %v", err)
+			}
+			if count != tt.wantCount || size != tt.wantSize {
+				t.Errorf("GetCatalogTypeStats = (%d, %d), want (%d, %d)", count, size, tt.wantCount, tt.wantSize)
+			}
+		})
+	}
+
+	_, _, err := manager.GetCatalogTypeStats(ctx, "unknown")
+	if !errors.Is(err, ErrUnknownCatalog) {
+		t.Errorf("GetCatalogTypeStats(unknown) error = %v, want ErrUnknownCatalog", err)
+	}
+}
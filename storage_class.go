@@ -0,0 +1,66 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Метод для добавления нового типа каталога с паттерном пути в бакете и классом хранения загружаемых в него файлов по умолчанию
+// (например, types.StorageClassStandardIa или types.StorageClassGlacierIr для архивных каталогов). Класс хранения отдельного файла
+// можно изменить опцией WithStorageClass.
+func (r *s3Manager) AddCatalogWithStorageClass(catalogType CatalogType, pathPattern string, storageClass types.StorageClass) {
+	r.AddCatalog(catalogType, pathPattern)
+
+	r.catalogMu.Lock()
+	defer r.catalogMu.Unlock()
+
+	r.catalogStorageClass[catalogType] = storageClass
+}
+
+// Определяет класс хранения загружаемого или копируемого файла: из опции WithStorageClass или класс каталога по умолчанию.
+// Возвращает пустую строку, если класс не задан (используется класс бакета по умолчанию, обычно STANDARD).
+func (r *s3Manager) uploadStorageClass(storagePath StoragePath, o operationOptions) types.StorageClass {
+	if o.storageClass != "" {
+		return o.storageClass
+	}
+
+	r.catalogMu.RLock()
+	defer r.catalogMu.RUnlock()
+
+	return r.catalogStorageClass[storagePath.CatalogType]
+}
+
+// Метод для перевода загруженного файла в другой класс хранения (например, в types.StorageClassGlacier после закрытия сделки).
+// Файл копируется сам в себя на стороне S3 с новым классом хранения; содержимое, MIME-тип и метаданные сохраняются.
+// ACL передаётся только опцией WithACL, иначе файл получает ACL по умолчанию бакета (Config.DefaultACL не применяется).
+// Файлы в классах GLACIER и DEEP_ARCHIVE перед переводом нужно восстановить. Для автоматического перевода по возрасту файлов
+// используются правила жизненного цикла (см. PutLifecycleRules).
+func (r *s3Manager) TransitionStorageClass(ctx context.Context, storagePath StoragePath, fileName string, storageClass types.StorageClass, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return fmt.Errorf("TransitionStorageClass: %w: file name is empty", ErrInvalidInput)
+	}
+	if storageClass == "" {
+		return fmt.Errorf("TransitionStorageClass: %w: storage class is empty", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	o.storageClass = storageClass
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return fmt.Errorf("TransitionStorageClass/objectKey: %w", err)
+	}
+
+	err = r.copyObject(ctx, fullPath, fullPath, o)
+	if err != nil {
+		return fmt.Errorf("TransitionStorageClass/copyObject: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestTransitionStorageClassKeepsPrivateACL(t *testing.T) {
+	ctx := context.Background()
+	backend := newACLBackend()
+	manager := newTestManagerWithBackend(t, backend, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+
+	_, err := manager.PutFile(ctx, StoragePath{}, &BucketFile{Name: "contract.pdf", File: bytes.NewReader([]byte("secret"))}, withRawName(), WithACL(types.ObjectCannedACLPrivate))
+	if err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+
+	err = manager.TransitionStorageClass(ctx, StoragePath{}, "contract.pdf", types.StorageClassGlacierIr)
+	if err != nil {
+		t.Fatalf("TransitionStorageClass: %v", err)
+	}
+
+	if acl := backend.acl("contract.pdf"); acl != types.ObjectCannedACLPrivate {
+		t.Errorf("ACL = %q, want %q", acl, types.ObjectCannedACLPrivate)
+	}
+	head, err := backend.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String("b"), Key: aws.String("contract.pdf")})
+	if err != nil {
+		t.Fatalf("HeadObject: %v", err)
+	}
+	if head.StorageClass != types.StorageClassGlacierIr {
+		t.Errorf("StorageClass = %q, want %q", head.StorageClass, types.StorageClassGlacierIr)
+	}
+}
@@ -0,0 +1,379 @@
+package s3_manager
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Параметры синхронизации локального каталога с каталогом в бакете
+type SyncOptions struct {
+	Delete  bool     // Удалять файлы в приёмнике, которых нет в источнике
+	Include []string // Шаблоны path.Match файлов для синхронизации (например, "*.jpg" или "images/*"). По умолчанию синхронизируются все файлы.
+	Exclude []string // Шаблоны path.Match файлов, которые не синхронизируются и не удаляются. Проверяются после Include.
+}
+
+// Проверяет, подходит ли файл под шаблоны Include и Exclude. Шаблон без "/" сравнивается и с именем файла без каталога.
+func (s SyncOptions) matches(name string) bool {
+	matchAny := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				return true
+			}
+			if !strings.Contains(pattern, "/") {
+				if ok, _ := path.Match(pattern, path.Base(name)); ok {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if len(s.Include) > 0 && !matchAny(s.Include) {
+		return false
+	}
+	return !matchAny(s.Exclude)
+}
+
+// Результат синхронизации. Имена файлов указываются относительно синхронизируемых каталогов, с разделителем "/".
+type SyncResult struct {
+	Copied    []string // Загруженные (SyncUp) или скачанные (SyncDown) файлы: новые и изменённые
+	Deleted   []string // Удалённые файлы, которых нет в источнике (при SyncOptions.Delete)
+	Unchanged int      // Количество файлов, которые совпадают в источнике и приёмнике
+}
+
+// Метод для синхронизации локального каталога localDir с каталогом storagePath в бакете (аналог aws s3 sync).
+// Загружаются новые файлы и файлы, которые отличаются размером или содержимым (MD5 сравнивается с ETag; для файлов, загруженных
+// через multipart, вместо содержимого сравнивается время изменения; для каталогов со сжатием (см. AddCatalogWithCompression, WithCompression)
+// время изменения сравнивается вместо размера и содержимого). Файлы загружаются параллельно (см. WithConcurrency),
+// опции загрузки (ACL, шифрование и т.д.) применяются к каждому файлу. Если часть файлов обработать не удалось,
+// возвращается результат по остальным файлам и ошибка *BatchError.
+func (r *s3Manager) SyncUp(ctx context.Context, localDir string, storagePath StoragePath, syncOpts SyncOptions, opts ...Option) (*SyncResult, error) {
+	r = r.forCall(opts)
+
+	dirInfo, err := os.Stat(localDir)
+	if err != nil {
+		return nil, fmt.Errorf("SyncUp/Stat: %w", err)
+	}
+	if !dirInfo.IsDir() {
+		return nil, fmt.Errorf("SyncUp: %w: %s is not a directory", ErrInvalidInput, localDir)
+	}
+
+	o := r.applyOptions(opts)
+	prefix, err := r.objectKey(storagePath, "")
+	if err != nil {
+		return nil, fmt.Errorf("SyncUp/objectKey: %w", err)
+	}
+
+	remote, err := r.listCatalog(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("SyncUp/listCatalog: %w", err)
+	}
+
+	var (
+		mu       sync.Mutex
+		result   = &SyncResult{}
+		failed   = make(map[string]error)
+		existing = make(map[string]bool)
+	)
+
+	compressed := r.uploadCompression(storagePath, o) != ""
+	tasks := r.newBatch(o.concurrency)
+	err = filepath.WalkDir(localDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil // Каталоги обходятся рекурсивно, символические ссылки и специальные файлы пропускаются
+		}
+
+		relPath, err := filepath.Rel(localDir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relPath)
+		if !syncOpts.matches(name) {
+			return nil
+		}
+		existing[name] = true
+
+		tasks.Go(func() {
+			changed, err := r.syncUpFile(ctx, path, storagePath, name, remote, compressed, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed[name] = err
+			case changed:
+				result.Copied = append(result.Copied, name)
+			default:
+				result.Unchanged++
+			}
+		})
+
+		return nil
+	})
+	tasks.Wait()
+	if err != nil {
+		return result, fmt.Errorf("SyncUp/WalkDir: %w", err)
+	}
+
+	if syncOpts.Delete {
+		var orphans, keys []string
+		for name := range remote {
+			if !existing[name] && syncOpts.matches(name) {
+				orphans = append(orphans, name)
+				keys = append(keys, prefix+name)
+			}
+		}
+
+		_, err := r.removeObjects(ctx, keys, o)
+		if err != nil {
+			return result, fmt.Errorf("SyncUp/removeObjects: %w", err)
+		}
+		r.invalidate(ctx, keys...)
+		result.Deleted = orphans
+	}
+
+	sort.Strings(result.Copied)
+	sort.Strings(result.Deleted)
+
+	if len(failed) > 0 {
+		return result, fmt.Errorf("SyncUp: %w", &BatchError{Errors: failed})
+	}
+
+	return result, nil
+}
+
+// Загружает локальный файл, если он отличается от файла в бакете (compressed — файл сжимается при загрузке). Возвращает true, если файл был загружен.
+func (r *s3Manager) syncUpFile(ctx context.Context, path string, storagePath StoragePath, name string, remote map[string]ObjectInfo, compressed bool, opts []Option) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("syncUpFile/Open: %w", err)
+	}
+	defer file.Close()
+
+	if object, ok := remote[name]; ok {
+		changed, err := localFileChanged(file, object, true, compressed)
+		if err != nil {
+			return false, fmt.Errorf("syncUpFile/localFileChanged: %w", err)
+		}
+		if !changed {
+			return false, nil
+		}
+	}
+
+	_, err = r.PutFile(ctx, storagePath, &BucketFile{File: file, Name: name}, append([]Option{withRawName()}, opts...)...)
+	if err != nil {
+		return false, fmt.Errorf("syncUpFile/PutFile: %w", err)
+	}
+
+	return true, nil
+}
+
+// Сравнивает локальный файл с объектом в бакете: по размеру, затем по MD5, если ETag объекта равен MD5 содержимого, иначе по времени изменения:
+// при загрузке (upload) файл считается изменённым, если он изменён позже объекта, при скачивании — если время изменения файла
+// не совпадает со временем изменения объекта (SyncDown устанавливает его после скачивания). Размер и ETag сжатого объекта (compressed)
+// относятся к сжатому содержимому, поэтому для него сравнивается только время изменения. Возвращает поток на начало файла.
+func localFileChanged(file *os.File, object ObjectInfo, upload, compressed bool) (bool, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return false, fmt.Errorf("localFileChanged/Stat: %w", err)
+	}
+	if !compressed && info.Size() != object.Size {
+		return true, nil
+	}
+
+	etag, ok := md5ETag(object.ETag)
+	if !ok || compressed {
+		if upload {
+			return info.ModTime().After(object.LastModified), nil
+		}
+		return !info.ModTime().Equal(object.LastModified), nil
+	}
+
+	h := md5.New()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return false, fmt.Errorf("localFileChanged/Copy: %w", err)
+	}
+	_, err = file.Seek(0, io.SeekStart)
+	if err != nil {
+		return false, fmt.Errorf("localFileChanged/Seek: %w", err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)) != etag, nil
+}
+
+// Метод для синхронизации каталога storagePath в бакете с локальным каталогом localDir (обратная операция к SyncUp).
+// Скачиваются новые файлы и файлы, которые отличаются размером или содержимым; время изменения скачанных файлов устанавливается равным
+// времени изменения объектов. Файлы скачиваются параллельно (см. WithConcurrency). Если часть файлов обработать не удалось,
+// возвращается результат по остальным файлам и ошибка *BatchError.
+func (r *s3Manager) SyncDown(ctx context.Context, storagePath StoragePath, localDir string, syncOpts SyncOptions, opts ...Option) (*SyncResult, error) {
+	r = r.forCall(opts)
+
+	if localDir == "" {
+		return nil, fmt.Errorf("SyncDown: %w: local directory is empty", ErrInvalidInput)
+	}
+
+	o := r.applyOptions(opts)
+
+	prefix, err := r.objectKey(storagePath, "")
+	if err != nil {
+		return nil, fmt.Errorf("SyncDown/objectKey: %w", err)
+	}
+	remote, err := r.listCatalog(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("SyncDown/listCatalog: %w", err)
+	}
+
+	err = os.MkdirAll(localDir, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("SyncDown/MkdirAll: %w", err)
+	}
+	root, err := filepath.Abs(localDir)
+	if err != nil {
+		return nil, fmt.Errorf("SyncDown/Abs: %w", err)
+	}
+
+	var (
+		mu     sync.Mutex
+		result = &SyncResult{}
+		failed = make(map[string]error)
+	)
+
+	tasks := r.newBatch(o.concurrency)
+	for name, object := range remote {
+		if !syncOpts.matches(name) {
+			continue
+		}
+
+		localPath := filepath.Join(root, filepath.FromSlash(name))
+		if !strings.HasPrefix(localPath, root+string(filepath.Separator)) {
+			mu.Lock()
+			failed[name] = fmt.Errorf("%w: file name is outside of local directory", ErrInvalidInput) // Ключи вида "../file" не должны выходить за пределы каталога
+			mu.Unlock()
+			continue
+		}
+
+		tasks.Go(func() {
+			changed, err := r.syncDownFile(ctx, storagePath, name, localPath, object, opts)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				failed[name] = err
+			case changed:
+				result.Copied = append(result.Copied, name)
+			default:
+				result.Unchanged++
+			}
+		})
+	}
+	tasks.Wait()
+
+	if syncOpts.Delete {
+		err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+
+			relPath, err := filepath.Rel(root, path)
+			if err != nil {
+				return err
+			}
+			name := filepath.ToSlash(relPath)
+			if _, ok := remote[name]; ok || !syncOpts.matches(name) {
+				return nil
+			}
+
+			err = os.Remove(path)
+			if err != nil {
+				return err
+			}
+			result.Deleted = append(result.Deleted, name)
+
+			return nil
+		})
+		if err != nil {
+			return result, fmt.Errorf("SyncDown/WalkDir: %w", err)
+		}
+	}
+
+	sort.Strings(result.Copied)
+	sort.Strings(result.Deleted)
+
+	if len(failed) > 0 {
+		return result, fmt.Errorf("SyncDown: %w", &BatchError{Errors: failed})
+	}
+
+	return result, nil
+}
+
+// Скачивает объект в локальный файл, если файл отличается от объекта. Возвращает true, если файл был скачан.
+func (r *s3Manager) syncDownFile(ctx context.Context, storagePath StoragePath, name, localPath string, object ObjectInfo, opts []Option) (bool, error) {
+	file, err := os.Open(localPath)
+	if err == nil {
+		changed, err := localFileChanged(file, object, false, false) // DownloadToFile сохраняет содержимое объекта без распаковки
+		file.Close()
+		if err != nil {
+			return false, fmt.Errorf("syncDownFile/localFileChanged: %w", err)
+		}
+		if !changed {
+			return false, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("syncDownFile/Open: %w", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(localPath), 0o755)
+	if err != nil {
+		return false, fmt.Errorf("syncDownFile/MkdirAll: %w", err)
+	}
+
+	_, err = r.DownloadToFile(ctx, storagePath, name, localPath, opts...)
+	if err != nil {
+		return false, fmt.Errorf("syncDownFile/DownloadToFile: %w", err)
+	}
+
+	err = os.Chtimes(localPath, object.LastModified, object.LastModified)
+	if err != nil {
+		return false, fmt.Errorf("syncDownFile/Chtimes: %w", err)
+	}
+
+	return true, nil
+}
+
+// Получает объекты каталога в бакете с именами относительно префикса каталога
+func (r *s3Manager) listCatalog(ctx context.Context, prefix string) (map[string]ObjectInfo, error) {
+	objects, err := r.ListObjects(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listCatalog/ListObjects: %w", err)
+	}
+
+	catalog := make(map[string]ObjectInfo, len(objects))
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, prefix)
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue // Объекты-маркеры каталогов
+		}
+		catalog[name] = object
+	}
+
+	return catalog, nil
+}
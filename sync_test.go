@@ -0,0 +1,61 @@
+package s3_manager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSyncUpCompressedCatalog(t *testing.T) {
+	ctx := context.Background()
+	manager, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+	manager.AddCatalogWithCompression("exports", "exports/%d/", CompressionGzip)
+	storagePath := StoragePath{CatalogType: "exports", EntityID: 1}
+
+	dir := t.TempDir()
+	past := time.Now().Add(-time.Hour)
+	for _, name := range []string{"a.json", "b.json"} {
+		localPath := filepath.Join(dir, name)
+		err := os.WriteFile(localPath, []byte(`{"name": "`+name+`", "items": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]}`), 0o644)
+		if err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		err = os.Chtimes(localPath, past, past)
+		if err != nil {
+			t.Fatalf("Chtimes: %v", err)
+		}
+	}
+
+	result, err := manager.SyncUp(ctx, dir, storagePath, SyncOptions{})
+	if err != nil {
+		t.Fatalf("first SyncUp: %v", err)
+	}
+	if want := []string{"a.json", "b.json"}; !slices.Equal(result.Copied, want) {
+		t.Fatalf("first SyncUp Copied = %v, want %v", result.Copied, want)
+	}
+
+	// Размер сжатых объектов отличается от размера файлов, но файлы не изменялись после загрузки
+	result, err = manager.SyncUp(ctx, dir, storagePath, SyncOptions{})
+	if err != nil {
+		t.Fatalf("second SyncUp: %v", err)
+	}
+	if len(result.Copied) != 0 || result.Unchanged != 2 {
+		t.Errorf("second SyncUp = Copied %v, Unchanged %d, want nothing copied and 2 unchanged", result.Copied, result.Unchanged)
+	}
+
+	future := time.Now().Add(time.Hour)
+	err = os.Chtimes(filepath.Join(dir, "b.json"), future, future)
+	if err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	result, err = manager.SyncUp(ctx, dir, storagePath, SyncOptions{})
+	if err != nil {
+		t.Fatalf("third SyncUp: %v", err)
+	}
+	if want := []string{"b.json"}; !slices.Equal(result.Copied, want) || result.Unchanged != 1 {
+		t.Errorf("third SyncUp = Copied %v, Unchanged %d, want %v and 1 unchanged", result.Copied, result.Unchanged, want)
+	}
+}
@@ -0,0 +1,27 @@
+package s3_manager
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Ограничивает время выполнения операции тайм-аутом из конфига. Нулевой тайм-аут означает отсутствие ограничения.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Поток с содержимым объекта, который отменяет контекст операции при закрытии.
+// Нужен для скачивания: тайм-аут должен действовать, пока читается тело ответа, а не только до получения заголовков.
+type cancelOnCloseReader struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnCloseReader) Close() error {
+	defer r.cancel()
+	return r.ReadCloser.Close()
+}
@@ -0,0 +1,153 @@
+package s3_manager
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+)
+
+// Настройки HTTP-транспорта клиента S3. Незаполненные поля принимают значения по умолчанию из AWS SDK.
+type HTTPTransport struct {
+	ProxyURL            string        // Адрес прокси-сервера (например, "http://proxy.corp:3128"). По умолчанию используются переменные окружения HTTP_PROXY и HTTPS_PROXY.
+	TLSConfig           *tls.Config   // Настройки TLS (например, клиентский сертификат). CAFile, CAPEM и InsecureSkipVerify дополняют их.
+	CAFile              string        // Путь к файлу PEM с сертификатами центров сертификации, которым нужно доверять помимо системных (например, внутренний CA для MinIO)
+	CAPEM               []byte        // Сертификаты центров сертификации в формате PEM, которым нужно доверять помимо системных (например, из секрета)
+	InsecureSkipVerify  bool          // Не проверять сертификат хранилища. Только для локальной разработки: соединение уязвимо для перехвата.
+	DialTimeout         time.Duration // Тайм-аут установки TCP-соединения (по умолчанию 30 секунд)
+	TLSHandshakeTimeout time.Duration // Тайм-аут TLS-рукопожатия (по умолчанию 10 секунд)
+	MaxIdleConns        int           // Максимальное количество простаивающих соединений в пуле (по умолчанию 100)
+	MaxIdleConnsPerHost int           // Максимальное количество простаивающих соединений с хранилищем (по умолчанию 10). При пакетной загрузке в больше потоков соединения сверх этого числа закрываются после каждого запроса, и следующие запросы тратят время на новые TLS-рукопожатия.
+	MaxConnsPerHost     int           // Максимальное количество соединений с хранилищем, включая активные (по умолчанию не ограничено)
+	IdleConnTimeout     time.Duration // Время, через которое простаивающее соединение закрывается (по умолчанию 90 секунд)
+	KeepAlive           time.Duration // Интервал TCP keep-alive для открытых соединений (по умолчанию 30 секунд)
+	DisableHTTP2        bool          // Использовать только HTTP/1.1 (например, если прокси или хранилище некорректно работают с HTTP/2). По умолчанию HTTP/2 используется, если хранилище его поддерживает.
+}
+
+// Возвращает HTTP-клиент для клиента S3: Config.HTTPClient, если он задан, иначе клиент AWS SDK с настройками Config.Transport.
+// Возвращает nil, если ничего не настроено и можно использовать клиент AWS SDK по умолчанию.
+func (c *Config) httpClient() (aws.HTTPClient, error) {
+	if c.HTTPClient != nil {
+		return c.HTTPClient, nil
+	}
+
+	transport := c.Transport
+	if transport.isZero() {
+		return nil, nil
+	}
+
+	tlsConfig, err := transport.tlsConfig()
+	if err != nil {
+		return nil, fmt.Errorf("httpClient/tlsConfig: %w", err)
+	}
+
+	var proxyURL *url.URL
+	if transport.ProxyURL != "" {
+		parsed, err := url.Parse(transport.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("httpClient/Parse: %w: invalid proxy URL: %w", ErrInvalidInput, err)
+		}
+		proxyURL = parsed
+	}
+
+	client := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		if proxyURL != nil {
+			tr.Proxy = http.ProxyURL(proxyURL)
+		}
+		if tlsConfig != nil {
+			tr.TLSClientConfig = tlsConfig
+		}
+		if transport.TLSHandshakeTimeout > 0 {
+			tr.TLSHandshakeTimeout = transport.TLSHandshakeTimeout
+		}
+		if transport.MaxIdleConns > 0 {
+			tr.MaxIdleConns = transport.MaxIdleConns
+		}
+		if transport.MaxIdleConnsPerHost > 0 {
+			tr.MaxIdleConnsPerHost = transport.MaxIdleConnsPerHost
+		}
+		if transport.MaxConnsPerHost > 0 {
+			tr.MaxConnsPerHost = transport.MaxConnsPerHost
+		}
+		if transport.IdleConnTimeout > 0 {
+			tr.IdleConnTimeout = transport.IdleConnTimeout
+		}
+		if transport.DisableHTTP2 {
+			// Непустая карта TLSNextProto без "h2" отключает переход на HTTP/2 при TLS-рукопожатии
+			tr.ForceAttemptHTTP2 = false
+			tr.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		}
+	})
+	if transport.DialTimeout > 0 || transport.KeepAlive > 0 {
+		client = client.WithDialerOptions(func(d *net.Dialer) {
+			if transport.DialTimeout > 0 {
+				d.Timeout = transport.DialTimeout
+			}
+			if transport.KeepAlive > 0 {
+				d.KeepAlive = transport.KeepAlive
+			}
+		})
+	}
+
+	return client, nil
+}
+
+// Проверяет, что ни одна настройка транспорта не задана
+func (t HTTPTransport) isZero() bool {
+	return t.ProxyURL == "" && t.TLSConfig == nil && t.CAFile == "" && len(t.CAPEM) == 0 && !t.InsecureSkipVerify &&
+		t.DialTimeout == 0 && t.TLSHandshakeTimeout == 0 && t.MaxIdleConns == 0 && t.MaxIdleConnsPerHost == 0 && t.MaxConnsPerHost == 0 &&
+		t.IdleConnTimeout == 0 && t.KeepAlive == 0 && !t.DisableHTTP2
+}
+
+// Собирает настройки TLS из TLSConfig, CAFile, CAPEM и InsecureSkipVerify. Возвращает nil, если настройки TLS по умолчанию не меняются.
+func (t HTTPTransport) tlsConfig() (*tls.Config, error) {
+	if t.TLSConfig == nil && t.CAFile == "" && len(t.CAPEM) == 0 && !t.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.TLSConfig != nil {
+		tlsConfig = t.TLSConfig.Clone()
+	}
+
+	if t.CAFile != "" || len(t.CAPEM) > 0 {
+		// Дополнительные сертификаты добавляются к пулу из TLSConfig или к системному пулу, чтобы публичные хосты (например, AWS) продолжали работать
+		pool := tlsConfig.RootCAs
+		if pool == nil {
+			systemPool, err := x509.SystemCertPool()
+			if err != nil {
+				systemPool = x509.NewCertPool()
+			}
+			pool = systemPool
+		} else {
+			pool = pool.Clone()
+		}
+
+		if t.CAFile != "" {
+			pem, err := os.ReadFile(t.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("tlsConfig/ReadFile: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("tlsConfig: %w: no certificates found in %s", ErrInvalidInput, t.CAFile)
+			}
+		}
+		if len(t.CAPEM) > 0 && !pool.AppendCertsFromPEM(t.CAPEM) {
+			return nil, fmt.Errorf("tlsConfig: %w: no certificates found in CA PEM", ErrInvalidInput)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if t.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,218 @@
+package s3_manager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Формат времени удаления в ключах корзины. Фиксированная ширина позволяет сравнивать время как строки.
+const trashTimeLayout = "20060102T150405.000Z"
+
+// Метод для восстановления файла из корзины (см. Config.TrashCatalog). Если файл удалялся несколько раз, восстанавливается последняя удалённая копия.
+// Существующий файл с тем же именем перезаписывается. ACL восстановленному файлу задаётся только опцией WithACL, иначе он получает
+// ACL по умолчанию бакета: прежний ACL файла в корзине не сохраняется. Возвращает ссылку на восстановленный файл.
+func (r *s3Manager) RestoreFromTrash(ctx context.Context, storagePath StoragePath, fileName string, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return "", fmt.Errorf("RestoreFromTrash: %w: file name is empty", ErrInvalidInput)
+	}
+	if r.cfg.TrashCatalog == "" {
+		return "", fmt.Errorf("RestoreFromTrash: %w: trash catalog is not configured", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return "", fmt.Errorf("RestoreFromTrash/objectKey: %w", err)
+	}
+
+	var trashKey, deletedAt string
+	paginator := s3.NewListObjectsV2Paginator(r.client, &s3.ListObjectsV2Input{
+		Bucket: &r.cfg.Name,
+		Prefix: aws.String(r.trashPrefix()),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", fmt.Errorf("RestoreFromTrash/ListObjectsV2: %w", classifyError(err))
+		}
+
+		for _, item := range page.Contents {
+			timestamp, originalKey, ok := r.parseTrashKey(aws.ToString(item.Key))
+			if ok && originalKey == fullPath && timestamp > deletedAt {
+				trashKey, deletedAt = aws.ToString(item.Key), timestamp
+			}
+		}
+	}
+	if trashKey == "" {
+		return "", fmt.Errorf("RestoreFromTrash: %w: %s is not in trash", ErrObjectNotFound, fullPath)
+	}
+
+	err = r.copyObject(ctx, trashKey, fullPath, o)
+	if err != nil {
+		return "", fmt.Errorf("RestoreFromTrash/copyObject: %w", err)
+	}
+	r.invalidate(ctx, fullPath)
+
+	_, err = r.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: &r.cfg.Name,
+		Key:    &trashKey,
+	})
+	if err != nil {
+		return "", fmt.Errorf("RestoreFromTrash/DeleteObject: %w", classifyError(err))
+	}
+
+	fileURL, err := r.GetObjectURL(storagePath, fileName, opts...)
+	if err != nil {
+		return "", fmt.Errorf("RestoreFromTrash/GetObjectURL: %w", err)
+	}
+
+	return fileURL, nil
+}
+
+// Метод для окончательного удаления файлов, которые находятся в корзине дольше olderThan (при olderThan = 0 корзина очищается полностью).
+// Возвращает количество удалённых объектов.
+func (r *s3Manager) PurgeTrash(ctx context.Context, olderThan time.Duration, opts ...Option) (int, error) {
+	r = r.forCall(opts)
+
+	if r.cfg.TrashCatalog == "" {
+		return 0, fmt.Errorf("PurgeTrash: %w: trash catalog is not configured", ErrInvalidInput)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.DeleteTimeout)
+	defer cancel()
+
+	deadline := time.Now().UTC().Add(-olderThan).Format(trashTimeLayout)
+
+	deleted := 0
+	paginator := s3.NewListObjectsV2Paginator(r.client, &s3.ListObjectsV2Input{
+		Bucket: &r.cfg.Name,
+		Prefix: aws.String(r.trashPrefix()),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("PurgeTrash/ListObjectsV2: %w", classifyError(err))
+		}
+
+		var keys []string
+		for _, item := range page.Contents {
+			timestamp, _, ok := r.parseTrashKey(aws.ToString(item.Key))
+			if !ok || timestamp <= deadline {
+				keys = append(keys, aws.ToString(item.Key)) // Объекты с нераспознанным ключом в корзине не восстановить, поэтому они тоже удаляются
+			}
+		}
+
+		n, err := r.deleteObjects(ctx, keys)
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("PurgeTrash/deleteObjects: %w", err)
+		}
+	}
+
+	return deleted, nil
+}
+
+// Удаляет объекты: при включённой корзине перемещает их в корзину, иначе удаляет окончательно. Возвращает количество удалённых объектов.
+func (r *s3Manager) removeObjects(ctx context.Context, keys []string, o operationOptions) (int, error) {
+	if r.cfg.TrashCatalog == "" || o.permanentDelete {
+		return r.deleteObjects(ctx, keys)
+	}
+
+	moved, err := r.moveToTrash(ctx, keys, o)
+	if err != nil {
+		return moved, fmt.Errorf("removeObjects/moveToTrash: %w", err)
+	}
+
+	return moved, nil
+}
+
+// Перемещает объекты в корзину: копирует каждый объект в <TrashCatalog><время удаления>/<ключ> и удаляет исходный объект.
+// Объекты, которые уже находятся в корзине, удаляются окончательно. Возвращает количество удалённых объектов.
+func (r *s3Manager) moveToTrash(ctx context.Context, keys []string, o operationOptions) (int, error) {
+	// Файлы в корзине не должны быть доступны по публичным ссылкам
+	trashOptions := o
+	if trashOptions.acl != NoACL {
+		trashOptions.acl, trashOptions.explicitACL = types.ObjectCannedACLPrivate, true
+	}
+
+	var (
+		mu        sync.Mutex
+		toDelete  []string
+		failed    = make(map[string]error)
+		prefix    = r.trashPrefix()
+		timestamp = time.Now().UTC().Format(trashTimeLayout)
+	)
+
+	tasks := r.newBatch(o.concurrency)
+	for _, key := range keys {
+		if strings.HasPrefix(key, prefix) {
+			mu.Lock()
+			toDelete = append(toDelete, key)
+			mu.Unlock()
+			continue
+		}
+
+		tasks.Go(func() {
+			err := r.copyObject(ctx, key, prefix+timestamp+"/"+key, trashOptions)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed[key] = err
+				return
+			}
+			toDelete = append(toDelete, key)
+		})
+	}
+	tasks.Wait()
+
+	deleted, err := r.deleteObjects(ctx, toDelete)
+	if err != nil {
+		return deleted, fmt.Errorf("moveToTrash/deleteObjects: %w", err)
+	}
+	if len(failed) > 0 {
+		return deleted, fmt.Errorf("moveToTrash: %w", &BatchError{Errors: failed})
+	}
+
+	return deleted, nil
+}
+
+// Префикс корзины в бакете (всегда заканчивается на "/")
+func (r *s3Manager) trashPrefix() string {
+	return strings.TrimSuffix(r.cfg.TrashCatalog, "/") + "/"
+}
+
+// Проверяет, находится ли объект в корзине
+func (r *s3Manager) inTrash(key string) bool {
+	return r.cfg.TrashCatalog != "" && strings.HasPrefix(key, r.trashPrefix())
+}
+
+// Разбирает ключ объекта в корзине на время удаления и исходный ключ
+func (r *s3Manager) parseTrashKey(key string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(key, r.trashPrefix())
+	if !ok {
+		return "", "", false
+	}
+
+	timestamp, originalKey, ok := strings.Cut(rest, "/")
+	if !ok || originalKey == "" {
+		return "", "", false
+	}
+	if _, err := time.Parse(trashTimeLayout, timestamp); err != nil {
+		return "", "", false
+	}
+
+	return timestamp, originalKey, true
+}
@@ -0,0 +1,75 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestRestoreFromTrashDoesNotPublishFile(t *testing.T) {
+	ctx := context.Background()
+	backend := newACLBackend()
+	manager := newTestManagerWithBackend(t, backend, &Config{Endpoint: "http://s3.test", Name: "b", TrashCatalog: ".trash/"}, false)
+
+	_, err := manager.PutFile(ctx, StoragePath{}, &BucketFile{Name: "private.txt", File: bytes.NewReader([]byte("secret"))}, withRawName(), WithACL(types.ObjectCannedACLPrivate))
+	if err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+	if err := manager.DeleteFile(ctx, StoragePath{}, "private.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := manager.RestoreFromTrash(ctx, StoragePath{}, "private.txt"); err != nil {
+		t.Fatalf("RestoreFromTrash: %v", err)
+	}
+
+	if acl := backend.acl("private.txt"); acl != types.ObjectCannedACLPrivate {
+		t.Errorf("ACL = %q, want %q", acl, types.ObjectCannedACLPrivate)
+	}
+	if content := readObject(t, backend, "private.txt"); content != "secret" {
+		t.Errorf("content = %q, want %q", content, "secret")
+	}
+}
+
+func TestDeleteFilesSkipsTrash(t *testing.T) {
+	ctx := context.Background()
+	manager, _ := newTestManager(t, &Config{Endpoint: "http://s3.test", Name: "b", TrashCatalog: ".trash/", LegacyCatalogPaths: true}, false)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		_, err := manager.PutFile(ctx, StoragePath{}, &BucketFile{Name: name, File: bytes.NewReader([]byte(name))}, withRawName())
+		if err != nil {
+			t.Fatalf("PutFile(%q): %v", name, err)
+		}
+	}
+	if err := manager.DeleteFile(ctx, StoragePath{}, "a.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+
+	// Пустой префикс охватывает и корзину: удаляется только b.txt, копии в корзине остаются
+	for _, opts := range [][]Option{nil, {WithPermanentDelete()}} {
+		if _, err := manager.DeleteFiles(ctx, StoragePath{}, "", opts...); err != nil {
+			t.Fatalf("DeleteFiles: %v", err)
+		}
+	}
+
+	objects, err := manager.ListObjects(ctx, "")
+	if err != nil {
+		t.Fatalf("ListObjects: %v", err)
+	}
+	var trashed []string
+	for _, object := range objects {
+		if !strings.HasPrefix(object.Key, ".trash/") {
+			t.Errorf("object %q outside of trash was not deleted", object.Key)
+			continue
+		}
+		_, originalKey, _ := strings.Cut(strings.TrimPrefix(object.Key, ".trash/"), "/")
+		trashed = append(trashed, originalKey)
+	}
+	slices.Sort(trashed)
+	if want := []string{"a.txt", "b.txt"}; !slices.Equal(trashed, want) {
+		t.Errorf("files in trash = %v, want %v", trashed, want)
+	}
+}
@@ -0,0 +1,109 @@
+package s3_manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// Файл, загруженный в бакет из multipart-формы
+type UploadedFile struct {
+	Name        string // Имя, под которым файл сохранён в бакете (имя файла из формы после очистки или уникальное имя, см. WithStoredName)
+	URL         string // Ссылка на файл в бакете
+	Size        int64  // Размер файла в байтах
+	ContentType string // MIME-тип из заголовка Content-Type части формы (пустой, если клиент его не передал)
+}
+
+// Метод для загрузки в бакет файлов из поля fieldName multipart-формы запроса (multipart/form-data).
+// Тело запроса читается потоком: файлы загружаются в бакет по мере получения, без сохранения на диск и в память целиком (в отличие от r.ParseMultipartForm).
+// Размер каждого файла ограничивается так же, как в PutFile (Config.MaxUploadSize, WithMaxFileSize), общий размер запроса — http.MaxBytesReader в обработчике.
+// MIME-тип файла берётся из заголовка части формы, если он передан и не равен "application/octet-stream" (WithContentType его переопределяет),
+// иначе определяется так же, как в PutFile. Остальные поля формы пропускаются.
+// Возвращает загруженные файлы в порядке их следования в форме; при ошибке возвращаются файлы, загруженные до неё.
+func (r *s3Manager) PutFromMultipartForm(ctx context.Context, storagePath StoragePath, req *http.Request, fieldName string, opts ...Option) ([]UploadedFile, error) {
+	r = r.forCall(opts)
+
+	if req == nil || fieldName == "" {
+		return nil, fmt.Errorf("PutFromMultipartForm: %w: request or field name is empty", ErrInvalidInput)
+	}
+
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("PutFromMultipartForm/MultipartReader: %w: %w", ErrInvalidInput, err)
+	}
+
+	var files []UploadedFile
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return files, fmt.Errorf("PutFromMultipartForm/NextPart: %w", err)
+		}
+		if part.FormName() != fieldName || part.FileName() == "" {
+			continue // Непрочитанная часть пропускается при следующем вызове NextPart
+		}
+
+		file, err := r.putFormFile(ctx, storagePath, part.FileName(), part.Header.Get("Content-Type"), part, opts)
+		part.Close()
+		if err != nil {
+			return files, fmt.Errorf("PutFromMultipartForm/putFormFile: %s: %w", part.FileName(), err)
+		}
+		files = append(files, *file)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("PutFromMultipartForm: %w: no files in field %q", ErrInvalidInput, fieldName)
+	}
+
+	return files, nil
+}
+
+// Загружает файл из части формы
+func (r *s3Manager) putFormFile(ctx context.Context, storagePath StoragePath, name, contentType string, body io.Reader, opts []Option) (*UploadedFile, error) {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || mediaType == "application/octet-stream" {
+		contentType = "" // Браузеры передают application/octet-stream для неизвестных типов — в этом случае тип определяется по имени и содержимому
+	}
+
+	putOpts := opts
+	if contentType != "" {
+		// Опции вызова идут после типа из формы, поэтому WithContentType переопределяет его
+		putOpts = append([]Option{WithContentType(contentType)}, opts...)
+	}
+	var storedName string
+	putOpts = append(putOpts, WithStoredName(&storedName))
+
+	counter := &sizeLimitReader{reader: body} // Размер файла проверяет PutFile, здесь он только подсчитывается
+	fileURL, err := r.PutFile(ctx, storagePath, &BucketFile{File: counter, Name: name}, putOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("putFormFile/PutFile: %w", err)
+	}
+
+	return &UploadedFile{
+		Name:        storedName,
+		URL:         fileURL,
+		Size:        counter.read,
+		ContentType: contentType,
+	}, nil
+}
+
+// Поток, который считает прочитанные байты и возвращает ErrFileTooLarge, если их больше limit (при limit <= 0 размер не ограничен)
+type sizeLimitReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := l.reader.Read(p)
+	l.read += int64(n)
+	if l.limit > 0 && l.read > l.limit {
+		return n, fmt.Errorf("%w: exceeds %d bytes", ErrFileTooLarge, l.limit)
+	}
+
+	return n, err
+}
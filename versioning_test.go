@@ -0,0 +1,29 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestRestoreFileVersionKeepsPrivateACL(t *testing.T) {
+	ctx := context.Background()
+	backend := newACLBackend()
+	manager := newTestManagerWithBackend(t, backend, &Config{Endpoint: "http://s3.test", Name: "b"}, false)
+
+	_, err := manager.PutFile(ctx, StoragePath{}, &BucketFile{Name: "report.pdf", File: bytes.NewReader([]byte("v1"))}, withRawName(), WithACL(types.ObjectCannedACLPrivate))
+	if err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+
+	// Встроенное хранилище не хранит версии: у каждого объекта единственная версия "null", как в бакете без версионирования
+	if _, err := manager.RestoreFileVersion(ctx, StoragePath{}, "report.pdf", "null"); err != nil {
+		t.Fatalf("RestoreFileVersion: %v", err)
+	}
+
+	if acl := backend.acl("report.pdf"); acl != types.ObjectCannedACLPrivate {
+		t.Errorf("ACL = %q, want %q", acl, types.ObjectCannedACLPrivate)
+	}
+}
@@ -0,0 +1,255 @@
+package s3_manager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Настройки статического сайта бакета (например, для лендингов, которые раздаются прямо из хранилища)
+type WebsiteConfig struct {
+	IndexDocument string               // Файл, который отдаётся при запросе каталога (например, "index.html")
+	ErrorDocument string               // Ключ файла, который отдаётся при ошибке 4xx (например, "404.html")
+	RedirectAllTo string               // Домен, на который перенаправляются все запросы к сайту (например, "www.example.com"). Остальные настройки при этом не задаются.
+	RoutingRules  []WebsiteRoutingRule // Правила перенаправления запросов
+}
+
+// Правило перенаправления запросов к статическому сайту. Условия (KeyPrefix, ErrorCode) проверяются вместе; правило без условий применяется ко всем запросам.
+type WebsiteRoutingRule struct {
+	KeyPrefix        string // Условие: ключ начинается с префикса (например, "docs/")
+	ErrorCode        int    // Условие: хранилище вернуло этот код ошибки (например, 404)
+	HostName         string // Домен перенаправления; по умолчанию — домен запроса
+	Protocol         string // Протокол перенаправления ("http" или "https"); по умолчанию — протокол запроса
+	ReplaceKeyPrefix string // Заменить KeyPrefix в ключе на это значение (например, "documents/")
+	ReplaceKey       string // Заменить ключ целиком (нельзя задать вместе с ReplaceKeyPrefix)
+	HTTPRedirectCode int    // HTTP-код перенаправления (например, 301); по умолчанию 301
+}
+
+// Метод для получения настроек статического сайта бакета. Если сайт не настроен, возвращается nil.
+func (r *s3Manager) GetBucketWebsite(ctx context.Context, opts ...Option) (*WebsiteConfig, error) {
+	r = r.forCall(opts)
+
+	client, err := r.s3Client()
+	if err != nil {
+		return nil, fmt.Errorf("GetBucketWebsite/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	output, err := client.GetBucketWebsite(ctx, &s3.GetBucketWebsiteInput{
+		Bucket: &r.cfg.Name,
+	})
+	if err != nil {
+		if apiErrorCode(err) == "NoSuchWebsiteConfiguration" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("GetBucketWebsite/GetBucketWebsite: %w", classifyError(err))
+	}
+
+	website := &WebsiteConfig{}
+	if output.IndexDocument != nil {
+		website.IndexDocument = aws.ToString(output.IndexDocument.Suffix)
+	}
+	if output.ErrorDocument != nil {
+		website.ErrorDocument = aws.ToString(output.ErrorDocument.Key)
+	}
+	if output.RedirectAllRequestsTo != nil {
+		website.RedirectAllTo = aws.ToString(output.RedirectAllRequestsTo.HostName)
+	}
+	for _, rule := range output.RoutingRules {
+		website.RoutingRules = append(website.RoutingRules, websiteRoutingRuleFromS3(rule))
+	}
+
+	return website, nil
+}
+
+// Метод для замены настроек статического сайта бакета. Для доступа к сайту бакет или его файлы должны быть публично доступны на чтение.
+func (r *s3Manager) PutBucketWebsite(ctx context.Context, website WebsiteConfig, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return fmt.Errorf("PutBucketWebsite: %w", err)
+	}
+
+	websiteConfig, err := website.toS3()
+	if err != nil {
+		return fmt.Errorf("PutBucketWebsite/toS3: %w", err)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return fmt.Errorf("PutBucketWebsite/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.PutBucketWebsite(ctx, &s3.PutBucketWebsiteInput{
+		Bucket:               &r.cfg.Name,
+		WebsiteConfiguration: websiteConfig,
+	})
+	r.audit(ctx, "PutBucketWebsite", "", start, err)
+	if err != nil {
+		return fmt.Errorf("PutBucketWebsite/PutBucketWebsite: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Метод для удаления настроек статического сайта бакета. Если сайт не настроен, ошибка не возвращается.
+func (r *s3Manager) DeleteBucketWebsite(ctx context.Context, opts ...Option) error {
+	r = r.forCall(opts)
+
+	if err := r.checkWritable(); err != nil {
+		return fmt.Errorf("DeleteBucketWebsite: %w", err)
+	}
+
+	client, err := r.s3Client()
+	if err != nil {
+		return fmt.Errorf("DeleteBucketWebsite/s3Client: %w", err)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.RequestTimeout)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.DeleteBucketWebsite(ctx, &s3.DeleteBucketWebsiteInput{
+		Bucket: &r.cfg.Name,
+	})
+	r.audit(ctx, "DeleteBucketWebsite", "", start, err)
+	if err != nil {
+		return fmt.Errorf("DeleteBucketWebsite/DeleteBucketWebsite: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Метод для создания объекта-редиректа: пустого файла, при запросе которого статический сайт бакета (см. PutBucketWebsite)
+// перенаправляет на location — ключ в том же бакете, начинающийся с "/" (например, "/landing/new.html"), или внешний адрес
+// "http://" или "https://". Например, для старых адресов страниц после переезда лендинга. Возвращает ссылку на объект-редирект.
+// Перенаправление работает только через адрес сайта бакета, а не через API хранилища.
+func (r *s3Manager) PutRedirect(ctx context.Context, storagePath StoragePath, fileName, location string, opts ...Option) (string, error) {
+	r = r.forCall(opts)
+
+	if fileName == "" {
+		return "", fmt.Errorf("PutRedirect: %w: file name is empty", ErrInvalidInput)
+	}
+	if !strings.HasPrefix(location, "/") && !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return "", fmt.Errorf("PutRedirect: %w: location %q must start with \"/\", \"http://\" or \"https://\"", ErrInvalidInput, location)
+	}
+
+	ctx, cancel := withTimeout(ctx, r.cfg.UploadTimeout)
+	defer cancel()
+
+	o := r.applyOptions(opts)
+	fullPath, err := r.objectKey(storagePath, fileName)
+	if err != nil {
+		return "", fmt.Errorf("PutRedirect/objectKey: %w", err)
+	}
+
+	putInput := &s3.PutObjectInput{
+		Bucket:                  &r.cfg.Name,
+		Key:                     &fullPath,
+		Body:                    bytes.NewReader(nil),
+		ContentLength:           aws.Int64(0),
+		WebsiteRedirectLocation: &location,
+	}
+	if o.acl != NoACL {
+		putInput.ACL = o.acl
+	}
+	o.encryption.applyToPut(putInput)
+	r.uploadHeaders(storagePath, o).applyToPut(putInput)
+
+	_, err = r.client.PutObject(ctx, putInput)
+	if err != nil {
+		return "", fmt.Errorf("PutRedirect/PutObject: %w", classifyError(err))
+	}
+	r.invalidate(ctx, fullPath)
+
+	fileURL, err := r.GetObjectURL(storagePath, fileName, opts...)
+	if err != nil {
+		return "", fmt.Errorf("PutRedirect/GetObjectURL: %w", err)
+	}
+
+	return fileURL, nil
+}
+
+// Проверяет настройки и преобразует их в формат API S3
+func (website WebsiteConfig) toS3() (*types.WebsiteConfiguration, error) {
+	if website.RedirectAllTo != "" {
+		if website.IndexDocument != "" || website.ErrorDocument != "" || len(website.RoutingRules) > 0 {
+			return nil, fmt.Errorf("%w: RedirectAllTo can't be combined with other website settings", ErrInvalidInput)
+		}
+		return &types.WebsiteConfiguration{
+			RedirectAllRequestsTo: &types.RedirectAllRequestsTo{HostName: aws.String(website.RedirectAllTo)},
+		}, nil
+	}
+
+	if website.IndexDocument == "" || strings.Contains(website.IndexDocument, "/") {
+		return nil, fmt.Errorf("%w: index document %q must be a file name without \"/\"", ErrInvalidInput, website.IndexDocument)
+	}
+	websiteConfig := &types.WebsiteConfiguration{
+		IndexDocument: &types.IndexDocument{Suffix: aws.String(website.IndexDocument)},
+	}
+	if website.ErrorDocument != "" {
+		websiteConfig.ErrorDocument = &types.ErrorDocument{Key: aws.String(website.ErrorDocument)}
+	}
+	for i, rule := range website.RoutingRules {
+		if rule.ReplaceKey != "" && rule.ReplaceKeyPrefix != "" {
+			return nil, fmt.Errorf("%w: routing rule %d: ReplaceKey and ReplaceKeyPrefix can't be set together", ErrInvalidInput, i)
+		}
+		websiteConfig.RoutingRules = append(websiteConfig.RoutingRules, rule.toS3())
+	}
+
+	return websiteConfig, nil
+}
+
+// Преобразует правило в формат API S3
+func (rule WebsiteRoutingRule) toS3() types.RoutingRule {
+	s3Rule := types.RoutingRule{
+		Redirect: &types.Redirect{
+			HostName:             nonEmpty(rule.HostName),
+			Protocol:             types.Protocol(rule.Protocol),
+			ReplaceKeyPrefixWith: nonEmpty(rule.ReplaceKeyPrefix),
+			ReplaceKeyWith:       nonEmpty(rule.ReplaceKey),
+		},
+	}
+	if rule.HTTPRedirectCode != 0 {
+		s3Rule.Redirect.HttpRedirectCode = aws.String(strconv.Itoa(rule.HTTPRedirectCode))
+	}
+	if rule.KeyPrefix != "" || rule.ErrorCode != 0 {
+		s3Rule.Condition = &types.Condition{KeyPrefixEquals: nonEmpty(rule.KeyPrefix)}
+		if rule.ErrorCode != 0 {
+			s3Rule.Condition.HttpErrorCodeReturnedEquals = aws.String(strconv.Itoa(rule.ErrorCode))
+		}
+	}
+
+	return s3Rule
+}
+
+// Преобразует правило из формата API S3
+func websiteRoutingRuleFromS3(s3Rule types.RoutingRule) WebsiteRoutingRule {
+	var rule WebsiteRoutingRule
+	if s3Rule.Condition != nil {
+		rule.KeyPrefix = aws.ToString(s3Rule.Condition.KeyPrefixEquals)
+		rule.ErrorCode, _ = strconv.Atoi(aws.ToString(s3Rule.Condition.HttpErrorCodeReturnedEquals))
+	}
+	if s3Rule.Redirect != nil {
+		rule.HostName = aws.ToString(s3Rule.Redirect.HostName)
+		rule.Protocol = string(s3Rule.Redirect.Protocol)
+		rule.ReplaceKeyPrefix = aws.ToString(s3Rule.Redirect.ReplaceKeyPrefixWith)
+		rule.ReplaceKey = aws.ToString(s3Rule.Redirect.ReplaceKeyWith)
+		rule.HTTPRedirectCode, _ = strconv.Atoi(aws.ToString(s3Rule.Redirect.HttpRedirectCode))
+	}
+
+	return rule
+}